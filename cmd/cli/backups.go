@@ -0,0 +1,273 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.kirha.ai/mcp-installer/di"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/pkg/security"
+)
+
+func NewCmdBackups() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backups",
+		Short: "List, inspect, restore and prune configuration backups",
+		Long: `Manage the backups taken before every change to a client configuration.
+
+Backups are kept in the installer state directory. Identical file contents are
+stored only once, and old backups are pruned automatically according to the
+retention policy in the installer settings file.`,
+		Example: `  # List all backups
+  mcp-installer backups list
+
+  # Show a backup with API keys masked
+  mcp-installer backups show 20250101-120000-a1b2c3 --content
+
+  # Restore a backup
+  mcp-installer backups restore 20250101-120000-a1b2c3
+
+  # Delete backups older than 30 days, keeping at most 5 per file
+  mcp-installer backups prune --max-age 30d --keep 5`,
+	}
+
+	cmd.AddCommand(newCmdBackupsList())
+	cmd.AddCommand(newCmdBackupsShow())
+	cmd.AddCommand(newCmdBackupsRestore())
+	cmd.AddCommand(newCmdBackupsPrune())
+
+	return cmd
+}
+
+func newCmdBackupsList() *cobra.Command {
+	var client string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List configuration backups, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter := installer.BackupFilter{}
+			if client != "" {
+				clientType, err := validateClient(client)
+				if err != nil {
+					return describeOperationError(err, client)
+				}
+				filter.Client = clientType
+			}
+
+			app, err := di.ProvideInstallerApplication()
+			if err != nil {
+				return err
+			}
+
+			backups, err := app.ListBackups(cmd.Context(), filter)
+			if err != nil {
+				return fmt.Errorf("failed to list backups: %w", err)
+			}
+
+			if len(backups) == 0 {
+				fmt.Println("No backups found")
+				return nil
+			}
+
+			return printBackups(backups)
+		},
+	}
+
+	cmd.Flags().StringVarP(&client, "client", "c", "", "Only list backups of this client")
+
+	return cmd
+}
+
+func newCmdBackupsShow() *cobra.Command {
+	var showContent bool
+
+	cmd := &cobra.Command{
+		Use:   "show <id>",
+		Short: "Show the details of a backup",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := di.ProvideInstallerApplication()
+			if err != nil {
+				return err
+			}
+
+			backup, content, err := app.GetBackup(cmd.Context(), args[0])
+			if err != nil {
+				return describeOperationError(err, "")
+			}
+
+			fmt.Printf("ID:           %s\n", backup.ID)
+			fmt.Printf("Operation ID: %s\n", valueOrDash(backup.OperationID))
+			fmt.Printf("Client:       %s\n", backup.Client)
+			fmt.Printf("Operation:    %s\n", backup.Operation)
+			fmt.Printf("Created:      %s\n", backup.CreatedAt.Local().Format(time.RFC3339))
+			fmt.Printf("Config path:  %s\n", backup.ConfigPath)
+			if backup.Existed {
+				fmt.Printf("Size:         %d bytes\n", backup.Size)
+				fmt.Printf("SHA-256:      %s\n", backup.Hash)
+			} else {
+				fmt.Printf("Size:         - (file did not exist)\n")
+			}
+			fmt.Printf("Tool version: %s\n", backup.ToolVersion)
+			fmt.Printf("Completed:    %s\n", yesNo(backup.Completed()))
+			if backup.Scrubbed {
+				fmt.Printf("Scrubbed:     yes, API keys were redacted by purge\n")
+			}
+
+			if showContent && backup.Existed {
+				fmt.Printf("\n%s\n", security.MaskBearerTokens(string(content)))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&showContent, "content", false, "Print the backed up file, with API keys masked")
+
+	return cmd
+}
+
+func newCmdBackupsRestore() *cobra.Command {
+	flags := &operationFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "restore <id>",
+		Short: "Restore a backup over its configuration file",
+		Long: `Restore a backup over the configuration file it was taken from.
+
+The current content of that file is backed up first, so a restore can itself be
+reverted with another restore.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := di.ProvideInstallerApplication()
+			if err != nil {
+				return err
+			}
+
+			backup, _, err := app.GetBackup(cmd.Context(), args[0])
+			if err != nil {
+				return describeOperationError(err, "")
+			}
+
+			result, err := app.RestoreBackup(cmd.Context(), backup.ID, &installer.Config{
+				Operation:   installer.OperationRestore,
+				DryRun:      flags.dryRun,
+				Force:       flags.force,
+				LockTimeout: flags.lockTimeout,
+			})
+			if err != nil {
+				return describeOperationError(err, string(backup.Client))
+			}
+
+			fmt.Println(result.Message)
+			if result.BackupID != "" {
+				fmt.Printf("Previous content saved as backup %s\n", result.BackupID)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "Show what would be restored without making changes")
+	cmd.Flags().BoolVar(&flags.force, "force", false, "Restore even if the client application is running")
+	addLockTimeoutFlag(cmd, flags)
+
+	return cmd
+}
+
+func newCmdBackupsPrune() *cobra.Command {
+	var (
+		keep   int
+		maxAge string
+		dryRun bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete old backups",
+		Long: `Delete backups outside the retention policy. The newest backup of every
+configuration file is always kept.
+
+Without flags the policy from the installer settings file is used
+(settings.json, "backups": {"keep_last": 20, "max_age": "90d"}).`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := di.ProvideInstallerApplication()
+			if err != nil {
+				return err
+			}
+
+			var policy *installer.RetentionPolicy
+			if cmd.Flags().Changed("keep") || cmd.Flags().Changed("max-age") {
+				if keep < 0 {
+					return fmt.Errorf("--keep must not be negative")
+				}
+				age, err := installer.ParseAge(maxAge)
+				if err != nil {
+					return fmt.Errorf("invalid --max-age: %w", err)
+				}
+				policy = &installer.RetentionPolicy{KeepLast: keep, MaxAge: age}
+			}
+
+			pruned, err := app.PruneBackups(cmd.Context(), policy, dryRun)
+			if err != nil {
+				return describeOperationError(err, "")
+			}
+
+			if len(pruned) == 0 {
+				fmt.Println("No backups to prune")
+				return nil
+			}
+
+			if err := printBackups(pruned); err != nil {
+				return err
+			}
+
+			if dryRun {
+				fmt.Printf("\nWould delete %d backups\n", len(pruned))
+			} else {
+				fmt.Printf("\nDeleted %d backups\n", len(pruned))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&keep, "keep", 0, "Number of backups to keep per configuration file (0 for no limit)")
+	cmd.Flags().StringVar(&maxAge, "max-age", "", "Delete backups older than this, e.g. 720h or 30d")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the backups that would be deleted without deleting them")
+
+	return cmd
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func printBackups(backups []*installer.Backup) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tOPERATION ID\tCLIENT\tOPERATION\tCREATED\tSIZE\tCONFIG PATH")
+	for _, backup := range backups {
+		size := fmt.Sprintf("%d", backup.Size)
+		if !backup.Existed {
+			size = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			backup.ID,
+			valueOrDash(backup.OperationID),
+			backup.Client,
+			backup.Operation,
+			backup.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+			size,
+			backup.ConfigPath)
+	}
+	return w.Flush()
+}
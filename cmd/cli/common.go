@@ -3,32 +3,77 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"os"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 	"go.kirha.ai/mcp-installer/di"
+	installerApp "go.kirha.ai/mcp-installer/internal/applications/installer"
 	domainErrors "go.kirha.ai/mcp-installer/internal/core/domain/errors"
 	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
 )
 
-func runOperation(cmd *cobra.Command, operation installer.OperationType, client, apiKey, configPath string, dryRun, verbose, force bool) error {
-	clientType, err := validateClient(client)
-	if err != nil {
-		return err
+const supportedClients = "claudecode, codex, opencode, gemini, droid"
+
+type operationFlags struct {
+	client      string
+	apiKey      string
+	configPath  string
+	scope       string
+	project     string
+	dryRun      bool
+	verbose     bool
+	force       bool
+	allDetected bool
+	atomic      bool
+	parallel    int
+	timeout     time.Duration
+	lockTimeout time.Duration
+}
+
+func addMultiClientFlags(cmd *cobra.Command, flags *operationFlags) {
+	cmd.Flags().BoolVar(&flags.allDetected, "all-detected", false, "Apply to every client detected on this machine")
+	cmd.Flags().IntVar(&flags.parallel, "parallel", 4, "Maximum number of clients processed concurrently")
+	cmd.Flags().DurationVar(&flags.timeout, "timeout", 30*time.Second, "Timeout for each client operation")
+	cmd.Flags().BoolVar(&flags.atomic, "atomic", true, "Roll back every client if any client fails; --atomic=false keeps the clients that succeeded")
+}
+
+func addScopeFlag(cmd *cobra.Command, flags *operationFlags) {
+	cmd.Flags().StringVar(&flags.scope, "scope", string(installer.ScopeUser), "Where to keep the server, for clients with several scopes (claudecode): user, local (this project, private) or project (this project, .mcp.json)")
+}
+
+// resolveScope checks the --scope flag and, for the scopes tied to a project,
+// takes the current directory as the project.
+func resolveScope(flags *operationFlags) error {
+	switch installer.Scope(flags.scope) {
+	case "", installer.ScopeUser:
+		flags.scope = string(installer.ScopeUser)
+		return nil
+	case installer.ScopeLocal, installer.ScopeProject:
+		project, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to determine the project directory: %w", err)
+		}
+		flags.project = project
+		return nil
+	default:
+		return fmt.Errorf("invalid --scope %q, expected user, local or project", flags.scope)
 	}
+}
+
+func addLockTimeoutFlag(cmd *cobra.Command, flags *operationFlags) {
+	cmd.Flags().DurationVar(&flags.lockTimeout, "lock-timeout", 10*time.Second, "How long to wait for other installer processes to release their locks")
+}
 
-	if operation == installer.OperationInstall && apiKey == "" {
+func runOperation(cmd *cobra.Command, operation installer.OperationType, flags *operationFlags) error {
+	if operation == installer.OperationInstall && flags.apiKey == "" {
 		return fmt.Errorf("API key is required for %s operation", operation)
 	}
 
-	config := &installer.Config{
-		Client:     clientType,
-		ApiKey:     apiKey,
-		ConfigPath: configPath,
-		Operation:  operation,
-		DryRun:     dryRun,
-		Verbose:    verbose,
-		Force:      force,
+	if err := resolveScope(flags); err != nil {
+		return err
 	}
 
 	app, err := di.ProvideInstallerApplication()
@@ -36,37 +81,278 @@ func runOperation(cmd *cobra.Command, operation installer.OperationType, client,
 		return err
 	}
 
+	clients, err := resolveClients(cmd, app, flags)
+	if err != nil {
+		return err
+	}
+
+	if len(clients) == 1 && !flags.allDetected {
+		return runSingleOperation(cmd, app, operation, clients[0], flags)
+	}
+
+	if flags.configPath != "" {
+		return fmt.Errorf("--config-path applies to a single client, select only one with --client")
+	}
+
+	if operation == installer.OperationShow {
+		return fmt.Errorf("the %s command supports a single client", operation)
+	}
+
+	return runBatchOperation(cmd, app, operation, clients, flags)
+}
+
+func runSingleOperation(cmd *cobra.Command, app *installerApp.Application, operation installer.OperationType, clientType installer.ClientType, flags *operationFlags) error {
+	config := newOperationConfig(operation, clientType, flags)
+
 	ctx := cmd.Context()
 	result, err := app.Execute(ctx, config)
-
 	if err != nil {
-		if errors.Is(err, domainErrors.ErrServerExistsUseUpdate) {
-			return fmt.Errorf("MCP server already exists for %s. Use 'mcp-installer update --client %s --key <api-key>' to update it", client, client)
-		} else if errors.Is(err, domainErrors.ErrServerNotFoundForUpdate) {
-			return fmt.Errorf("MCP server not found for %s. Use 'mcp-installer install --client %s --key <api-key>' to install it first", client, client)
-		} else if errors.Is(err, domainErrors.ErrServerNotFoundForRemove) {
-			return fmt.Errorf("MCP server not found for %s. Nothing to remove", client)
-		} else if errors.Is(err, domainErrors.ErrClientRunning) {
-			return fmt.Errorf("the %s application is currently running. Please close it and try again", client)
-		} else if errors.Is(err, domainErrors.ErrUnsupportedClient) {
-			return fmt.Errorf("unsupported client: %s\n\nSupported clients: claudecode, codex, opencode, gemini, droid", client)
-		} else {
-			return fmt.Errorf("operation failed: %w", err)
-		}
+		return describeOperationError(err, string(clientType))
 	}
 
 	fmt.Println(result.Message)
 
-	if verbose && result.ConfigPath != "" {
+	if installer.Scope(flags.scope) == installer.ScopeProject && operation != installer.OperationShow && operation != installer.OperationRemove && !flags.dryRun {
+		fmt.Printf("Note: %s now holds your API key. It is meant to be shared with the project, so keep it out of version control.\n", result.ConfigPath)
+	}
+
+	if flags.verbose && result.OperationID != "" {
+		fmt.Printf("\nOperation ID: %s (undo with 'mcp-installer undo %s')\n", result.OperationID, result.OperationID)
+	}
+
+	if flags.verbose && result.ConfigPath != "" {
 		fmt.Printf("\nConfiguration file: %s\n", result.ConfigPath)
-		if result.BackupPath != "" {
-			fmt.Printf("Backup created at: %s\n", result.BackupPath)
+		if result.BackupID != "" {
+			fmt.Printf("Backup created: %s (restore with 'mcp-installer backups restore %s')\n", result.BackupID, result.BackupID)
+		}
+	}
+
+	return nil
+}
+
+func runBatchOperation(cmd *cobra.Command, app *installerApp.Application, operation installer.OperationType, clients []installer.ClientType, flags *operationFlags) error {
+	configs := make([]*installer.Config, 0, len(clients))
+	for _, clientType := range clients {
+		configs = append(configs, newOperationConfig(operation, clientType, flags))
+	}
+
+	opts := installer.BatchOptions{
+		Concurrency: flags.parallel,
+		Timeout:     flags.timeout,
+		LockTimeout: flags.lockTimeout,
+	}
+
+	var (
+		results []*installer.BatchResult
+		txErr   error
+	)
+	if flags.atomic {
+		results, txErr = app.ExecuteTransaction(cmd.Context(), configs, opts)
+		if results == nil {
+			return fmt.Errorf("operation failed: %w", txErr)
+		}
+	} else {
+		results = app.ExecuteBatch(cmd.Context(), configs, opts)
+	}
+
+	failed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLIENT\tSTATUS\tDURATION\tDETAILS")
+	for _, result := range results {
+		status := "ok"
+		details := ""
+		if result.Err != nil {
+			failed++
+			status = "failed"
+			details = describeOperationError(result.Err, string(result.Client)).Error()
+		} else if result.RolledBack {
+			status = "rolled back"
+		} else if result.Result != nil {
+			details = result.Result.Message
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", clientLabel(result.Client, result.Scope, result.Project), status, result.Duration.Round(time.Millisecond), details)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if flags.verbose {
+		fmt.Println()
+		if len(configs) > 0 && configs[0].ID != "" && txErr == nil {
+			fmt.Printf("Operation ID: %s (undo with 'mcp-installer undo %s')\n", configs[0].ID, configs[0].ID)
+		}
+		for _, result := range results {
+			if result.Result != nil && result.Result.BackupID != "" {
+				fmt.Printf("%s backup created: %s\n", result.Client, result.Result.BackupID)
+			}
+		}
+	}
+
+	if failed > 0 {
+		if txErr != nil {
+			return fmt.Errorf("%s failed for %d of %d clients, all changes were rolled back", operation, failed, len(results))
 		}
+		return fmt.Errorf("%s failed for %d of %d clients", operation, failed, len(results))
+	}
+
+	if txErr != nil {
+		return fmt.Errorf("operation failed: %w", txErr)
 	}
 
 	return nil
 }
 
+// clientLabel names a client, along with the scope and project of entries not
+// kept at the user scope.
+func clientLabel(client installer.ClientType, scope installer.Scope, project string) string {
+	switch {
+	case scope == "" || scope == installer.ScopeUser:
+		return string(client)
+	case project == "":
+		return fmt.Sprintf("%s (%s)", client, scope)
+	default:
+		return fmt.Sprintf("%s (%s: %s)", client, scope, project)
+	}
+}
+
+func newOperationConfig(operation installer.OperationType, clientType installer.ClientType, flags *operationFlags) *installer.Config {
+	return &installer.Config{
+		Client:     clientType,
+		Scope:      installer.Scope(flags.scope),
+		Project:    flags.project,
+		ApiKey:     flags.apiKey,
+		ConfigPath: flags.configPath,
+		Operation:  operation,
+		DryRun:     flags.dryRun,
+		Verbose:    flags.verbose,
+		Force:      flags.force,
+
+		LockTimeout: flags.lockTimeout,
+	}
+}
+
+func resolveClients(cmd *cobra.Command, app *installerApp.Application, flags *operationFlags) ([]installer.ClientType, error) {
+	if flags.allDetected && flags.client != "" {
+		return nil, fmt.Errorf("--client and --all-detected cannot be used together")
+	}
+
+	if !flags.allDetected {
+		if flags.client == "" {
+			return nil, fmt.Errorf("either --client or --all-detected is required")
+		}
+		return parseClients(flags.client)
+	}
+
+	detections, err := app.Detect(cmd.Context())
+	if err != nil {
+		return nil, fmt.Errorf("detection failed: %w", err)
+	}
+
+	var clients []installer.ClientType
+	for _, detection := range detections {
+		if detection.Detected() {
+			clients = append(clients, detection.Client)
+		}
+	}
+
+	if len(clients) == 0 {
+		return nil, fmt.Errorf("no supported clients detected on this machine")
+	}
+
+	return clients, nil
+}
+
+func parseClients(value string) ([]installer.ClientType, error) {
+	var clients []installer.ClientType
+	seen := make(map[installer.ClientType]bool)
+
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		clientType, err := validateClient(name)
+		if err != nil {
+			return nil, describeOperationError(err, name)
+		}
+
+		if !seen[clientType] {
+			seen[clientType] = true
+			clients = append(clients, clientType)
+		}
+	}
+
+	if len(clients) == 0 {
+		return nil, fmt.Errorf("at least one client is required")
+	}
+
+	return clients, nil
+}
+
+func describeOperationError(err error, client string) error {
+	var runningErr *installer.ClientRunningError
+	if errors.As(err, &runningErr) {
+		client = string(runningErr.Client)
+	}
+
+	var policyErr *installer.PolicyError
+	if errors.As(err, &policyErr) {
+		if client == "" && len(policyErr.Violations) > 0 {
+			client = string(policyErr.Violations[0].Client)
+		}
+		return fmt.Errorf("%w for %s:\n%s", domainErrors.ErrPolicyViolation, client, formatViolations(policyErr.Violations))
+	}
+
+	var schemaErr *installer.SchemaError
+	if errors.As(err, &schemaErr) {
+		return fmt.Errorf("%w: the %s configuration would not be accepted by the client, nothing was written:\n%s", domainErrors.ErrConfigInvalid, client, formatSchemaViolations(schemaErr.Violations))
+	}
+
+	var parseErr *installer.ParseError
+	if errors.As(err, &parseErr) {
+		return fmt.Errorf("%w\nRun 'mcp-installer repair --client %s' to repair it", err, client)
+	}
+
+	if errors.Is(err, domainErrors.ErrServerExistsUseUpdate) {
+		return fmt.Errorf("MCP server already exists for %s. Use 'mcp-installer update --client %s --key <api-key>' to update it", client, client)
+	} else if errors.Is(err, domainErrors.ErrServerNotFoundForUpdate) {
+		return fmt.Errorf("MCP server not found for %s. Use 'mcp-installer install --client %s --key <api-key>' to install it first", client, client)
+	} else if errors.Is(err, domainErrors.ErrServerNotFoundForRemove) {
+		return fmt.Errorf("MCP server not found for %s. Nothing to remove", client)
+	} else if errors.Is(err, domainErrors.ErrClientRunning) {
+		return fmt.Errorf("the %s application is currently running. Please close it and try again", client)
+	} else if errors.Is(err, domainErrors.ErrConfigModified) {
+		return fmt.Errorf("the %s configuration was changed by another process while saving, nothing was overwritten. Please try again: %w", client, err)
+	} else if errors.Is(err, domainErrors.ErrUnexpectedChange) {
+		return fmt.Errorf("saving the %s configuration altered settings outside the Kirha entry, so the change was reverted: %w\nPlease report this issue along with the output of --verbose", client, err)
+	} else if errors.Is(err, domainErrors.ErrLocked) {
+		return fmt.Errorf("another mcp-installer process is modifying configurations (%w). Wait for it to finish or raise --lock-timeout", err)
+	} else if errors.Is(err, domainErrors.ErrBackupNotFound) {
+		return fmt.Errorf("%w. Use 'mcp-installer backups list' to see available backups", err)
+	} else if errors.Is(err, domainErrors.ErrOperationNotFound) {
+		return fmt.Errorf("%w or it cannot be undone. Use 'mcp-installer backups list' to see recorded operations", err)
+	} else if errors.Is(err, domainErrors.ErrUndoConflict) {
+		return fmt.Errorf("%w, nothing was undone. Use --force to overwrite those changes", err)
+	} else if errors.Is(err, domainErrors.ErrSyncSourceNotConfigured) {
+		return fmt.Errorf("%w. Use 'mcp-installer install --client %s --key <api-key>' first or sync from another client", err, client)
+	} else if errors.Is(err, domainErrors.ErrProfileNotFound) {
+		return fmt.Errorf("%w. Profiles are defined under \"profiles\" in settings.json", err)
+	} else if errors.Is(err, domainErrors.ErrPlaceholderUnresolved) {
+		return fmt.Errorf("%w. Pass them with --set NAME=VALUE or as environment variables", err)
+	} else if errors.Is(err, domainErrors.ErrSignatureRequired) {
+		return fmt.Errorf("%w. Trust its key with --trusted-key or under \"signatures\" in settings.json", err)
+	} else if errors.Is(err, domainErrors.ErrSignatureInvalid) {
+		return fmt.Errorf("%w. Do not use this bundle, ask its author for a fresh copy", err)
+	} else if errors.Is(err, domainErrors.ErrScopeNotSupported) {
+		return fmt.Errorf("%w, use --scope user", err)
+	} else if errors.Is(err, domainErrors.ErrUnsupportedClient) {
+		return fmt.Errorf("unsupported client: %s\n\nSupported clients: %s", client, supportedClients)
+	} else {
+		return fmt.Errorf("operation failed: %w", err)
+	}
+}
+
 func validateClient(client string) (installer.ClientType, error) {
 	switch strings.ToLower(client) {
 	case "claudecode", "claude-code":
@@ -83,3 +369,11 @@ func validateClient(client string) (installer.ClientType, error) {
 		return "", domainErrors.ErrUnsupportedClient
 	}
 }
+
+func formatSchemaViolations(violations []installer.SchemaViolation) string {
+	lines := make([]string, len(violations))
+	for i, violation := range violations {
+		lines[i] = fmt.Sprintf("  %s: %s", violation.Path, violation.Message)
+	}
+	return strings.Join(lines, "\n")
+}
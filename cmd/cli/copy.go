@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"go.kirha.ai/mcp-installer/di"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+func NewCmdCopy() *cobra.Command {
+	flags := &operationFlags{}
+	var from, to string
+	var servers []string
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "copy",
+		Short: "Copy MCP servers from one client to another",
+		Long: `Copy MCP server definitions from one client configuration to another, translating
+them to the format of the target client, for example "http" servers become
+"remote" ones for OpenCode and headers become http_headers for Codex.
+
+Servers the target already has are left untouched. Settings the target cannot
+represent, such as a transport it does not support, are reported as warnings.`,
+		Example: `  # Copy the Kirha server from Claude Code to Codex
+  mcp-installer copy --from claudecode --to codex --server kirha
+
+  # Copy every server from Claude Code to OpenCode, previewing it first
+  mcp-installer copy --from claudecode --to opencode --all --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCopy(cmd, flags, from, to, servers, all)
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "Client to copy servers from (required)")
+	cmd.Flags().StringVar(&to, "to", "", "Client to copy servers to (required)")
+	cmd.Flags().StringSliceVar(&servers, "server", nil, "Name of a server to copy, repeatable or comma-separated")
+	cmd.Flags().BoolVar(&all, "all", false, "Copy every server of the source client")
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "Show what would be copied without making changes")
+	cmd.Flags().BoolVarP(&flags.force, "force", "f", false, "Copy even if the target client is running")
+	addLockTimeoutFlag(cmd, flags)
+
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+func runCopy(cmd *cobra.Command, flags *operationFlags, from, to string, servers []string, all bool) error {
+	if (len(servers) == 0) == !all {
+		return fmt.Errorf("exactly one of --server or --all is required")
+	}
+
+	fromClient, err := validateClient(from)
+	if err != nil {
+		return describeOperationError(err, from)
+	}
+	toClient, err := validateClient(to)
+	if err != nil {
+		return describeOperationError(err, to)
+	}
+	if fromClient == toClient {
+		return fmt.Errorf("--from and --to must be different clients")
+	}
+
+	app, err := di.ProvideInstallerApplication()
+	if err != nil {
+		return err
+	}
+
+	result, err := app.Copy(cmd.Context(), &installer.Config{
+		Client:      toClient,
+		Operation:   installer.OperationCopy,
+		DryRun:      flags.dryRun,
+		Force:       flags.force,
+		LockTimeout: flags.lockTimeout,
+	}, fromClient, servers)
+	if err != nil {
+		return describeOperationError(err, to)
+	}
+
+	if len(result.Servers) == 0 {
+		fmt.Printf("No MCP servers configured in %s\n", fromClient)
+		return nil
+	}
+
+	return printAddedServers(result, "copy", "Copied")
+}
+
+// printAddedServers reports the servers written by copy or import. verb and
+// done name the operation in the summary line.
+func printAddedServers(result *installer.CopyResult, verb, done string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVER\tTRANSPORT\tSTATUS\tWARNINGS")
+	added := 0
+	for _, server := range result.Servers {
+		if server.Status != installer.CopyExists {
+			added++
+		}
+		warnings := strings.Join(server.Warnings, ", ")
+		if warnings != "" {
+			warnings = "not supported by " + string(result.To) + ": " + warnings
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", server.Name, valueOrDash(server.Type), server.Status, valueOrDash(warnings))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	switch {
+	case added == 0:
+		fmt.Printf("\nEvery server already exists in %s\n", result.To)
+	case result.DryRun:
+		fmt.Printf("\nWould %s %d servers to %s\n", verb, added, result.ConfigPath)
+	default:
+		fmt.Printf("\n%s %d servers to %s, operation ID: %s\n", done, added, result.ConfigPath, result.OperationID)
+	}
+
+	return nil
+}
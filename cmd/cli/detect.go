@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"go.kirha.ai/mcp-installer/di"
+)
+
+func NewCmdDetect() *cobra.Command {
+	var verbose bool
+
+	cmd := &cobra.Command{
+		Use:   "detect",
+		Short: "Detect supported clients installed on this machine",
+		Long: `Detect which supported development environments are installed on this machine.
+
+For each supported client this command reports whether its binary is on PATH,
+whether its configuration file exists, where that file lives, whether the client
+is currently running and whether the Kirha MCP server is already installed.`,
+		Example: `  # Detect installed clients
+  mcp-installer detect
+
+  # Include binary locations and load errors
+  mcp-installer detect --verbose`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDetect(cmd, verbose)
+		},
+	}
+
+	cmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging")
+
+	return cmd
+}
+
+func runDetect(cmd *cobra.Command, verbose bool) error {
+	app, err := di.ProvideInstallerApplication()
+	if err != nil {
+		return err
+	}
+
+	results, err := app.Detect(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("detection failed: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLIENT\tBINARY\tCONFIG\tRUNNING\tKIRHA\tCONFIG PATH")
+	for _, result := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			result.Client,
+			yesNo(result.BinaryPath != ""),
+			yesNo(result.ConfigExists),
+			yesNo(result.Running),
+			yesNo(result.HasServer),
+			result.ConfigPath)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if verbose {
+		fmt.Println()
+		for _, result := range results {
+			if result.BinaryPath != "" {
+				fmt.Printf("%s binary: %s\n", result.Client, result.BinaryPath)
+			}
+			if result.Error != "" {
+				fmt.Printf("%s error: %s\n", result.Client, result.Error)
+			}
+		}
+	}
+
+	return nil
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"go.kirha.ai/mcp-installer/di"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/pkg/security"
+)
+
+func NewCmdDiff() *cobra.Command {
+	var left, right string
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare the MCP servers of two clients",
+		Long: `Compare the MCP servers configured in two clients. Both configurations are
+translated to the same server definition first, so that only real differences
+are reported: servers present on one side only, and for servers present on both
+sides the transport, URL, headers, command, environment and enabled state.
+
+Header and environment values are masked.`,
+		Example: `  # Compare Claude Code with Codex
+  mcp-installer diff --left claudecode --right codex`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDiff(cmd, left, right)
+		},
+	}
+
+	cmd.Flags().StringVar(&left, "left", "", "First client to compare (required)")
+	cmd.Flags().StringVar(&right, "right", "", "Second client to compare (required)")
+
+	_ = cmd.MarkFlagRequired("left")
+	_ = cmd.MarkFlagRequired("right")
+
+	return cmd
+}
+
+func runDiff(cmd *cobra.Command, left, right string) error {
+	leftClient, err := validateClient(left)
+	if err != nil {
+		return describeOperationError(err, left)
+	}
+	rightClient, err := validateClient(right)
+	if err != nil {
+		return describeOperationError(err, right)
+	}
+
+	app, err := di.ProvideInstallerApplication()
+	if err != nil {
+		return err
+	}
+
+	comparison, err := app.Diff(cmd.Context(), leftClient, rightClient)
+	if err != nil {
+		return describeOperationError(err, left)
+	}
+
+	fmt.Printf("--- %s (%s)\n", comparison.Left, comparison.LeftPath)
+	fmt.Printf("+++ %s (%s)\n", comparison.Right, comparison.RightPath)
+
+	var leftOnly, rightOnly, different []installer.ServerComparison
+	identical := 0
+	for _, server := range comparison.Servers {
+		switch {
+		case server.Right == nil:
+			leftOnly = append(leftOnly, server)
+		case server.Left == nil:
+			rightOnly = append(rightOnly, server)
+		case len(server.Differences) > 0:
+			different = append(different, server)
+		default:
+			identical++
+		}
+	}
+
+	printOnlyIn(comparison.Left, leftOnly, func(s installer.ServerComparison) *installer.McpServer { return s.Left })
+	printOnlyIn(comparison.Right, rightOnly, func(s installer.ServerComparison) *installer.McpServer { return s.Right })
+
+	for _, server := range different {
+		fmt.Printf("\nDifferent: %s\n", server.Name)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "  FIELD\t%s\t%s\n", strings.ToUpper(string(comparison.Left)), strings.ToUpper(string(comparison.Right)))
+		for _, difference := range server.Differences {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", difference.Field,
+				valueOrDash(maskFieldValue(difference.Field, difference.Left)),
+				valueOrDash(maskFieldValue(difference.Field, difference.Right)))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(leftOnly) == 0 && len(rightOnly) == 0 && len(different) == 0 {
+		fmt.Printf("\nNo differences, %d servers identical\n", identical)
+	} else {
+		fmt.Printf("\n%d only in %s, %d only in %s, %d different, %d identical\n",
+			len(leftOnly), comparison.Left, len(rightOnly), comparison.Right, len(different), identical)
+	}
+
+	return nil
+}
+
+func printOnlyIn(client installer.ClientType, servers []installer.ServerComparison, side func(installer.ServerComparison) *installer.McpServer) {
+	if len(servers) == 0 {
+		return
+	}
+
+	fmt.Printf("\nOnly in %s:\n", client)
+	for _, server := range servers {
+		definition := side(server)
+		target := definition.URL
+		if definition.Command != "" {
+			target = strings.Join(append([]string{definition.Command}, definition.Args...), " ")
+		}
+		fmt.Printf("  %s (%s) %s\n", server.Name, valueOrDash(definition.Type), target)
+	}
+}
+
+// maskFieldValue hides header and environment values, which often hold
+// credentials.
+func maskFieldValue(field, value string) string {
+	if value == "" {
+		return ""
+	}
+	if strings.HasPrefix(field, "header ") || strings.HasPrefix(field, "env ") {
+		return security.MaskAPIKey(value)
+	}
+	return value
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.kirha.ai/mcp-installer/di"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+func NewCmdDoctor() *cobra.Command {
+	var clients []string
+	var opts installer.DoctorOptions
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose why the Kirha MCP server does not show up in a client",
+		Long: `Check the MCP setup of each client: whether its configuration exists and parses,
+its permissions, duplicate or shadowing entries, the URL and Authorization header
+of the Kirha entry, transports the client does not support, whether the client is
+running, leftover backups and whether the Kirha endpoint answers.
+
+Every finding comes with a remediation hint. With --fix, the ones that need no
+decision are applied: restricting the permissions of a configuration holding an
+API key, deleting legacy backup files and pruning old backups.`,
+		Example: `  # Diagnose every installed client
+  mcp-installer doctor
+
+  # Diagnose Claude Code without contacting the Kirha endpoint
+  mcp-installer doctor --client claudecode --offline
+
+  # Apply the safe fixes
+  mcp-installer doctor --fix`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, client := range clients {
+				clientType, err := validateClient(client)
+				if err != nil {
+					return describeOperationError(err, client)
+				}
+				opts.Clients = append(opts.Clients, clientType)
+			}
+			return runDoctor(cmd, opts)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&clients, "client", "c", nil, "Client to diagnose, repeatable or comma-separated (default every installed client)")
+	cmd.Flags().BoolVar(&opts.Fix, "fix", false, "Apply the fixes that need no decision")
+	cmd.Flags().BoolVar(&opts.Offline, "offline", false, "Skip contacting the Kirha endpoint")
+
+	return cmd
+}
+
+func runDoctor(cmd *cobra.Command, opts installer.DoctorOptions) error {
+	app, err := di.ProvideInstallerApplication()
+	if err != nil {
+		return err
+	}
+
+	diagnoses, err := app.Doctor(cmd.Context(), opts)
+	if err != nil {
+		return describeOperationError(err, "")
+	}
+
+	if len(diagnoses) == 0 {
+		fmt.Println("No supported client detected. Use --client to diagnose one anyway")
+		return nil
+	}
+
+	failing, fixable := 0, 0
+	for i, diagnosis := range diagnoses {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s (%s)\n", diagnosis.Client, valueOrDash(diagnosis.ConfigPath))
+
+		for _, finding := range diagnosis.Findings {
+			status := string(finding.Severity)
+			if finding.Fixed {
+				status = "fixed"
+			}
+			fmt.Printf("  %-8s %-12s %s\n", status, finding.Check, finding.Message)
+
+			if !finding.Fixed {
+				if finding.FixErr != nil {
+					fmt.Printf("  %-8s %-12s fix failed: %v\n", "", "", finding.FixErr)
+				}
+				if finding.Hint != "" {
+					fmt.Printf("  %-8s %-12s -> %s\n", "", "", finding.Hint)
+				}
+			}
+
+			if finding.Fix != "" && !finding.Fixed && !opts.Fix {
+				fixable++
+			}
+		}
+
+		if diagnosis.Worst() == installer.SeverityError {
+			failing++
+		}
+	}
+
+	if fixable > 0 {
+		fmt.Printf("\n%d findings can be fixed automatically with --fix\n", fixable)
+	}
+	if failing > 0 {
+		return fmt.Errorf("%d of %d clients have errors", failing, len(diagnoses))
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.kirha.ai/mcp-installer/di"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+func NewCmdExport() *cobra.Command {
+	var client, format, output, signKey string
+	var servers []string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export MCP servers to a shareable bundle",
+		Long: `Export the MCP servers of a client to a client-neutral, versioned bundle in JSON
+or YAML that can be shared and installed into any client with 'import'.
+
+Header and environment values that look like secrets, such as API keys, are
+replaced by named placeholders like ${KIRHA_AUTHORIZATION}. Command arguments are
+exported as they are.
+
+With --sign-key, a detached signature is written next to the bundle.`,
+		Example: `  # Export every Claude Code server to a YAML bundle
+  mcp-installer export --client claudecode --output team-mcp.yaml
+
+  # Print the Kirha server of Codex as JSON
+  mcp-installer export --client codex --server kirha --format json
+
+  # Export and sign a bundle for distribution
+  mcp-installer export --client claudecode --output team-mcp.yaml --sign-key team-signing.key`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExport(cmd, client, servers, format, output, signKey)
+		},
+	}
+
+	cmd.Flags().StringVarP(&client, "client", "c", "", "Client to export servers from (required)")
+	cmd.Flags().StringSliceVar(&servers, "server", nil, "Name of a server to export, repeatable or comma-separated (default all)")
+	cmd.Flags().StringVar(&format, "format", "", "Bundle format: json or yaml (default from the output extension, else yaml)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "File to write the bundle to (default stdout)")
+	cmd.Flags().StringVar(&signKey, "sign-key", "", "Private key to sign the bundle with, requires --output")
+
+	_ = cmd.MarkFlagRequired("client")
+
+	return cmd
+}
+
+func runExport(cmd *cobra.Command, client string, servers []string, format, output, signKey string) error {
+	if signKey != "" && output == "" {
+		return fmt.Errorf("--sign-key requires --output")
+	}
+
+	clientType, err := validateClient(client)
+	if err != nil {
+		return describeOperationError(err, client)
+	}
+
+	bundleFormat, err := bundleFormat(format, output)
+	if err != nil {
+		return err
+	}
+
+	app, err := di.ProvideInstallerApplication()
+	if err != nil {
+		return err
+	}
+
+	data, bundle, err := app.ExportBundle(cmd.Context(), clientType, servers, bundleFormat)
+	if err != nil {
+		return describeOperationError(err, client)
+	}
+
+	for _, warning := range bundle.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	if output == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(output, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	fmt.Printf("Exported %d servers from %s to %s\n", len(bundle.Servers), clientType, output)
+	if signKey != "" {
+		keyID, err := signFile(data, signKey, output+signatureExtension)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Signed with key %s, signature written to %s%s\n", keyID, output, signatureExtension)
+	}
+	if len(bundle.Placeholders) > 0 {
+		fmt.Println("Secrets were replaced by placeholders, resolved on import:")
+		for _, placeholder := range bundle.Placeholders {
+			fmt.Printf("  %s  %s\n", placeholder.Name, placeholder.Description)
+		}
+	}
+
+	return nil
+}
+
+func bundleFormat(format, output string) (installer.BundleFormat, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(output)) {
+		case ".json":
+			return installer.BundleFormatJSON, nil
+		default:
+			return installer.BundleFormatYAML, nil
+		}
+	}
+
+	switch strings.ToLower(format) {
+	case "json":
+		return installer.BundleFormatJSON, nil
+	case "yaml", "yml":
+		return installer.BundleFormatYAML, nil
+	default:
+		return "", fmt.Errorf("unknown format %q, use json or yaml", format)
+	}
+}
@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.kirha.ai/mcp-installer/di"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/pkg/prompt"
+	"go.kirha.ai/mcp-installer/pkg/security"
+)
+
+func NewCmdImport() *cobra.Command {
+	flags := &operationFlags{}
+	var values []string
+	var signature string
+	var useEnv bool
+	var policy installer.SignaturePolicy
+
+	cmd := &cobra.Command{
+		Use:   "import <bundle>",
+		Short: "Install the MCP servers of a bundle into a client",
+		Long: `Install the MCP servers of a bundle created by 'export' into a client, translated
+to its configuration format. Servers the client already has are left untouched.
+
+Placeholders in the bundle take their value from --set, then from an environment
+variable of the same name, and are otherwise prompted for. Values found in the
+environment are shown and must be confirmed, or allowed up front with --use-env.
+Placeholders are only accepted in header and environment values, and must be
+declared by the bundle.
+
+A detached signature next to the bundle (<bundle>.sig, created by 'sign') is
+checked first: a bundle changed after signing is always refused. Keys are trusted
+with --trusted-key or under "signatures" in settings.json, where
+"require": true refuses bundles not signed by a trusted key.`,
+		Example: `  # Import a bundle into Codex, prompting for its secrets
+  mcp-installer import team-mcp.yaml --client codex
+
+  # Import non-interactively
+  KIRHA_AUTHORIZATION=your-api-key mcp-installer import team-mcp.yaml --client opencode --use-env
+
+  # Only import the bundle if it was signed by the team key
+  mcp-installer import team-mcp.yaml --client codex --trusted-key ed25519:... --require-signature`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runImport(cmd, flags, args[0], values, useEnv, signature, policy)
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.client, "client", "c", "", "Client to install the servers into (required)")
+	cmd.Flags().StringArrayVar(&values, "set", nil, "Value of a placeholder as NAME=VALUE, repeatable")
+	cmd.Flags().BoolVar(&useEnv, "use-env", false, "Take placeholder values from environment variables without asking")
+	cmd.Flags().StringVar(&signature, "signature", "", "Detached signature of the bundle (default <bundle>.sig when it exists)")
+	cmd.Flags().StringArrayVar(&policy.TrustedKeys, "trusted-key", nil, "Public key trusted to sign bundles, repeatable")
+	cmd.Flags().BoolVar(&policy.Require, "require-signature", false, "Refuse bundles not signed by a trusted key")
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "Show what would be installed without making changes")
+	cmd.Flags().BoolVarP(&flags.force, "force", "f", false, "Import even if the client is running")
+	addLockTimeoutFlag(cmd, flags)
+
+	_ = cmd.MarkFlagRequired("client")
+
+	return cmd
+}
+
+func runImport(cmd *cobra.Command, flags *operationFlags, path string, values []string, useEnv bool, signaturePath string, policy installer.SignaturePolicy) error {
+	clientType, err := validateClient(flags.client)
+	if err != nil {
+		return describeOperationError(err, flags.client)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	signature, err := readSignature(path, signaturePath)
+	if err != nil {
+		return err
+	}
+
+	app, err := di.ProvideInstallerApplication()
+	if err != nil {
+		return err
+	}
+
+	bundle, verification, err := app.ReadBundle(cmd.Context(), data, signature, policy)
+	if err != nil {
+		return describeOperationError(err, flags.client)
+	}
+
+	switch verification.Status {
+	case installer.SignatureVerified:
+		fmt.Printf("Signature verified, signed by trusted key %s\n\n", verification.KeyID)
+	case installer.SignatureUntrusted:
+		fmt.Printf("Warning: the bundle is signed by key %s, which is not trusted\n\n", verification.KeyID)
+	}
+
+	resolved, err := resolvePlaceholders(bundle, values, useEnv, flags.dryRun)
+	if err != nil {
+		return err
+	}
+
+	result, err := app.ImportBundle(cmd.Context(), &installer.Config{
+		Client:      clientType,
+		Operation:   installer.OperationImport,
+		DryRun:      flags.dryRun,
+		Force:       flags.force,
+		LockTimeout: flags.lockTimeout,
+	}, bundle, resolved)
+	if err != nil {
+		return describeOperationError(err, flags.client)
+	}
+
+	if len(result.Servers) == 0 {
+		fmt.Println("The bundle holds no MCP servers")
+		return nil
+	}
+
+	return printAddedServers(result, "import", "Imported")
+}
+
+// readSignature reads the detached signature of a bundle. Without an explicit
+// path, a missing <bundle>.sig means the bundle is unsigned.
+func readSignature(bundlePath, signaturePath string) ([]byte, error) {
+	explicit := signaturePath != ""
+	if !explicit {
+		signaturePath = bundlePath + signatureExtension
+	}
+
+	signature, err := os.ReadFile(signaturePath)
+	if os.IsNotExist(err) && !explicit {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature: %w", err)
+	}
+
+	return signature, nil
+}
+
+// resolvePlaceholders collects a value for every placeholder the bundle
+// declares. Since the bundle chooses the names, values found in environment
+// variables are shown and must be confirmed, with a prompt or --use-env.
+// Dry runs never prompt nor read the environment; placeholders without a
+// value are kept as they are.
+func resolvePlaceholders(bundle *installer.Bundle, values []string, useEnv, dryRun bool) (map[string]string, error) {
+	descriptions := make(map[string]string)
+	var names []string
+	for _, placeholder := range bundle.Placeholders {
+		if _, ok := descriptions[placeholder.Name]; !ok {
+			names = append(names, placeholder.Name)
+		}
+		descriptions[placeholder.Name] = placeholder.Description
+	}
+
+	resolved := make(map[string]string)
+	for _, value := range values {
+		name, v, ok := strings.Cut(value, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --set %q, expected NAME=VALUE", value)
+		}
+		if _, ok := descriptions[name]; !ok {
+			return nil, fmt.Errorf("invalid --set %q, the bundle has no placeholder %s", value, name)
+		}
+		resolved[name] = v
+	}
+
+	var fromEnv []string
+	for _, name := range names {
+		if _, ok := resolved[name]; ok {
+			continue
+		}
+		if dryRun {
+			resolved[name] = "${" + name + "}"
+			continue
+		}
+		if value, ok := os.LookupEnv(name); ok {
+			resolved[name] = value
+			fromEnv = append(fromEnv, name)
+			continue
+		}
+		if !prompt.IsTerminal(os.Stdin) {
+			continue
+		}
+
+		label := name
+		if description := descriptions[name]; description != "" {
+			label += " (" + description + ")"
+		}
+		value, err := prompt.Secret(label + ": ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		resolved[name] = value
+	}
+
+	if len(fromEnv) > 0 && !useEnv {
+		if err := confirmEnvValues(fromEnv, resolved, descriptions); err != nil {
+			return nil, err
+		}
+	}
+
+	return resolved, nil
+}
+
+func confirmEnvValues(names []string, resolved, descriptions map[string]string) error {
+	if !prompt.IsTerminal(os.Stdin) {
+		return fmt.Errorf("placeholders %s would take their value from environment variables, pass --use-env to allow it or set them with --set", strings.Join(names, ", "))
+	}
+
+	fmt.Fprintln(os.Stderr, "These placeholders take their value from environment variables:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s=%s  %s\n", name, security.MaskAPIKey(resolved[name]), descriptions[name])
+	}
+
+	answer, err := prompt.Line("Use these values? [y/N]: ")
+	if err != nil {
+		return fmt.Errorf("failed to read answer: %w", err)
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "y" && answer != "yes" {
+		return fmt.Errorf("import cancelled, nothing was changed")
+	}
+
+	return nil
+}
@@ -6,14 +6,7 @@ import (
 )
 
 func NewCmdInstall() *cobra.Command {
-	var (
-		client     string
-		apiKey     string
-		configPath string
-		dryRun     bool
-		verbose    bool
-		force      bool
-	)
+	flags := &operationFlags{}
 
 	cmd := &cobra.Command{
 		Use:   "install",
@@ -25,6 +18,12 @@ If the server already exists, the command will fail with a suggestion to use 'up
 		Example: `  # Install for Claude Code CLI
   mcp-installer install --client claudecode --key your-api-key-here
 
+  # Install for Claude Code in the current project only, without sharing it
+  mcp-installer install --client claudecode --scope local --key your-api-key-here
+
+  # Install for Claude Code in the project's .mcp.json, approved for use
+  mcp-installer install --client claudecode --scope project --key your-api-key-here
+
   # Install for Codex with dry run
   mcp-installer install --client codex --key your-api-key-here --dry-run
 
@@ -35,20 +34,28 @@ If the server already exists, the command will fail with a suggestion to use 'up
   mcp-installer install --client droid --key your-api-key-here
 
   # Install for Gemini CLI with verbose output
-  mcp-installer install --client gemini --key your-api-key-here --verbose`,
+  mcp-installer install --client gemini --key your-api-key-here --verbose
+
+  # Install for several clients at once
+  mcp-installer install --client claudecode,codex,opencode --key your-api-key-here
+
+  # Install for every client detected on this machine
+  mcp-installer install --all-detected --key your-api-key-here`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runOperation(cmd, installer.OperationInstall, client, apiKey, configPath, dryRun, verbose, force)
+			return runOperation(cmd, installer.OperationInstall, flags)
 		},
 	}
 
-	cmd.Flags().StringVarP(&client, "client", "c", "", "Client to install for (claudecode, codex, opencode, gemini, droid) (required)")
-	cmd.Flags().StringVarP(&apiKey, "key", "k", "", "API key for Kirha MCP server (required)")
-	cmd.Flags().StringVar(&configPath, "config-path", "", "Custom configuration file path (optional)")
-	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be changed without making changes")
-	cmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging")
-	cmd.Flags().BoolVarP(&force, "force", "f", false, "Force installation even if the client is running")
+	cmd.Flags().StringVarP(&flags.client, "client", "c", "", "Comma-separated clients to install for (claudecode, codex, opencode, gemini, droid)")
+	cmd.Flags().StringVarP(&flags.apiKey, "key", "k", "", "API key for Kirha MCP server (required)")
+	cmd.Flags().StringVar(&flags.configPath, "config-path", "", "Custom configuration file path (optional)")
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "Show what would be changed without making changes")
+	cmd.Flags().BoolVar(&flags.verbose, "verbose", false, "Enable verbose logging")
+	cmd.Flags().BoolVarP(&flags.force, "force", "f", false, "Force installation even if the client is running")
+	addScopeFlag(cmd, flags)
+	addMultiClientFlags(cmd, flags)
+	addLockTimeoutFlag(cmd, flags)
 
-	_ = cmd.MarkFlagRequired("client")
 	_ = cmd.MarkFlagRequired("key")
 
 	return cmd
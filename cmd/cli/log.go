@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.kirha.ai/mcp-installer/di"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+type logFlags struct {
+	client      string
+	operationID string
+	operation   string
+	result      string
+	since       string
+	limit       int
+	jsonOutput  bool
+}
+
+func NewCmdLog() *cobra.Command {
+	flags := &logFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "log",
+		Short: "Show the audit log of configuration changes",
+		Long: `Show the audit log of every install, update, remove, restore and undo.
+
+Each record holds the operation ID, client, configuration path, content hashes
+before and after the change, a fingerprint of the API key (never the key itself),
+the tool version, the user who ran the command and the result.`,
+		Example: `  # Show the 20 most recent changes
+  mcp-installer log
+
+  # Show failed changes to Codex during the last week
+  mcp-installer log --client codex --result failed --since 7d
+
+  # Print every record of one operation as JSON lines
+  mcp-installer log --operation-id op-20250101-120000-a1b2c3 --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLog(cmd, flags)
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.client, "client", "c", "", "Only show changes to this client")
+	cmd.Flags().StringVar(&flags.operationID, "operation-id", "", "Only show records of this operation")
+	cmd.Flags().StringVar(&flags.operation, "operation", "", "Only show this kind of operation (install, update, remove, restore, undo)")
+	cmd.Flags().StringVar(&flags.result, "result", "", "Only show records with this result (success, failed, rolled_back)")
+	cmd.Flags().StringVar(&flags.since, "since", "", "Only show records newer than this, e.g. 24h, 7d or 2025-01-31")
+	cmd.Flags().IntVarP(&flags.limit, "limit", "n", 20, "Maximum number of records to show, most recent last (0 for all)")
+	cmd.Flags().BoolVar(&flags.jsonOutput, "json", false, "Print records as JSON lines")
+
+	return cmd
+}
+
+func runLog(cmd *cobra.Command, flags *logFlags) error {
+	filter := installer.AuditFilter{
+		OperationID: flags.operationID,
+		Operation:   installer.OperationType(flags.operation),
+		Result:      installer.AuditResult(flags.result),
+		Limit:       flags.limit,
+	}
+
+	if flags.client != "" {
+		clientType, err := validateClient(flags.client)
+		if err != nil {
+			return describeOperationError(err, flags.client)
+		}
+		filter.Client = clientType
+	}
+
+	if flags.since != "" {
+		since, err := parseSince(flags.since, time.Now())
+		if err != nil {
+			return err
+		}
+		filter.Since = since
+	}
+
+	app, err := di.ProvideInstallerApplication()
+	if err != nil {
+		return err
+	}
+
+	records, err := app.ReadAuditLog(cmd.Context(), filter)
+	if err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	if flags.jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(records) == 0 {
+		fmt.Println("No matching audit records")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tOPERATION ID\tOPERATION\tCLIENT\tRESULT\tUSER\tKEY\tBEFORE\tAFTER\tCONFIG PATH")
+	for _, record := range records {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			record.Time.Local().Format("2006-01-02 15:04:05"),
+			valueOrDash(record.OperationID),
+			record.Operation,
+			record.Client,
+			record.Result,
+			valueOrDash(record.User),
+			valueOrDash(record.KeyFingerprint),
+			shortHash(record.BeforeHash),
+			shortHash(record.AfterHash),
+			valueOrDash(record.ConfigPath))
+	}
+
+	return w.Flush()
+}
+
+// parseSince accepts either an age such as "24h" or "7d", or a date.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if age, err := installer.ParseAge(value); err == nil {
+		return now.Add(-age), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid --since value %q, use an age like 24h or 7d, or a date like 2025-01-31", value)
+}
+
+func shortHash(hash string) string {
+	if hash == "" {
+		return "-"
+	}
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
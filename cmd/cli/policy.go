@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"go.kirha.ai/mcp-installer/di"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+func NewCmdPolicy() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Audit client configurations against the organization policy",
+		Long: `Organization policies restrict which MCP servers may be written to client
+configurations. They are read from policy.json in the system-wide config directory
+(/etc/kirha-mcp-installer on Linux) and in the user config directory; a server
+must satisfy both.
+
+Every command that writes a server checks it first and refuses to save it when a
+rule is broken. 'policy check' audits the servers already configured.`,
+		Example: `  # Audit every client with a configuration file
+  mcp-installer policy check
+
+  # Audit Codex only
+  mcp-installer policy check --client codex`,
+	}
+
+	cmd.AddCommand(newCmdPolicyCheck())
+
+	return cmd
+}
+
+func newCmdPolicyCheck() *cobra.Command {
+	var clients []string
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Report MCP servers that break the organization policy",
+		Long: `Check the MCP servers configured in each client against the organization policies
+and report every violation by rule. Exits with an error when any is found.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPolicyCheck(cmd, clients)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&clients, "client", "c", nil, "Client to audit, repeatable or comma-separated (default every configured client)")
+
+	return cmd
+}
+
+func runPolicyCheck(cmd *cobra.Command, clients []string) error {
+	var clientTypes []installer.ClientType
+	for _, client := range clients {
+		clientType, err := validateClient(client)
+		if err != nil {
+			return describeOperationError(err, client)
+		}
+		clientTypes = append(clientTypes, clientType)
+	}
+
+	app, err := di.ProvideInstallerApplication()
+	if err != nil {
+		return err
+	}
+
+	report, err := app.CheckPolicy(cmd.Context(), clientTypes)
+	if err != nil {
+		return describeOperationError(err, strings.Join(clients, ","))
+	}
+
+	if len(report.Policies) == 0 {
+		fmt.Println("No policy file found, every server is allowed")
+		return nil
+	}
+	fmt.Printf("Policies: %s\n\n", strings.Join(report.Policies, ", "))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLIENT\tSERVERS\tVIOLATIONS\tCONFIG PATH")
+	for _, client := range report.Clients {
+		violations := fmt.Sprintf("%d", len(client.Violations))
+		if client.Err != nil {
+			violations = "error: " + client.Err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", client.Client, client.Servers, violations, valueOrDash(client.ConfigPath))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	var violations []installer.PolicyViolation
+	for _, client := range report.Clients {
+		violations = append(violations, client.Violations...)
+	}
+	if len(violations) == 0 {
+		fmt.Println("\nEvery server complies with the policy")
+		return nil
+	}
+
+	fmt.Printf("\n%s\n\n", formatViolations(violations))
+
+	return fmt.Errorf("%d policy violations found", len(violations))
+}
+
+// formatViolations lists violations grouped by rule, one per line.
+func formatViolations(violations []installer.PolicyViolation) string {
+	byRule := make(map[installer.PolicyRule][]installer.PolicyViolation)
+	var rules []installer.PolicyRule
+	for _, violation := range violations {
+		if _, ok := byRule[violation.Rule]; !ok {
+			rules = append(rules, violation.Rule)
+		}
+		byRule[violation.Rule] = append(byRule[violation.Rule], violation)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i] < rules[j] })
+
+	var b strings.Builder
+	for _, rule := range rules {
+		fmt.Fprintf(&b, "%s:\n", rule)
+		for _, violation := range byRule[rule] {
+			fmt.Fprintf(&b, "  %s/%s: %s (%s)\n", violation.Client, violation.Server, violation.Message, violation.Policy)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.kirha.ai/mcp-installer/di"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+func NewCmdPurge() *cobra.Command {
+	flags := &operationFlags{}
+	var deleteBackups bool
+
+	cmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Remove Kirha from every client and clean up stored API keys",
+		Long: `Remove the Kirha MCP server from every client configuration that has it, in a
+single transaction: if any client fails, every client is rolled back.
+
+Once removed, purge forgets the installer state and redacts the Kirha API keys
+kept in installer backups, so restoring one of them no longer brings a key back.
+Tokens of other servers are kept. Use --delete-backups to delete those backups
+instead. Backup files written next to
+the configuration by older versions of the installer are deleted.`,
+		Example: `  # Show what would be removed and cleaned
+  mcp-installer purge --dry-run
+
+  # Remove Kirha everywhere, even from running clients
+  mcp-installer purge --force
+
+  # Also delete every backup that held a Kirha API key
+  mcp-installer purge --delete-backups`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPurge(cmd, flags, deleteBackups)
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "Show what would be removed without making changes")
+	cmd.Flags().BoolVarP(&flags.force, "force", "f", false, "Remove even if clients are running")
+	cmd.Flags().BoolVar(&deleteBackups, "delete-backups", false, "Delete backups holding API keys instead of redacting them")
+	cmd.Flags().IntVar(&flags.parallel, "parallel", 4, "Maximum number of clients processed concurrently")
+	cmd.Flags().DurationVar(&flags.timeout, "timeout", 30*time.Second, "Timeout for each client operation")
+	addLockTimeoutFlag(cmd, flags)
+
+	return cmd
+}
+
+func runPurge(cmd *cobra.Command, flags *operationFlags, deleteBackups bool) error {
+	app, err := di.ProvideInstallerApplication()
+	if err != nil {
+		return err
+	}
+
+	result, err := app.Purge(cmd.Context(), &installer.Config{
+		Operation:   installer.OperationPurge,
+		DryRun:      flags.dryRun,
+		Force:       flags.force,
+		LockTimeout: flags.lockTimeout,
+	}, installer.PurgeOptions{
+		BatchOptions: installer.BatchOptions{
+			Concurrency: flags.parallel,
+			Timeout:     flags.timeout,
+			LockTimeout: flags.lockTimeout,
+		},
+		DeleteBackups: deleteBackups,
+	})
+	if result == nil {
+		return fmt.Errorf("purge failed: %w", err)
+	}
+
+	if printErr := printPurgeResult(result); printErr != nil {
+		return printErr
+	}
+	if err != nil {
+		return fmt.Errorf("purge failed, all client changes were rolled back: %w", err)
+	}
+
+	return nil
+}
+
+func printPurgeResult(result *installer.PurgeResult) error {
+	if len(result.Clients) == 0 {
+		fmt.Println("No client has the Kirha MCP server configured")
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CLIENT\tSTATUS\tDETAILS")
+		for _, client := range result.Clients {
+			status := "removed"
+			details := ""
+			switch {
+			case client.Err != nil:
+				status = "failed"
+				details = describeOperationError(client.Err, string(client.Client)).Error()
+			case client.RolledBack:
+				status = "rolled back"
+			case result.DryRun:
+				status = "would remove"
+			}
+			if details == "" && client.Result != nil {
+				details = client.Result.ConfigPath
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", clientLabel(client.Client, client.Scope, client.Project), status, details)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	for _, skipped := range result.Skipped {
+		fmt.Printf("Skipped %s, its configuration could not be read: %s\n", clientLabel(skipped.Client, skipped.Scope, skipped.Project), skipped.Error)
+	}
+
+	verb := func(done, planned string) string {
+		if result.DryRun {
+			return planned
+		}
+		return done
+	}
+
+	fmt.Println()
+	fmt.Printf("Installer state entries %s: %d\n", verb("forgotten", "to forget"), result.ForgottenState)
+	if len(result.DeletedBackups) > 0 {
+		fmt.Printf("Backups holding API keys %s: %d\n", verb("deleted", "to delete"), len(result.DeletedBackups))
+	} else {
+		fmt.Printf("Backups holding API keys %s: %d\n", verb("redacted", "to redact"), len(result.ScrubbedBackups))
+	}
+	fmt.Printf("Legacy backup files %s: %d\n", verb("deleted", "to delete"), len(result.LegacyBackups))
+	for _, path := range result.LegacyBackups {
+		fmt.Printf("  %s\n", path)
+	}
+
+	if result.OperationID != "" {
+		fmt.Printf("Operation ID: %s\n", result.OperationID)
+	}
+
+	return nil
+}
@@ -6,13 +6,7 @@ import (
 )
 
 func NewCmdRemove() *cobra.Command {
-	var (
-		client     string
-		configPath string
-		dryRun     bool
-		verbose    bool
-		force      bool
-	)
+	flags := &operationFlags{}
 
 	cmd := &cobra.Command{
 		Use:   "remove",
@@ -31,19 +25,24 @@ If the server doesn't exist, the command will fail with an appropriate message.`
   mcp-installer remove --client opencode --verbose
 
   # Remove from Droid (Factory AI)
-  mcp-installer remove --client droid`,
+  mcp-installer remove --client droid
+
+  # Remove from several clients at once
+  mcp-installer remove --client claudecode,codex`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runOperation(cmd, installer.OperationRemove, client, "", configPath, dryRun, verbose, force)
+			return runOperation(cmd, installer.OperationRemove, flags)
 		},
 	}
 
-	cmd.Flags().StringVarP(&client, "client", "c", "", "Client to remove MCP server from (claudecode, codex, opencode, gemini, droid) (required)")
-	cmd.Flags().StringVar(&configPath, "config-path", "", "Custom configuration file path (optional)")
-	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be changed without making changes")
-	cmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging")
-	cmd.Flags().BoolVarP(&force, "force", "f", false, "Force removal even if the client is running")
+	cmd.Flags().StringVarP(&flags.client, "client", "c", "", "Comma-separated clients to remove MCP server from (claudecode, codex, opencode, gemini, droid)")
+	cmd.Flags().StringVar(&flags.configPath, "config-path", "", "Custom configuration file path (optional)")
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "Show what would be changed without making changes")
+	cmd.Flags().BoolVar(&flags.verbose, "verbose", false, "Enable verbose logging")
+	cmd.Flags().BoolVarP(&flags.force, "force", "f", false, "Force removal even if the client is running")
 
-	_ = cmd.MarkFlagRequired("client")
+	addScopeFlag(cmd, flags)
+	addMultiClientFlags(cmd, flags)
+	addLockTimeoutFlag(cmd, flags)
 
 	return cmd
 }
@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.kirha.ai/mcp-installer/di"
+	installerApp "go.kirha.ai/mcp-installer/internal/applications/installer"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/pkg/prompt"
+	"go.kirha.ai/mcp-installer/pkg/security"
+	"go.kirha.ai/mcp-installer/pkg/textdiff"
+)
+
+const repairDiffContext = 3
+
+func NewCmdRepair() *cobra.Command {
+	flags := &operationFlags{}
+	var apply string
+
+	cmd := &cobra.Command{
+		Use:   "repair",
+		Short: "Repair a client configuration that fails to parse",
+		Long: `Report where a client configuration fails to parse and offer two ways to repair it:
+restoring the most recent backup that parses, or applying safe fixes that remove
+trailing commas, a byte order mark and duplicate keys (the last value is kept).
+
+The changes each option would make are shown as a diff, with API keys masked. On
+a terminal you are asked which one to apply; otherwise pass --apply. The current
+content is backed up first, so a repair can be reverted with 'mcp-installer undo'.`,
+		Example: `  # Show what can be done and choose interactively
+  mcp-installer repair --client claudecode
+
+  # Apply the safe fixes without asking
+  mcp-installer repair --client codex --apply fix
+
+  # Restore the most recent backup that parses
+  mcp-installer repair --client gemini --apply restore`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientType, err := validateClient(flags.client)
+			if err != nil {
+				return describeOperationError(err, flags.client)
+			}
+			action := installer.RepairAction(apply)
+			if action != "" && action != installer.RepairFix && action != installer.RepairRestore {
+				return fmt.Errorf("invalid --apply %q, expected fix or restore", apply)
+			}
+			return runRepair(cmd, clientType, action, flags)
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.client, "client", "c", "", "Client whose configuration to repair (claudecode, codex, opencode, gemini, droid) (required)")
+	cmd.Flags().StringVar(&apply, "apply", "", "Apply an option without asking: fix or restore")
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "Show the options without making changes")
+	cmd.Flags().BoolVarP(&flags.force, "force", "f", false, "Repair even if the client is running")
+	addLockTimeoutFlag(cmd, flags)
+	_ = cmd.MarkFlagRequired("client")
+
+	return cmd
+}
+
+func runRepair(cmd *cobra.Command, clientType installer.ClientType, action installer.RepairAction, flags *operationFlags) error {
+	app, err := di.ProvideInstallerApplication()
+	if err != nil {
+		return err
+	}
+
+	plan, err := app.PlanRepair(cmd.Context(), clientType)
+	if err != nil {
+		return describeOperationError(err, string(clientType))
+	}
+
+	printRepairPlan(plan)
+
+	if !plan.NeedsRepair() {
+		return nil
+	}
+
+	options := repairOptions(plan)
+	if len(options) == 0 {
+		return fmt.Errorf("no automatic repair is available for %s, fix the error by hand", plan.ConfigPath)
+	}
+
+	if action != "" && !plan.Available(action) {
+		return fmt.Errorf("%s is not available for %s, use --apply %s", action, plan.ConfigPath, joinActions(options))
+	}
+
+	if action == "" {
+		if flags.dryRun {
+			return nil
+		}
+		if !prompt.IsTerminal(os.Stdin) {
+			return fmt.Errorf("pass --apply %s to choose a repair", joinActions(options))
+		}
+		action, err = chooseRepair(options)
+		if err != nil || action == "" {
+			return err
+		}
+	}
+
+	return applyRepair(cmd, app, plan, action, flags)
+}
+
+func applyRepair(cmd *cobra.Command, app *installerApp.Application, plan *installer.RepairPlan, action installer.RepairAction, flags *operationFlags) error {
+	result, err := app.Repair(cmd.Context(), &installer.Config{
+		Operation:   installer.OperationRepair,
+		DryRun:      flags.dryRun,
+		Force:       flags.force,
+		LockTimeout: flags.lockTimeout,
+	}, plan, action)
+	if err != nil {
+		return describeOperationError(err, string(plan.Client))
+	}
+
+	fmt.Println(result.Message)
+	if result.OperationID != "" {
+		fmt.Printf("Undo with 'mcp-installer undo %s'\n", result.OperationID)
+	}
+
+	return nil
+}
+
+func printRepairPlan(plan *installer.RepairPlan) {
+	fmt.Printf("Configuration: %s\n", plan.ConfigPath)
+
+	if plan.ParseErr == nil && len(plan.Fixes) == 0 {
+		fmt.Println("The configuration parses and needs no repair.")
+		return
+	}
+
+	if plan.ParseErr != nil {
+		fmt.Printf("Error: %v\n", plan.ParseErr)
+	} else {
+		fmt.Println("The configuration parses, but some safe fixes apply.")
+	}
+
+	if len(plan.Fixes) > 0 {
+		fmt.Println("\nSafe fixes:")
+		for _, fix := range plan.Fixes {
+			fmt.Printf("  - %s\n", fix)
+		}
+		if plan.FixedErr != nil {
+			fmt.Printf("They are not enough to make the file parse: %v\n", plan.FixedErr)
+		} else {
+			printRepairDiff(plan.Content, plan.Fixed, "fixed")
+		}
+	}
+
+	if plan.ParseErr != nil {
+		if plan.Backup == nil {
+			fmt.Println("\nNo backup of this file parses.")
+		} else {
+			fmt.Printf("\nMost recent backup that parses: %s (%s operation, %s)\n",
+				plan.Backup.ID, plan.Backup.Operation, plan.Backup.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+			printRepairDiff(plan.Content, plan.BackupContent, "backup "+plan.Backup.ID)
+		}
+	}
+}
+
+func printRepairDiff(before, after []byte, label string) {
+	diff := textdiff.Unified(string(before), string(after), "current", label, repairDiffContext)
+	fmt.Println()
+	fmt.Print(security.MaskBearerTokens(diff))
+}
+
+func repairOptions(plan *installer.RepairPlan) []installer.RepairAction {
+	var options []installer.RepairAction
+	for _, action := range []installer.RepairAction{installer.RepairFix, installer.RepairRestore} {
+		if plan.Available(action) {
+			options = append(options, action)
+		}
+	}
+	return options
+}
+
+func chooseRepair(options []installer.RepairAction) (installer.RepairAction, error) {
+	answer, err := prompt.Line(fmt.Sprintf("\nApply which repair? [%s/no]: ", joinActions(options)))
+	if err != nil {
+		return "", fmt.Errorf("failed to read answer: %w", err)
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	for _, option := range options {
+		if answer == string(option) {
+			return option, nil
+		}
+	}
+	if answer == "" || answer == "n" || answer == "no" {
+		fmt.Println("Nothing was changed.")
+		return "", nil
+	}
+
+	return "", fmt.Errorf("invalid answer %q, expected %s", answer, joinActions(options))
+}
+
+func joinActions(actions []installer.RepairAction) string {
+	names := make([]string, len(actions))
+	for i, action := range actions {
+		names[i] = string(action)
+	}
+	return strings.Join(names, "|")
+}
@@ -32,6 +32,9 @@ Supported clients:
   # Show current configuration
   mcp-installer show --client claudecode
 
+  # Detect which clients are installed
+  mcp-installer detect
+
   # Dry run to see what would be changed
   mcp-installer install --client opencode --key your-api-key-here --dry-run`,
 		SilenceUsage:  true,
@@ -52,6 +55,22 @@ Supported clients:
 	cmd.AddCommand(NewCmdUpdate())
 	cmd.AddCommand(NewCmdRemove())
 	cmd.AddCommand(NewCmdShow())
+	cmd.AddCommand(NewCmdDetect())
+	cmd.AddCommand(NewCmdDoctor())
+	cmd.AddCommand(NewCmdRepair())
+	cmd.AddCommand(NewCmdBackups())
+	cmd.AddCommand(NewCmdUndo())
+	cmd.AddCommand(NewCmdLog())
+	cmd.AddCommand(NewCmdPurge())
+	cmd.AddCommand(NewCmdRotateKey())
+	cmd.AddCommand(NewCmdSync())
+	cmd.AddCommand(NewCmdCopy())
+	cmd.AddCommand(NewCmdDiff())
+	cmd.AddCommand(NewCmdExport())
+	cmd.AddCommand(NewCmdImport())
+	cmd.AddCommand(NewCmdKeygen())
+	cmd.AddCommand(NewCmdSign())
+	cmd.AddCommand(NewCmdPolicy())
 	cmd.AddCommand(NewCmdVersion())
 	cmd.AddCommand(NewCmdUpdateVersion())
 
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.kirha.ai/mcp-installer/di"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/pkg/security"
+)
+
+func NewCmdRotateKey() *cobra.Command {
+	flags := &operationFlags{}
+	var oldKey string
+
+	cmd := &cobra.Command{
+		Use:   "rotate-key",
+		Short: "Replace the Kirha API key in every client",
+		Long: `Replace the API key of the Kirha MCP server in every client configuration that has
+it, in a single transaction: if any client fails, every client is rolled back.
+
+With --old-key, only the clients using that key are changed; the others are
+reported and left alone. The replaced key is then redacted from the backups.`,
+		Example: `  # Set a new key everywhere Kirha is configured
+  mcp-installer rotate-key --key your-new-api-key
+
+  # Only replace a specific revoked key
+  mcp-installer rotate-key --key your-new-api-key --old-key your-old-api-key
+
+  # Show which clients would change
+  mcp-installer rotate-key --key your-new-api-key --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRotateKey(cmd, flags, oldKey)
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.apiKey, "key", "k", "", "New API key for Kirha MCP server (required)")
+	cmd.Flags().StringVar(&oldKey, "old-key", "", "Only replace this key (optional - replaces any key if not provided)")
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "Show what would be changed without making changes")
+	cmd.Flags().BoolVarP(&flags.force, "force", "f", false, "Rotate even if clients are running")
+	cmd.Flags().IntVar(&flags.parallel, "parallel", 4, "Maximum number of clients processed concurrently")
+	cmd.Flags().DurationVar(&flags.timeout, "timeout", 30*time.Second, "Timeout for each client operation")
+	addLockTimeoutFlag(cmd, flags)
+
+	_ = cmd.MarkFlagRequired("key")
+
+	return cmd
+}
+
+func runRotateKey(cmd *cobra.Command, flags *operationFlags, oldKey string) error {
+	app, err := di.ProvideInstallerApplication()
+	if err != nil {
+		return err
+	}
+
+	result, err := app.RotateKey(cmd.Context(), &installer.Config{
+		ApiKey:      flags.apiKey,
+		Operation:   installer.OperationUpdate,
+		DryRun:      flags.dryRun,
+		Force:       flags.force,
+		LockTimeout: flags.lockTimeout,
+	}, oldKey, installer.BatchOptions{
+		Concurrency: flags.parallel,
+		Timeout:     flags.timeout,
+		LockTimeout: flags.lockTimeout,
+	})
+	if result == nil {
+		// Errors tied to a client, such as it running, carry its name.
+		return describeOperationError(err, "")
+	}
+
+	if len(result.Clients) == 0 {
+		fmt.Println("No client has the Kirha MCP server configured")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLIENT\tSTATUS\tDETAILS")
+	for _, rotation := range result.Clients {
+		details := rotation.ConfigPath
+		if rotation.Err != nil {
+			details = describeOperationError(rotation.Err, string(rotation.Client)).Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", clientLabel(rotation.Client, rotation.Scope, rotation.Project), rotation.Status, details)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if err != nil {
+		if flags.dryRun {
+			return fmt.Errorf("key rotation would fail: %w", err)
+		}
+		return fmt.Errorf("key rotation failed, all changes were rolled back: %w", err)
+	}
+
+	if result.OperationID != "" {
+		fmt.Printf("\nNew key %s set, operation ID: %s\n", security.KeyFingerprint(flags.apiKey), result.OperationID)
+	}
+
+	return nil
+}
@@ -1,16 +1,18 @@
 package cli
 
 import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
 	"github.com/spf13/cobra"
+	"go.kirha.ai/mcp-installer/di"
 	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
 )
 
 func NewCmdShow() *cobra.Command {
-	var (
-		client     string
-		configPath string
-		verbose    bool
-	)
+	flags := &operationFlags{}
+	var managed bool
 
 	cmd := &cobra.Command{
 		Use:   "show",
@@ -18,7 +20,11 @@ func NewCmdShow() *cobra.Command {
 		Long: `Display the current MCP server configuration for the specified development environment.
 
 This command will show the existing MCP server configuration, including any Kirha MCP servers
-and other MCP servers that are configured. API keys will be masked for security.`,
+and other MCP servers that are configured. API keys will be masked for security.
+
+With --managed, list the Kirha entries tracked by the installer and whether each
+one is still as the installer wrote it (in_sync), was edited by hand (modified),
+was removed by hand (missing), or exists without being tracked (unmanaged).`,
 		Example: `  # Show MCP server configuration for Claude Code CLI
   mcp-installer show --client claudecode
 
@@ -29,17 +35,73 @@ and other MCP servers that are configured. API keys will be masked for security.
   mcp-installer show --client opencode
 
   # Show configuration for Droid (Factory AI)
-  mcp-installer show --client droid`,
+  mcp-installer show --client droid
+
+  # Show the entries managed by the installer on every client
+  mcp-installer show --managed`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runOperation(cmd, installer.OperationShow, client, "", configPath, false, verbose, false)
+			if managed {
+				return runShowManaged(cmd, flags)
+			}
+			if flags.client == "" {
+				return fmt.Errorf("--client is required")
+			}
+			return runOperation(cmd, installer.OperationShow, flags)
 		},
 	}
 
-	cmd.Flags().StringVarP(&client, "client", "c", "", "Client to show configuration for (claudecode, codex, opencode, gemini, droid) (required)")
-	cmd.Flags().StringVar(&configPath, "config-path", "", "Custom configuration file path (optional)")
-	cmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging")
-
-	_ = cmd.MarkFlagRequired("client")
+	cmd.Flags().StringVarP(&flags.client, "client", "c", "", "Client to show configuration for (claudecode, codex, opencode, gemini, droid) (required unless --managed)")
+	cmd.Flags().StringVar(&flags.configPath, "config-path", "", "Custom configuration file path (optional)")
+	cmd.Flags().BoolVar(&flags.verbose, "verbose", false, "Enable verbose logging")
+	addScopeFlag(cmd, flags)
+	cmd.Flags().BoolVar(&managed, "managed", false, "List the entries managed by the installer and their drift status")
 
 	return cmd
 }
+
+func runShowManaged(cmd *cobra.Command, flags *operationFlags) error {
+	var clients []installer.ClientType
+	if flags.client != "" {
+		parsed, err := parseClients(flags.client)
+		if err != nil {
+			return err
+		}
+		clients = parsed
+	}
+
+	app, err := di.ProvideInstallerApplication()
+	if err != nil {
+		return err
+	}
+
+	statuses, err := app.ManagedStatus(cmd.Context(), clients)
+	if err != nil {
+		return fmt.Errorf("failed to read installer state: %w", err)
+	}
+
+	if len(statuses) == 0 {
+		fmt.Println("No Kirha MCP servers found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLIENT\tSCOPE\tPROJECT\tSTATUS\tPROFILE\tCREATED FILE\tUPDATED\tCONFIG PATH")
+	for _, status := range statuses {
+		drift := string(status.Drift)
+		if status.Error != "" {
+			drift = "error: " + status.Error
+		}
+
+		profile, createdFile, updated := "-", "-", "-"
+		if status.Managed != nil {
+			profile = valueOrDash(status.Managed.Profile)
+			createdFile = fmt.Sprintf("%t", status.Managed.CreatedFile)
+			updated = status.Managed.UpdatedAt.Local().Format("2006-01-02 15:04:05")
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			status.Client, status.Scope, valueOrDash(status.Project), drift, profile, createdFile, updated, valueOrDash(status.ConfigPath))
+	}
+
+	return w.Flush()
+}
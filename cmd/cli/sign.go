@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.kirha.ai/mcp-installer/pkg/signing"
+)
+
+const (
+	signatureExtension = ".sig"
+	publicKeyExtension = ".pub"
+)
+
+func NewCmdKeygen() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Generate a key pair for signing bundles",
+		Long: `Generate an ed25519 key pair for signing bundles. The private key is written to
+--output, readable only by you, and the public key to the same path with a .pub
+extension. Share the public key with the engineers who import your bundles.`,
+		Example: `  mcp-installer keygen --output team-signing.key`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runKeygen(output)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "File to write the private key to (required)")
+	_ = cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+func runKeygen(output string) error {
+	public, private, err := signing.GenerateKey()
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	file, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create private key file: %w", err)
+	}
+	if _, err := fmt.Fprintln(file, signing.EncodePrivateKey(private)); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write private key: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write private key: %w", err)
+	}
+
+	encoded := signing.EncodePublicKey(public)
+	if err := os.WriteFile(output+publicKeyExtension, []byte(encoded+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write public key: %w", err)
+	}
+
+	fmt.Printf("Private key: %s (keep it secret)\n", output)
+	fmt.Printf("Public key:  %s%s\n\n", output, publicKeyExtension)
+	fmt.Printf("%s\n\nKey ID: %s\n", encoded, signing.KeyID(public))
+
+	return nil
+}
+
+func NewCmdSign() *cobra.Command {
+	var keyPath, output string
+
+	cmd := &cobra.Command{
+		Use:   "sign <bundle>",
+		Short: "Sign a bundle with a private key",
+		Long: `Write a detached ed25519 signature of a bundle, by default to <bundle>.sig.
+'import' checks it against the trusted keys. The bundle must not be changed
+after signing, not even reformatted.`,
+		Example: `  mcp-installer sign team-mcp.yaml --key team-signing.key`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSign(args[0], keyPath, output)
+		},
+	}
+
+	cmd.Flags().StringVar(&keyPath, "key", "", "Private key created by 'keygen' (required)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "File to write the signature to (default <bundle>.sig)")
+	_ = cmd.MarkFlagRequired("key")
+
+	return cmd
+}
+
+func runSign(bundlePath, keyPath, output string) error {
+	data, err := os.ReadFile(bundlePath)
+	if err != nil {
+		return fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	if output == "" {
+		output = bundlePath + signatureExtension
+	}
+
+	keyID, err := signFile(data, keyPath, output)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Signed %s with key %s, signature written to %s\n", bundlePath, keyID, output)
+	return nil
+}
+
+// signFile writes the signature of data made with the private key stored at
+// keyPath, and returns the ID of the key.
+func signFile(data []byte, keyPath, output string) (string, error) {
+	encodedKey, err := os.ReadFile(keyPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read private key: %w", err)
+	}
+
+	key, err := signing.ParsePrivateKey(string(encodedKey))
+	if err != nil {
+		return "", err
+	}
+
+	signature, err := signing.Sign(data, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign: %w", err)
+	}
+
+	if err := os.WriteFile(output, signature, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write signature: %w", err)
+	}
+
+	return signing.KeyID(key.Public().(ed25519.PublicKey)), nil
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.kirha.ai/mcp-installer/di"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+func NewCmdSync() *cobra.Command {
+	flags := &operationFlags{}
+	var source, profile string
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Make every client use the same Kirha server definition",
+		Long: `Reconcile the Kirha MCP server of every detected client with one definition: the
+one configured in a source client, or a profile from settings.json.
+
+The drift of each client is reported first. Clients without Kirha get it installed
+and clients that differ are updated, in a single transaction. With a profile and
+no --key, each client keeps its own API key.`,
+		Example: `  # Make every client match Claude Code
+  mcp-installer sync --source claudecode
+
+  # Show the drift against the default profile without changing anything
+  mcp-installer sync --profile default --dry-run
+
+  # Apply the "team" profile from settings.json with a given key
+  mcp-installer sync --profile team --key your-api-key`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSync(cmd, flags, source, profile)
+		},
+	}
+
+	cmd.Flags().StringVar(&source, "source", "", "Client whose Kirha server definition the others should match")
+	cmd.Flags().StringVar(&profile, "profile", "", "Profile from settings.json the clients should match")
+	cmd.Flags().StringVarP(&flags.apiKey, "key", "k", "", "API key to set on every client (optional)")
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "Only report the drift of each client")
+	cmd.Flags().BoolVarP(&flags.force, "force", "f", false, "Sync even if clients are running")
+	cmd.Flags().IntVar(&flags.parallel, "parallel", 4, "Maximum number of clients processed concurrently")
+	cmd.Flags().DurationVar(&flags.timeout, "timeout", 30*time.Second, "Timeout for each client operation")
+	addLockTimeoutFlag(cmd, flags)
+
+	return cmd
+}
+
+func runSync(cmd *cobra.Command, flags *operationFlags, source, profile string) error {
+	if (source == "") == (profile == "") {
+		return fmt.Errorf("exactly one of --source or --profile is required")
+	}
+
+	var sourceClient installer.ClientType
+	if source != "" {
+		clientType, err := validateClient(source)
+		if err != nil {
+			return describeOperationError(err, source)
+		}
+		sourceClient = clientType
+	}
+
+	app, err := di.ProvideInstallerApplication()
+	if err != nil {
+		return err
+	}
+
+	opts := installer.BatchOptions{
+		Concurrency: flags.parallel,
+		Timeout:     flags.timeout,
+		LockTimeout: flags.lockTimeout,
+	}
+	newConfig := func(dryRun bool) *installer.Config {
+		return &installer.Config{
+			ApiKey:      flags.apiKey,
+			Operation:   installer.OperationUpdate,
+			DryRun:      dryRun,
+			Force:       flags.force,
+			LockTimeout: flags.lockTimeout,
+		}
+	}
+
+	plan, err := app.Sync(cmd.Context(), newConfig(true), sourceClient, profile, opts)
+	if plan == nil {
+		return describeOperationError(err, source)
+	}
+
+	if len(plan.Clients) == 0 {
+		fmt.Println("No other client detected")
+		return nil
+	}
+
+	if printErr := printSyncResult(plan); printErr != nil {
+		return printErr
+	}
+	if err != nil {
+		return fmt.Errorf("sync is not possible, nothing was changed: %w", err)
+	}
+
+	pending := 0
+	for _, change := range plan.Clients {
+		if change.Status == installer.SyncMissing || change.Status == installer.SyncDrifted {
+			pending++
+		}
+	}
+	if flags.dryRun || pending == 0 {
+		if pending == 0 {
+			fmt.Println("\nEvery client is in sync")
+		}
+		return nil
+	}
+
+	fmt.Println()
+	result, err := app.Sync(cmd.Context(), newConfig(false), sourceClient, profile, opts)
+	if result != nil {
+		if printErr := printSyncResult(result); printErr != nil {
+			return printErr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("sync failed, all changes were rolled back: %w", err)
+	}
+
+	fmt.Printf("\nSynced %d clients, operation ID: %s\n", pending, result.OperationID)
+
+	return nil
+}
+
+func printSyncResult(result *installer.SyncResult) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLIENT\tDRIFT\tACTION\tDETAILS")
+	for _, change := range result.Clients {
+		action := "-"
+		switch {
+		case change.RolledBack:
+			action = "rolled back"
+		case change.Applied && change.Status == installer.SyncMissing:
+			action = "installed"
+		case change.Applied:
+			action = "updated"
+		case change.Err == nil && change.Status == installer.SyncMissing:
+			action = "install"
+		case change.Err == nil && change.Status == installer.SyncDrifted:
+			action = "update"
+		}
+
+		details := strings.Join(change.Differences, ", ")
+		if change.Err != nil {
+			details = describeOperationError(change.Err, string(change.Client)).Error()
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", clientLabel(change.Client, change.Scope, change.Project), change.Status, action, valueOrDash(details))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"go.kirha.ai/mcp-installer/di"
+	domainErrors "go.kirha.ai/mcp-installer/internal/core/domain/errors"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+func NewCmdUndo() *cobra.Command {
+	flags := &operationFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "undo [operation-id]",
+		Short: "Revert the changes made by an operation",
+		Long: `Revert every configuration file changed by an install, update, remove, restore
+or undo operation, restoring or deleting exactly the files it changed.
+
+Without an operation ID the most recent operation is undone. Operation IDs are
+printed with --verbose and listed by 'mcp-installer backups list'.
+
+If a file was changed again since the operation, undo refuses to run and lists
+the conflicting files. Use --force to overwrite those changes.`,
+		Example: `  # Undo the most recent operation
+  mcp-installer undo
+
+  # Undo a specific operation
+  mcp-installer undo op-20250101-120000-a1b2c3
+
+  # Show what would be undone
+  mcp-installer undo --dry-run`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var operationID string
+			if len(args) == 1 {
+				operationID = args[0]
+			}
+			return runUndo(cmd, operationID, flags)
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "Show what would be undone without making changes")
+	cmd.Flags().BoolVarP(&flags.force, "force", "f", false, "Undo even if files changed since or clients are running")
+	addLockTimeoutFlag(cmd, flags)
+
+	return cmd
+}
+
+func runUndo(cmd *cobra.Command, operationID string, flags *operationFlags) error {
+	app, err := di.ProvideInstallerApplication()
+	if err != nil {
+		return err
+	}
+
+	result, err := app.Undo(cmd.Context(), operationID, &installer.Config{
+		Operation:   installer.OperationUndo,
+		DryRun:      flags.dryRun,
+		Force:       flags.force,
+		LockTimeout: flags.lockTimeout,
+	})
+	if result != nil {
+		if printErr := printUndoResult(result); printErr != nil {
+			return printErr
+		}
+	}
+	if err != nil {
+		if errors.Is(err, domainErrors.ErrNothingToUndo) {
+			return err
+		}
+		return describeOperationError(err, string(undoneClient(result)))
+	}
+
+	if result.DryRun {
+		fmt.Printf("\nWould undo %s operation %s\n", result.Operation, result.OperationID)
+	} else {
+		fmt.Printf("\nUndid %s operation %s (undo with 'mcp-installer undo %s')\n", result.Operation, result.OperationID, result.UndoID)
+	}
+
+	return nil
+}
+
+// undoneClient is the client whose configuration the operation changed, the
+// first one when it changed several.
+func undoneClient(result *installer.UndoResult) installer.ClientType {
+	if result == nil || len(result.Changes) == 0 {
+		return ""
+	}
+	return result.Changes[0].Client
+}
+
+func printUndoResult(result *installer.UndoResult) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLIENT\tACTION\tSTATUS\tCONFIG PATH")
+	for _, change := range result.Changes {
+		action := "restore"
+		if change.Deleted {
+			action = "delete"
+		}
+		status := "unchanged since"
+		if change.Conflict {
+			status = "changed since"
+		}
+		if change.Scrubbed {
+			status += ", API keys redacted"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", change.Client, action, status, change.ConfigPath)
+	}
+	return w.Flush()
+}
@@ -6,14 +6,7 @@ import (
 )
 
 func NewCmdUpdate() *cobra.Command {
-	var (
-		client     string
-		apiKey     string
-		configPath string
-		dryRun     bool
-		verbose    bool
-		force      bool
-	)
+	flags := &operationFlags{}
 
 	cmd := &cobra.Command{
 		Use:   "update",
@@ -32,20 +25,25 @@ If the server doesn't exist, the command will fail with a suggestion to use 'ins
   mcp-installer update --client opencode --key your-new-api-key --verbose
 
   # Update for Droid (Factory AI)
-  mcp-installer update --client droid --key your-new-api-key`,
+  mcp-installer update --client droid --key your-new-api-key
+
+  # Update every client detected on this machine
+  mcp-installer update --all-detected --key your-new-api-key`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runOperation(cmd, installer.OperationUpdate, client, apiKey, configPath, dryRun, verbose, force)
+			return runOperation(cmd, installer.OperationUpdate, flags)
 		},
 	}
 
-	cmd.Flags().StringVarP(&client, "client", "c", "", "Client to update configuration for (claudecode, codex, opencode, gemini, droid) (required)")
-	cmd.Flags().StringVarP(&apiKey, "key", "k", "", "API key for Kirha MCP server (optional - preserves existing if not provided)")
-	cmd.Flags().StringVar(&configPath, "config-path", "", "Custom configuration file path (optional)")
-	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be changed without making changes")
-	cmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging")
-	cmd.Flags().BoolVarP(&force, "force", "f", false, "Force update even if the client is running")
+	cmd.Flags().StringVarP(&flags.client, "client", "c", "", "Comma-separated clients to update configuration for (claudecode, codex, opencode, gemini, droid)")
+	cmd.Flags().StringVarP(&flags.apiKey, "key", "k", "", "API key for Kirha MCP server (optional - preserves existing if not provided)")
+	cmd.Flags().StringVar(&flags.configPath, "config-path", "", "Custom configuration file path (optional)")
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "Show what would be changed without making changes")
+	cmd.Flags().BoolVar(&flags.verbose, "verbose", false, "Enable verbose logging")
+	cmd.Flags().BoolVarP(&flags.force, "force", "f", false, "Force update even if the client is running")
 
-	_ = cmd.MarkFlagRequired("client")
+	addScopeFlag(cmd, flags)
+	addMultiClientFlags(cmd, flags)
+	addLockTimeoutFlag(cmd, flags)
 
 	return cmd
 }
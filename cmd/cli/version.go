@@ -9,9 +9,10 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"go.kirha.ai/mcp-installer/pkg/version"
 )
 
-const Version = "0.1.0"
+const Version = version.Version
 
 type VersionInfo struct {
 	Version   string `json:"version"`
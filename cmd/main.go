@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/joho/godotenv"
 	"go.kirha.ai/mcp-installer/cmd/cli"
@@ -16,8 +19,15 @@ func main() {
 		slog.Warn("failed to load .env file", slog.String("err", err.Error()))
 	}
 
+	// Cancel the command context on interrupt instead of terminating, so that
+	// in-flight operations can roll back the files they already modified.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
 	rootCmd := cli.NewCmdRoot()
-	if err := rootCmd.Execute(); err != nil {
+	err = rootCmd.ExecuteContext(ctx)
+	stop()
+
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
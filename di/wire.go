@@ -5,13 +5,29 @@ package di
 
 import (
 	"github.com/google/wire"
+	"go.kirha.ai/mcp-installer/internal/adapters/audit"
+	"go.kirha.ai/mcp-installer/internal/adapters/backups"
+	"go.kirha.ai/mcp-installer/internal/adapters/bundles"
+	"go.kirha.ai/mcp-installer/internal/adapters/endpoints"
 	installerfactory "go.kirha.ai/mcp-installer/internal/adapters/factories/installer"
+	"go.kirha.ai/mcp-installer/internal/adapters/locks"
+	"go.kirha.ai/mcp-installer/internal/adapters/policy"
+	"go.kirha.ai/mcp-installer/internal/adapters/settings"
+	"go.kirha.ai/mcp-installer/internal/adapters/state"
 	"go.kirha.ai/mcp-installer/internal/applications/installer"
 )
 
 func ProvideInstallerApplication() (*installer.Application, error) {
 	wire.Build(
 		installerfactory.NewFactory,
+		locks.New,
+		backups.New,
+		settings.New,
+		audit.New,
+		state.New,
+		bundles.New,
+		policy.New,
+		endpoints.New,
 		installer.New,
 	)
 	return nil, nil
@@ -7,7 +7,15 @@
 package di
 
 import (
+	"go.kirha.ai/mcp-installer/internal/adapters/audit"
+	"go.kirha.ai/mcp-installer/internal/adapters/backups"
+	"go.kirha.ai/mcp-installer/internal/adapters/bundles"
+	"go.kirha.ai/mcp-installer/internal/adapters/endpoints"
 	"go.kirha.ai/mcp-installer/internal/adapters/factories/installer"
+	"go.kirha.ai/mcp-installer/internal/adapters/locks"
+	"go.kirha.ai/mcp-installer/internal/adapters/policy"
+	"go.kirha.ai/mcp-installer/internal/adapters/settings"
+	"go.kirha.ai/mcp-installer/internal/adapters/state"
 	"go.kirha.ai/mcp-installer/internal/applications/installer"
 )
 
@@ -15,6 +23,14 @@ import (
 
 func ProvideInstallerApplication() (*installer.Application, error) {
 	installerFactory := installerfactory.NewFactory()
-	application := installer.New(installerFactory)
+	locker := locks.New()
+	backupStore := backups.New()
+	settingsProvider := settings.New()
+	auditLog := audit.New()
+	stateStore := state.New()
+	bundleCodec := bundles.New()
+	policyProvider := policy.New()
+	endpointProber := endpoints.New()
+	application := installer.New(installerFactory, locker, backupStore, settingsProvider, auditLog, stateStore, bundleCodec, policyProvider, endpointProber)
 	return application, nil
 }
@@ -0,0 +1,133 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/internal/core/ports"
+	"go.kirha.ai/mcp-installer/pkg/appdirs"
+	"go.kirha.ai/mcp-installer/pkg/version"
+)
+
+const (
+	logFile = "audit.log"
+
+	fileMode = 0600
+	dirMode  = 0700
+
+	maxLineSize = 1024 * 1024
+)
+
+// Log appends records to audit.log in the installer state directory. Each
+// record is written with a single append so that concurrent runs never
+// interleave partial lines, and existing records are never rewritten.
+type Log struct{}
+
+func New() ports.AuditLog {
+	return &Log{}
+}
+
+func (l *Log) Append(ctx context.Context, record *installer.AuditRecord) error {
+	path, err := appdirs.StatePath(logFile)
+	if err != nil {
+		return err
+	}
+
+	if record.Time.IsZero() {
+		record.Time = time.Now().UTC()
+	}
+	if record.ToolVersion == "" {
+		record.ToolVersion = version.Version
+	}
+	if record.User == "" {
+		record.User = currentUser()
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), dirMode); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, fileMode)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return file.Sync()
+}
+
+func (l *Log) Read(ctx context.Context, filter installer.AuditFilter) ([]*installer.AuditRecord, error) {
+	path, err := appdirs.StatePath(logFile)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var records []*installer.AuditRecord
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		record := &installer.AuditRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			slog.WarnContext(ctx, "skipping malformed audit record",
+				slog.Int("line", lineNumber),
+				slog.String("error", err.Error()))
+			continue
+		}
+
+		if filter.Matches(record) {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	if filter.Limit > 0 && len(records) > filter.Limit {
+		records = records[len(records)-filter.Limit:]
+	}
+
+	return records, nil
+}
+
+func currentUser() string {
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		return current.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
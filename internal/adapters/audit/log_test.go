@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/pkg/appdirs"
+)
+
+func TestLog_AppendAndRead(t *testing.T) {
+	t.Setenv(appdirs.EnvStateDir, t.TempDir())
+
+	ctx := context.Background()
+	log := New()
+
+	records := []*installer.AuditRecord{
+		{OperationID: "op-1", Operation: installer.OperationInstall, Client: installer.ClientTypeCodex, Result: installer.AuditResultSuccess},
+		{OperationID: "op-2", Operation: installer.OperationInstall, Client: installer.ClientTypeGemini, Result: installer.AuditResultFailed},
+		{OperationID: "op-3", Operation: installer.OperationRemove, Client: installer.ClientTypeCodex, Result: installer.AuditResultSuccess},
+	}
+	for _, record := range records {
+		if err := log.Append(ctx, record); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	codex, err := log.Read(ctx, installer.AuditFilter{Client: installer.ClientTypeCodex})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(codex) != 2 || codex[0].OperationID != "op-1" || codex[1].OperationID != "op-3" {
+		t.Errorf("Read(client=codex) returned %d records, want op-1 and op-3", len(codex))
+	}
+	if codex[0].ToolVersion == "" || codex[0].Time.IsZero() {
+		t.Errorf("Append() did not fill in time and tool version")
+	}
+
+	latest, err := log.Read(ctx, installer.AuditFilter{Limit: 1})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(latest) != 1 || latest[0].OperationID != "op-3" {
+		t.Errorf("Read(limit=1) did not return the most recent record")
+	}
+}
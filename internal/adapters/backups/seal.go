@@ -0,0 +1,174 @@
+package backups
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.kirha.ai/mcp-installer/pkg/appdirs"
+)
+
+const (
+	keyFile = "backup.key"
+	keySize = 32
+
+	// legacyKeyFile is where the key was kept before, next to the blobs.
+	legacyKeyFile = "content.key"
+)
+
+// sealedMagic prefixes encrypted blobs. Blobs written before encryption was
+// introduced hold the plain configuration and are read as is.
+var sealedMagic = []byte("KMIB1\x00")
+
+// seal encrypts a configuration with AES-GCM so the API keys it contains are
+// not stored in plain text.
+func seal(root string, data []byte) ([]byte, error) {
+	aead, err := contentCipher(root)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := append([]byte{}, sealedMagic...)
+	sealed = append(sealed, nonce...)
+	return aead.Seal(sealed, nonce, data, nil), nil
+}
+
+func open(root string, data []byte) ([]byte, error) {
+	if !isSealed(data) {
+		return data, nil
+	}
+
+	aead, err := contentCipher(root)
+	if err != nil {
+		return nil, err
+	}
+
+	data = data[len(sealedMagic):]
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("sealed content is truncated")
+	}
+
+	return aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+}
+
+func isSealed(data []byte) bool {
+	return bytes.HasPrefix(data, sealedMagic)
+}
+
+// contentCipher loads the key blobs are encrypted with, creating it on first
+// use. It is kept in the config directory rather than with the blobs, so a
+// copy of the state directory alone does not expose the keys the backups
+// contain. Anyone who can read both directories, such as the owner's other
+// processes, can decrypt them.
+func contentCipher(root string) (cipher.AEAD, error) {
+	path, err := appdirs.ConfigPath(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := moveLegacyKey(filepath.Join(root, legacyKeyFile), path); err != nil {
+		return nil, fmt.Errorf("failed to move backup key: %w", err)
+	}
+
+	key, err := loadKey(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load backup key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// moveLegacyKey moves a key kept next to the blobs by earlier versions to
+// path, so the blobs it encrypted stay readable. A key already at path wins.
+func moveLegacyKey(legacy, path string) error {
+	key, err := os.ReadFile(legacy)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := writeKey(path, key); err != nil && !os.IsExist(err) {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	return os.Remove(legacy)
+}
+
+func loadKey(path string) ([]byte, error) {
+	key, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		key, err = createKey(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) != keySize {
+		return nil, fmt.Errorf("%s is not a valid key", path)
+	}
+
+	return key, nil
+}
+
+func createKey(path string) ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	err := writeKey(path, key)
+	if os.IsExist(err) {
+		// Another run created it first.
+		return os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// writeKey creates path holding key, failing if it already exists.
+func writeKey(path string, key []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), dirMode); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fileMode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(key); err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	return nil
+}
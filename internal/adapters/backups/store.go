@@ -0,0 +1,624 @@
+package backups
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/internal/core/ports"
+	"go.kirha.ai/mcp-installer/pkg/appdirs"
+	"go.kirha.ai/mcp-installer/pkg/atomicfile"
+	"go.kirha.ai/mcp-installer/pkg/filelock"
+	"go.kirha.ai/mcp-installer/pkg/version"
+)
+
+const (
+	backupsDir = "backups"
+	blobsDir   = "blobs"
+	entriesDir = "entries"
+
+	lockFile = "store.lock"
+
+	entryExt = ".json"
+	fileMode = 0600
+	dirMode  = 0700
+
+	lockTimeout = 30 * time.Second
+)
+
+// entry is the on-disk metadata of one backup.
+type entry struct {
+	ID          string     `json:"id"`
+	OperationID string     `json:"operation_id,omitempty"`
+	Client      string     `json:"client"`
+	Scope       string     `json:"scope,omitempty"`
+	Project     string     `json:"project,omitempty"`
+	Operation   string     `json:"operation"`
+	ConfigPath  string     `json:"config_path"`
+	Absent      bool       `json:"absent,omitempty"`
+	Hash        string     `json:"hash,omitempty"`
+	Size        int64      `json:"size"`
+	ToolVersion string     `json:"tool_version"`
+	CreatedAt   time.Time  `json:"created_at"`
+	After       *fileState `json:"after,omitempty"`
+	Scrubbed    bool       `json:"scrubbed,omitempty"`
+}
+
+type fileState struct {
+	Exists bool   `json:"exists"`
+	Hash   string `json:"hash,omitempty"`
+}
+
+// Store keeps backups in the installer state directory. File contents are
+// stored once per SHA-256 under blobs/, and every backup has its own metadata
+// file under entries/ so concurrent runs never rewrite a shared index. Writing
+// content and collecting unreferenced content happen under a store-wide lock,
+// so a blob is never collected between being written and being referenced.
+// Blobs are encrypted with a key kept in the config directory, see seal.
+type Store struct {
+	mu sync.Mutex
+}
+
+func New() ports.BackupStore {
+	return &Store{}
+}
+
+func (s *Store) Create(ctx context.Context, backup *installer.Backup) (*installer.Backup, error) {
+	root, err := s.root()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(backup.ConfigPath)
+	existed := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		if os.IsPermission(err) {
+			return nil, errors.ErrPermissionDenied
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrConfigBackupFailed, err)
+	}
+
+	unlock, err := s.lock(ctx, root)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrConfigBackupFailed, err)
+	}
+	defer unlock()
+
+	var hash string
+	if existed {
+		hash = hashContent(data)
+		if err := s.writeBlob(root, hash, data); err != nil {
+			slog.ErrorContext(ctx, "failed to store backup content", slog.String("error", err.Error()))
+			return nil, errors.ErrConfigBackupFailed
+		}
+	}
+
+	id, err := newID(time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrConfigBackupFailed, err)
+	}
+
+	created := &installer.Backup{
+		ID:          id,
+		OperationID: backup.OperationID,
+		Client:      backup.Client,
+		Scope:       backup.Scope,
+		Project:     backup.Project,
+		Operation:   backup.Operation,
+		ConfigPath:  backup.ConfigPath,
+		Existed:     existed,
+		Hash:        hash,
+		Size:        int64(len(data)),
+		ToolVersion: version.Version,
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	if err := s.writeEntry(root, created); err != nil {
+		slog.ErrorContext(ctx, "failed to store backup metadata", slog.String("error", err.Error()))
+		return nil, errors.ErrConfigBackupFailed
+	}
+
+	slog.InfoContext(ctx, "created configuration backup",
+		slog.String("id", created.ID),
+		slog.String("operation_id", created.OperationID),
+		slog.String("original", created.ConfigPath),
+		slog.Bool("existed", created.Existed))
+
+	return created, nil
+}
+
+func (s *Store) RecordAfter(ctx context.Context, backup *installer.Backup) (*installer.Backup, error) {
+	root, err := s.root()
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := s.FileState(ctx, backup.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	completed := *backup
+	completed.After = &state
+
+	if err := s.writeEntry(root, &completed); err != nil {
+		slog.ErrorContext(ctx, "failed to update backup metadata", slog.String("error", err.Error()))
+		return nil, errors.ErrConfigBackupFailed
+	}
+
+	return &completed, nil
+}
+
+func (s *Store) FileState(ctx context.Context, path string) (installer.FileState, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return installer.FileState{}, nil
+	}
+	if err != nil {
+		if os.IsPermission(err) {
+			return installer.FileState{}, errors.ErrPermissionDenied
+		}
+		return installer.FileState{}, errors.ErrConfigReadFailed
+	}
+
+	return installer.FileState{Exists: true, Hash: hashContent(data)}, nil
+}
+
+func (s *Store) List(ctx context.Context, filter installer.BackupFilter) ([]*installer.Backup, error) {
+	root, err := s.root()
+	if err != nil {
+		return nil, err
+	}
+
+	dirEntries, err := os.ReadDir(filepath.Join(root, entriesDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var backups []*installer.Backup
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if dirEntry.IsDir() || !strings.HasSuffix(name, entryExt) {
+			continue
+		}
+
+		backup, err := s.readEntry(root, strings.TrimSuffix(name, entryExt))
+		if err != nil {
+			slog.WarnContext(ctx, "skipping unreadable backup entry",
+				slog.String("entry", name),
+				slog.String("error", err.Error()))
+			continue
+		}
+
+		if filter.Matches(backup) {
+			backups = append(backups, backup)
+		}
+	}
+
+	sortNewestFirst(backups)
+
+	return backups, nil
+}
+
+func (s *Store) Get(ctx context.Context, id string) (*installer.Backup, error) {
+	root, err := s.root()
+	if err != nil {
+		return nil, err
+	}
+
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.Contains(id, "..") {
+		return nil, fmt.Errorf("%w: %s", errors.ErrBackupNotFound, id)
+	}
+
+	backup, err := s.readEntry(root, id)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", errors.ErrBackupNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return backup, nil
+}
+
+func (s *Store) Content(ctx context.Context, backup *installer.Backup) ([]byte, error) {
+	if !backup.Existed {
+		return nil, nil
+	}
+
+	root, err := s.root()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(blobPath(root, backup.Hash))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: content of %s is missing", errors.ErrBackupNotFound, backup.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup %s: %w", backup.ID, err)
+	}
+
+	data, err = open(root, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", errors.ErrBackupCorrupted, backup.ID, err)
+	}
+
+	if hashContent(data) != backup.Hash {
+		return nil, fmt.Errorf("%w: %s", errors.ErrBackupCorrupted, backup.ID)
+	}
+
+	return data, nil
+}
+
+func (s *Store) Restore(ctx context.Context, backup *installer.Backup) error {
+	if !backup.Existed {
+		if err := os.Remove(backup.ConfigPath); err != nil && !os.IsNotExist(err) {
+			if os.IsPermission(err) {
+				return errors.ErrPermissionDenied
+			}
+			slog.ErrorContext(ctx, "failed to remove file",
+				slog.String("error", err.Error()),
+				slog.String("target", backup.ConfigPath))
+			return errors.ErrConfigRestoreFailed
+		}
+
+		slog.InfoContext(ctx, "removed configuration that did not exist before",
+			slog.String("id", backup.ID),
+			slog.String("target", backup.ConfigPath))
+		return nil
+	}
+
+	data, err := s.Content(ctx, backup)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(backup.ConfigPath), 0755); err != nil {
+		slog.ErrorContext(ctx, "failed to create directory", slog.String("error", err.Error()))
+		return errors.ErrConfigRestoreFailed
+	}
+
+	if err := atomicfile.WriteFile(backup.ConfigPath, data, fileMode); err != nil {
+		if stderrors.Is(err, os.ErrPermission) {
+			return errors.ErrPermissionDenied
+		}
+		slog.ErrorContext(ctx, "failed to restore file",
+			slog.String("error", err.Error()),
+			slog.String("target", backup.ConfigPath))
+		return errors.ErrConfigRestoreFailed
+	}
+
+	slog.InfoContext(ctx, "restored configuration from backup",
+		slog.String("id", backup.ID),
+		slog.String("target", backup.ConfigPath))
+
+	return nil
+}
+
+func (s *Store) Rewrite(ctx context.Context, backup *installer.Backup, content []byte) (*installer.Backup, error) {
+	root, err := s.root()
+	if err != nil {
+		return nil, err
+	}
+
+	if !backup.Existed {
+		return nil, fmt.Errorf("backup %s has no content to rewrite", backup.ID)
+	}
+
+	unlock, err := s.lock(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	rewritten := *backup
+	rewritten.Hash = hashContent(content)
+	rewritten.Size = int64(len(content))
+
+	if err := s.writeBlob(root, rewritten.Hash, content); err != nil {
+		return nil, fmt.Errorf("failed to store backup content: %w", err)
+	}
+	if err := s.writeEntry(root, &rewritten); err != nil {
+		return nil, fmt.Errorf("failed to update backup metadata: %w", err)
+	}
+
+	if err := s.collectBlobs(ctx, root); err != nil {
+		slog.WarnContext(ctx, "failed to remove unreferenced backup content", slog.String("error", err.Error()))
+	}
+
+	return &rewritten, nil
+}
+
+func (s *Store) Delete(ctx context.Context, backup *installer.Backup) error {
+	root, err := s.root()
+	if err != nil {
+		return err
+	}
+
+	unlock, err := s.lock(ctx, root)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := os.Remove(entryPath(root, backup.ID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete backup %s: %w", backup.ID, err)
+	}
+
+	if err := s.collectBlobs(ctx, root); err != nil {
+		slog.WarnContext(ctx, "failed to remove unreferenced backup content", slog.String("error", err.Error()))
+	}
+
+	return nil
+}
+
+func (s *Store) Prune(ctx context.Context, policy installer.RetentionPolicy, now time.Time, dryRun bool) ([]*installer.Backup, error) {
+	root, err := s.root()
+	if err != nil {
+		return nil, err
+	}
+
+	if !dryRun {
+		unlock, err := s.lock(ctx, root)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
+	backups, err := s.List(ctx, installer.BackupFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	expired := selectExpired(backups, policy, now)
+	if dryRun || len(expired) == 0 {
+		return expired, nil
+	}
+
+	removed := make([]*installer.Backup, 0, len(expired))
+	for _, backup := range expired {
+		if err := os.Remove(entryPath(root, backup.ID)); err != nil && !os.IsNotExist(err) {
+			slog.WarnContext(ctx, "failed to remove backup entry",
+				slog.String("id", backup.ID),
+				slog.String("error", err.Error()))
+			continue
+		}
+		removed = append(removed, backup)
+	}
+
+	if err := s.collectBlobs(ctx, root); err != nil {
+		slog.WarnContext(ctx, "failed to remove unreferenced backup content", slog.String("error", err.Error()))
+	}
+
+	slog.InfoContext(ctx, "pruned backups", slog.Int("removed", len(removed)))
+
+	return removed, nil
+}
+
+// collectBlobs deletes stored contents that no backup entry refers to anymore.
+// The caller holds the store lock.
+func (s *Store) collectBlobs(ctx context.Context, root string) error {
+	backups, err := s.List(ctx, installer.BackupFilter{})
+	if err != nil {
+		return err
+	}
+
+	referenced := make(map[string]bool, len(backups))
+	for _, backup := range backups {
+		referenced[backup.Hash] = true
+	}
+
+	blobs, err := os.ReadDir(filepath.Join(root, blobsDir))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, blob := range blobs {
+		if blob.IsDir() || referenced[blob.Name()] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(root, blobsDir, blob.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// selectExpired applies policy to each configuration file's backups, newest
+// first. The newest backup of every file is always kept.
+func selectExpired(backups []*installer.Backup, policy installer.RetentionPolicy, now time.Time) []*installer.Backup {
+	byPath := make(map[string][]*installer.Backup)
+	var paths []string
+	for _, backup := range backups {
+		if _, ok := byPath[backup.ConfigPath]; !ok {
+			paths = append(paths, backup.ConfigPath)
+		}
+		byPath[backup.ConfigPath] = append(byPath[backup.ConfigPath], backup)
+	}
+
+	var expired []*installer.Backup
+	for _, path := range paths {
+		group := byPath[path]
+		sortNewestFirst(group)
+
+		for i, backup := range group {
+			if i == 0 {
+				continue
+			}
+			tooMany := policy.KeepLast > 0 && i >= policy.KeepLast
+			tooOld := policy.MaxAge > 0 && now.Sub(backup.CreatedAt) > policy.MaxAge
+			if tooMany || tooOld {
+				expired = append(expired, backup)
+			}
+		}
+	}
+
+	sortNewestFirst(expired)
+
+	return expired
+}
+
+func (s *Store) root() (string, error) {
+	return appdirs.StatePath(backupsDir)
+}
+
+// lock takes the store lock, both within the process and across processes.
+func (s *Store) lock(ctx context.Context, root string) (func(), error) {
+	s.mu.Lock()
+
+	if err := os.MkdirAll(root, dirMode); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+
+	lock, err := filelock.Acquire(ctx, filepath.Join(root, lockFile), lockTimeout)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("failed to lock backup store: %w", err)
+	}
+
+	return func() {
+		if err := lock.Unlock(); err != nil {
+			slog.WarnContext(ctx, "failed to release backup store lock", slog.String("error", err.Error()))
+		}
+		s.mu.Unlock()
+	}, nil
+}
+
+func (s *Store) writeBlob(root, hash string, data []byte) error {
+	path := blobPath(root, hash)
+	if existing, err := os.ReadFile(path); err == nil && isSealed(existing) {
+		return nil
+	}
+
+	sealed, err := seal(root, data)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), dirMode); err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(path, sealed, fileMode)
+}
+
+func (s *Store) writeEntry(root string, backup *installer.Backup) error {
+	e := entry{
+		ID:          backup.ID,
+		OperationID: backup.OperationID,
+		Client:      string(backup.Client),
+		Scope:       string(backup.Scope),
+		Project:     backup.Project,
+		Operation:   string(backup.Operation),
+		ConfigPath:  backup.ConfigPath,
+		Absent:      !backup.Existed,
+		Hash:        backup.Hash,
+		Size:        backup.Size,
+		ToolVersion: backup.ToolVersion,
+		CreatedAt:   backup.CreatedAt,
+		Scrubbed:    backup.Scrubbed,
+	}
+	if backup.After != nil {
+		e.After = &fileState{Exists: backup.After.Exists, Hash: backup.After.Hash}
+	}
+
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := entryPath(root, backup.ID)
+	if err := os.MkdirAll(filepath.Dir(path), dirMode); err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(path, data, fileMode)
+}
+
+func (s *Store) readEntry(root, id string) (*installer.Backup, error) {
+	data, err := os.ReadFile(entryPath(root, id))
+	if err != nil {
+		return nil, err
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+
+	backup := &installer.Backup{
+		ID:          e.ID,
+		OperationID: e.OperationID,
+		Client:      installer.ClientType(e.Client),
+		Scope:       installer.Scope(e.Scope),
+		Project:     e.Project,
+		Operation:   installer.OperationType(e.Operation),
+		ConfigPath:  e.ConfigPath,
+		Existed:     !e.Absent,
+		Hash:        e.Hash,
+		Size:        e.Size,
+		ToolVersion: e.ToolVersion,
+		CreatedAt:   e.CreatedAt,
+		Scrubbed:    e.Scrubbed,
+	}
+	if e.After != nil {
+		backup.After = &installer.FileState{Exists: e.After.Exists, Hash: e.After.Hash}
+	}
+
+	return backup, nil
+}
+
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func blobPath(root, hash string) string {
+	return filepath.Join(root, blobsDir, hash)
+}
+
+func entryPath(root, id string) string {
+	return filepath.Join(root, entriesDir, id+entryExt)
+}
+
+// newID returns an identifier that sorts by creation time and stays unique
+// when several backups are taken within the same second.
+func newID(now time.Time) (string, error) {
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return now.UTC().Format("20060102-150405") + "-" + hex.EncodeToString(suffix), nil
+}
+
+func sortNewestFirst(backups []*installer.Backup) {
+	sort.SliceStable(backups, func(i, j int) bool {
+		if !backups[i].CreatedAt.Equal(backups[j].CreatedAt) {
+			return backups[i].CreatedAt.After(backups[j].CreatedAt)
+		}
+		return backups[i].ID > backups[j].ID
+	})
+}
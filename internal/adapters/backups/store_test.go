@@ -0,0 +1,229 @@
+package backups
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/pkg/appdirs"
+)
+
+func TestStore_CreateDeduplicatesAndRestores(t *testing.T) {
+	stateDir := t.TempDir()
+	t.Setenv(appdirs.EnvStateDir, stateDir)
+	t.Setenv(appdirs.EnvConfigDir, t.TempDir())
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"a":1}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	store := New()
+
+	first, err := store.Create(ctx, &installer.Backup{Client: installer.ClientTypeOpencode, ConfigPath: configPath})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	second, err := store.Create(ctx, &installer.Backup{Client: installer.ClientTypeClaudecode, Scope: installer.ScopeLocal, Project: "/work/app", ConfigPath: configPath})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	stored, err := store.Get(ctx, second.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if stored.Scope != installer.ScopeLocal || stored.Project != "/work/app" {
+		t.Errorf("Get() scope = %q, project = %q, want local and /work/app", stored.Scope, stored.Project)
+	}
+
+	if first.ID == second.ID {
+		t.Errorf("Create() returned duplicate ID %s", first.ID)
+	}
+	if first.Hash != second.Hash {
+		t.Errorf("Create() hashes differ for identical content")
+	}
+
+	blobs, err := os.ReadDir(filepath.Join(stateDir, backupsDir, blobsDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blobs) != 1 {
+		t.Errorf("stored %d blobs, want 1", len(blobs))
+	}
+
+	if err := os.WriteFile(configPath, []byte(`{"a":2}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Restore(ctx, first); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"a":1}` {
+		t.Errorf("restored content = %s, want {\"a\":1}", data)
+	}
+}
+
+func TestStore_PruneKeepsContentOfConcurrentCreate(t *testing.T) {
+	t.Setenv(appdirs.EnvStateDir, t.TempDir())
+	t.Setenv(appdirs.EnvConfigDir, t.TempDir())
+
+	ctx := context.Background()
+	store := New()
+	dir := t.TempDir()
+
+	busy := filepath.Join(dir, "busy.json")
+	if err := os.WriteFile(busy, []byte(`{}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	created := make([]*installer.Backup, 50)
+	errs := make([]error, len(created))
+	for i := range created {
+		configPath := filepath.Join(dir, fmt.Sprintf("config-%d.json", i))
+		if err := os.WriteFile(configPath, []byte(fmt.Sprintf(`{"n":%d}`, i)), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			created[i], errs[i] = store.Create(ctx, &installer.Backup{Client: installer.ClientTypeOpencode, ConfigPath: configPath})
+		}(i)
+		go func() {
+			defer wg.Done()
+			// A second backup of busy makes the prune expire one and collect
+			// content.
+			_, _ = store.Create(ctx, &installer.Backup{Client: installer.ClientTypeCodex, ConfigPath: busy})
+			_, _ = store.Prune(ctx, installer.RetentionPolicy{KeepLast: 1}, time.Now(), false)
+		}()
+	}
+	wg.Wait()
+
+	for i, backup := range created {
+		if errs[i] != nil {
+			t.Fatalf("Create() error = %v", errs[i])
+		}
+		if _, err := store.Content(ctx, backup); err != nil {
+			t.Errorf("Content(%s) error = %v", backup.ID, err)
+		}
+	}
+}
+
+func TestSelectExpired(t *testing.T) {
+	now := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	backup := func(id, path string, age time.Duration) *installer.Backup {
+		return &installer.Backup{ID: id, ConfigPath: path, CreatedAt: now.Add(-age)}
+	}
+
+	backups := []*installer.Backup{
+		backup("a1", "/a", time.Hour),
+		backup("a2", "/a", 2*time.Hour),
+		backup("a3", "/a", 3*time.Hour),
+		backup("b1", "/b", 60*24*time.Hour),
+	}
+
+	expired := selectExpired(backups, installer.RetentionPolicy{KeepLast: 2, MaxAge: 30 * 24 * time.Hour}, now)
+
+	if len(expired) != 1 || expired[0].ID != "a3" {
+		ids := make([]string, 0, len(expired))
+		for _, b := range expired {
+			ids = append(ids, b.ID)
+		}
+		t.Errorf("selectExpired() = %v, want [a3]", ids)
+	}
+}
+
+func TestStore_EncryptsContent(t *testing.T) {
+	stateDir := t.TempDir()
+	configDir := t.TempDir()
+	t.Setenv(appdirs.EnvStateDir, stateDir)
+	t.Setenv(appdirs.EnvConfigDir, configDir)
+
+	content := []byte(`{"headers":{"Authorization":"Bearer kirha-secret"}}`)
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(configPath, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	store := New()
+
+	backup, err := store.Create(ctx, &installer.Backup{Client: installer.ClientTypeOpencode, ConfigPath: configPath})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	blob, err := os.ReadFile(blobPath(filepath.Join(stateDir, backupsDir), backup.Hash))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(blob, []byte("kirha-secret")) {
+		t.Errorf("stored blob contains the API key in plain text")
+	}
+	if _, err := os.Stat(filepath.Join(configDir, keyFile)); err != nil {
+		t.Errorf("backup key not in the config directory: %v", err)
+	}
+
+	got, err := store.Content(ctx, backup)
+	if err != nil {
+		t.Fatalf("Content() error = %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("Content() = %s, want %s", got, content)
+	}
+}
+
+func TestStore_MovesLegacyKey(t *testing.T) {
+	stateDir := t.TempDir()
+	configDir := t.TempDir()
+	t.Setenv(appdirs.EnvStateDir, stateDir)
+	t.Setenv(appdirs.EnvConfigDir, configDir)
+
+	content := []byte(`{"a":1}`)
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(configPath, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	store := New()
+
+	backup, err := store.Create(ctx, &installer.Backup{Client: installer.ClientTypeOpencode, ConfigPath: configPath})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// Put the key back where earlier versions kept it.
+	root := filepath.Join(stateDir, backupsDir)
+	if err := os.Rename(filepath.Join(configDir, keyFile), filepath.Join(root, legacyKeyFile)); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.Content(ctx, backup)
+	if err != nil {
+		t.Fatalf("Content() error = %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("Content() = %s, want %s", got, content)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, legacyKeyFile)); !os.IsNotExist(err) {
+		t.Errorf("legacy key still next to the blobs: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(configDir, keyFile)); err != nil {
+		t.Errorf("backup key not moved to the config directory: %v", err)
+	}
+}
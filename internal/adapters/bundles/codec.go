@@ -0,0 +1,154 @@
+package bundles
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/internal/core/ports"
+	"gopkg.in/yaml.v3"
+)
+
+// fileBundle is the on-disk form of a bundle, identical in JSON and YAML.
+type fileBundle struct {
+	Version      int               `json:"version" yaml:"version"`
+	Source       string            `json:"source,omitempty" yaml:"source,omitempty"`
+	CreatedAt    time.Time         `json:"created_at" yaml:"created_at"`
+	Placeholders []filePlaceholder `json:"placeholders,omitempty" yaml:"placeholders,omitempty"`
+	Servers      []fileServer      `json:"servers" yaml:"servers"`
+}
+
+type filePlaceholder struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+type fileServer struct {
+	Name      string            `json:"name" yaml:"name"`
+	Transport string            `json:"transport" yaml:"transport"`
+	URL       string            `json:"url,omitempty" yaml:"url,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Command   string            `json:"command,omitempty" yaml:"command,omitempty"`
+	Args      []string          `json:"args,omitempty" yaml:"args,omitempty"`
+	Env       map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+	Disabled  bool              `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+}
+
+// Codec reads and writes bundles as JSON or YAML.
+type Codec struct{}
+
+func New() ports.BundleCodec {
+	return &Codec{}
+}
+
+func (c *Codec) Encode(bundle *installer.Bundle, format installer.BundleFormat) ([]byte, error) {
+	file := fileBundle{
+		Version:   bundle.Version,
+		Source:    string(bundle.Source),
+		CreatedAt: bundle.CreatedAt,
+		Servers:   make([]fileServer, 0, len(bundle.Servers)),
+	}
+	for _, placeholder := range bundle.Placeholders {
+		file.Placeholders = append(file.Placeholders, filePlaceholder(placeholder))
+	}
+	for _, server := range bundle.Servers {
+		file.Servers = append(file.Servers, fileServer{
+			Name:      server.Name,
+			Transport: server.Type,
+			URL:       server.URL,
+			Headers:   server.Headers,
+			Command:   server.Command,
+			Args:      server.Args,
+			Env:       server.Env,
+			Disabled:  server.Disabled,
+		})
+	}
+
+	switch format {
+	case installer.BundleFormatJSON:
+		data, err := json.MarshalIndent(file, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case installer.BundleFormatYAML:
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(file); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown format %q", errors.ErrBundleInvalid, format)
+	}
+}
+
+// Decode parses JSON and YAML alike, as YAML is a superset of JSON.
+func (c *Codec) Decode(data []byte) (*installer.Bundle, error) {
+	var file fileBundle
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrBundleInvalid, err)
+	}
+
+	if file.Version == 0 {
+		return nil, fmt.Errorf("%w: missing version", errors.ErrBundleInvalid)
+	}
+	if file.Version > installer.BundleVersion {
+		return nil, fmt.Errorf("%w: %d, this installer reads up to version %d", errors.ErrBundleVersion, file.Version, installer.BundleVersion)
+	}
+
+	bundle := &installer.Bundle{
+		Version:   file.Version,
+		Source:    installer.ClientType(file.Source),
+		CreatedAt: file.CreatedAt,
+	}
+	for _, placeholder := range file.Placeholders {
+		bundle.Placeholders = append(bundle.Placeholders, installer.Placeholder(placeholder))
+	}
+
+	seen := make(map[string]bool)
+	for i, server := range file.Servers {
+		if server.Name == "" {
+			return nil, fmt.Errorf("%w: servers[%d] has no name", errors.ErrBundleInvalid, i)
+		}
+		if seen[server.Name] {
+			return nil, fmt.Errorf("%w: server %s is listed twice", errors.ErrBundleInvalid, server.Name)
+		}
+		seen[server.Name] = true
+
+		switch server.Transport {
+		case installer.TransportStdio:
+			if server.Command == "" {
+				return nil, fmt.Errorf("%w: server %s has no command", errors.ErrBundleInvalid, server.Name)
+			}
+		case installer.TransportHTTP, installer.TransportSSE:
+			if server.URL == "" {
+				return nil, fmt.Errorf("%w: server %s has no url", errors.ErrBundleInvalid, server.Name)
+			}
+		default:
+			return nil, fmt.Errorf("%w: server %s has unknown transport %q", errors.ErrBundleInvalid, server.Name, server.Transport)
+		}
+
+		bundle.Servers = append(bundle.Servers, &installer.McpServer{
+			Name:     server.Name,
+			Type:     server.Transport,
+			URL:      server.URL,
+			Headers:  server.Headers,
+			Command:  server.Command,
+			Args:     server.Args,
+			Env:      server.Env,
+			Disabled: server.Disabled,
+		})
+	}
+
+	return bundle, nil
+}
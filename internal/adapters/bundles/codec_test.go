@@ -0,0 +1,64 @@
+package bundles
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	domainErrors "go.kirha.ai/mcp-installer/internal/core/domain/errors"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+func TestCodec_RoundTrip(t *testing.T) {
+	servers := []*installer.McpServer{
+		installer.NewKirhaRemoteMcpServer("sk-test-1234567890"),
+		{
+			Name:    "github",
+			Type:    installer.TransportStdio,
+			Command: "npx",
+			Args:    []string{"-y", "@modelcontextprotocol/server-github"},
+			Env:     map[string]string{"GITHUB_TOKEN": "ghp_secret", "LOG_LEVEL": "info"},
+		},
+	}
+	bundle := installer.NewBundle(installer.ClientTypeClaudecode, servers, time.Now())
+
+	for _, format := range []installer.BundleFormat{installer.BundleFormatJSON, installer.BundleFormatYAML} {
+		t.Run(string(format), func(t *testing.T) {
+			codec := New()
+			data, err := codec.Encode(bundle, format)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if strings.Contains(string(data), "sk-test") || strings.Contains(string(data), "ghp_secret") {
+				t.Fatalf("Encode() leaked a secret:\n%s", data)
+			}
+
+			decoded, err := codec.Decode(data)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			resolved, missing := decoded.Resolve(map[string]string{
+				"KIRHA_AUTHORIZATION": "new-key",
+				"GITHUB_TOKEN":        "ghp_other",
+			})
+			if len(missing) != 0 {
+				t.Fatalf("Resolve() missing = %v", missing)
+			}
+			if resolved[0].ApiKey() != "new-key" {
+				t.Errorf("kirha key = %q, want new-key", resolved[0].ApiKey())
+			}
+			if resolved[1].Env["GITHUB_TOKEN"] != "ghp_other" || resolved[1].Env["LOG_LEVEL"] != "info" {
+				t.Errorf("github env = %v", resolved[1].Env)
+			}
+		})
+	}
+}
+
+func TestCodec_DecodeRejectsNewerVersion(t *testing.T) {
+	_, err := New().Decode([]byte("version: 2\nservers: []\n"))
+	if !errors.Is(err, domainErrors.ErrBundleVersion) {
+		t.Errorf("Decode() error = %v, want ErrBundleVersion", err)
+	}
+}
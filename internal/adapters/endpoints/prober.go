@@ -0,0 +1,83 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/internal/core/ports"
+	"go.kirha.ai/mcp-installer/pkg/version"
+)
+
+const (
+	probeTimeout    = 10 * time.Second
+	protocolVersion = "2025-03-26"
+)
+
+// Prober probes MCP servers over HTTP.
+type Prober struct {
+	client *http.Client
+}
+
+func New() ports.EndpointProber {
+	return &Prober{client: &http.Client{Timeout: probeTimeout}}
+}
+
+func (p *Prober) Probe(ctx context.Context, server *installer.McpServer) (int, error) {
+	var req *http.Request
+	var err error
+
+	if server.Type == installer.TransportSSE {
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Accept", "text/event-stream")
+	} else {
+		body, err := json.Marshal(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"method":  "initialize",
+			"params": map[string]any{
+				"protocolVersion": protocolVersion,
+				"capabilities":    map[string]any{},
+				"clientInfo": map[string]string{
+					"name":    "mcp-installer-doctor",
+					"version": version.Version,
+				},
+			},
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, server.URL, bytes.NewReader(body))
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+	}
+
+	for name, value := range server.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reach %s: %w", server.URL, err)
+	}
+	defer resp.Body.Close()
+
+	// An SSE stream stays open, so only the status line is of interest.
+	if server.Type != installer.TransportSSE {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	}
+
+	return resp.StatusCode, nil
+}
@@ -48,3 +48,13 @@ func (f *Factory) GetInstaller(ctx context.Context, clientType installer.ClientT
 		return nil, errors.ErrClientNotSupported
 	}
 }
+
+func (f *Factory) GetSupportedClients() []installer.ClientType {
+	return []installer.ClientType{
+		installer.ClientTypeClaudecode,
+		installer.ClientTypeCodex,
+		installer.ClientTypeOpencode,
+		installer.ClientTypeGemini,
+		installer.ClientTypeDroid,
+	}
+}
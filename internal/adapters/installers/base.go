@@ -1,17 +1,17 @@
 package installers
 
 import (
-	"context"
-	"encoding/json"
+	stderrors "errors"
 	"fmt"
-	"io"
 	"log/slog"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
-	"time"
+	"sync"
 
 	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+	"go.kirha.ai/mcp-installer/pkg/atomicfile"
 )
 
 const (
@@ -22,12 +22,18 @@ const (
 	WindowsRoamingDir  = "Roaming"
 	LinuxConfigDir     = ".config"
 
+	// SecretFileMode is used for newly created files, since client configurations
+	// hold API keys
+	SecretFileMode = 0600
+
 	// Environment variables
 	EnvAppData       = "APPDATA"
 	EnvXDGConfigHome = "XDG_CONFIG_HOME"
 )
 
 type BaseInstaller struct {
+	mu      sync.Mutex
+	written *writtenFile
 }
 
 func NewBaseInstaller() *BaseInstaller {
@@ -50,19 +56,39 @@ func (b *BaseInstaller) ReadFile(path string) ([]byte, error) {
 }
 
 func (b *BaseInstaller) WriteFile(path string, content []byte) error {
+	if err := b.ensureDir(path); err != nil {
+		return err
+	}
+
+	if err := atomicfile.WriteFile(path, content, SecretFileMode); err != nil {
+		if stderrors.Is(err, os.ErrPermission) {
+			return errors.ErrPermissionDenied
+		}
+		slog.Error("failed to write file", slog.String("error", err.Error()), slog.String("path", path))
+		return errors.ErrConfigWriteFailed
+	}
+	return nil
+}
+
+func (b *BaseInstaller) ensureDir(path string) error {
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		slog.Error("failed to create directory", slog.String("error", err.Error()), slog.String("dir", dir))
 		return errors.ErrConfigWriteFailed
 	}
+	return nil
+}
 
-	if err := os.WriteFile(path, content, 0644); err != nil {
+func (b *BaseInstaller) RemoveFile(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
 		if os.IsPermission(err) {
 			return errors.ErrPermissionDenied
 		}
-		slog.Error("failed to write file", slog.String("error", err.Error()), slog.String("path", path))
-		return errors.ErrConfigWriteFailed
+		slog.Error("failed to remove file", slog.String("error", err.Error()), slog.String("path", path))
+		return errors.ErrConfigRestoreFailed
 	}
+
+	slog.Info("removed configuration file", slog.String("path", path))
 	return nil
 }
 
@@ -71,55 +97,12 @@ func (b *BaseInstaller) FileExists(path string) bool {
 	return err == nil
 }
 
-func (b *BaseInstaller) CreateBackup(path string) (string, error) {
-	if !b.FileExists(path) {
-		return "", errors.ErrConfigNotFound
-	}
-
-	timestamp := time.Now().Format("20060102_150405")
-	backupPath := fmt.Sprintf("%s.backup_%s", path, timestamp)
-
-	if b.FileExists(backupPath) {
-		return "", errors.ErrBackupExists
-	}
-
-	data, err := b.ReadFile(path)
+func (b *BaseInstaller) LookupBinary(name string) (string, error) {
+	path, err := exec.LookPath(name)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("%w: %s", errors.ErrBinaryNotFound, name)
 	}
-
-	if err := b.WriteFile(backupPath, data); err != nil {
-		slog.Error("failed to write backup file", slog.String("error", err.Error()), slog.String("backup_path", backupPath))
-		return "", errors.ErrConfigBackupFailed
-	}
-
-	slog.Info("created configuration backup",
-		slog.String("original", path),
-		slog.String("backup", backupPath))
-
-	return backupPath, nil
-}
-
-func (b *BaseInstaller) RestoreBackup(backupPath, targetPath string) error {
-	if !b.FileExists(backupPath) {
-		return errors.ErrConfigNotFound
-	}
-
-	data, err := b.ReadFile(backupPath)
-	if err != nil {
-		return err
-	}
-
-	if err := b.WriteFile(targetPath, data); err != nil {
-		slog.Error("failed to restore file", slog.String("error", err.Error()), slog.String("target", targetPath))
-		return errors.ErrConfigRestoreFailed
-	}
-
-	slog.Info("restored configuration from backup",
-		slog.String("backup", backupPath),
-		slog.String("target", targetPath))
-
-	return nil
+	return path, nil
 }
 
 func (b *BaseInstaller) GetHomeDir() (string, error) {
@@ -156,44 +139,18 @@ func (b *BaseInstaller) GetPlatformConfigPath(appName, fileName string) (string,
 	}
 }
 
-func (b *BaseInstaller) LoadJSONConfig(ctx context.Context, path string) (map[string]interface{}, error) {
-	data, err := b.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-
-	var config map[string]interface{}
-	if err := json.Unmarshal(data, &config); err != nil {
-		slog.ErrorContext(ctx, "failed to parse JSON config", slog.String("error", err.Error()))
-		return nil, errors.ErrConfigInvalid
-	}
-
-	return config, nil
-}
-
-func (b *BaseInstaller) SaveJSONConfig(ctx context.Context, path string, config interface{}) error {
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		slog.ErrorContext(ctx, "failed to marshal JSON config", slog.String("error", err.Error()))
-		return errors.ErrConfigInvalid
-	}
-
-	return b.WriteFile(path, data)
-}
-
+// CopyFile atomically writes the content of src to dst. A new dst gets the
+// permission bits of src, so a copy of a private file stays private.
 func (b *BaseInstaller) CopyFile(src, dst string) error {
-	sourceFile, err := os.Open(src)
+	info, err := os.Stat(src)
 	if err != nil {
 		return err
 	}
-	defer sourceFile.Close()
 
-	destFile, err := os.Create(dst)
+	data, err := os.ReadFile(src)
 	if err != nil {
 		return err
 	}
-	defer destFile.Close()
 
-	_, err = io.Copy(destFile, sourceFile)
-	return err
+	return atomicfile.WriteFile(dst, data, info.Mode().Perm())
 }
@@ -1,7 +1,9 @@
 package claudecode
 
 import (
+	"bytes"
 	"context"
+	_ "embed"
 	"fmt"
 	"log/slog"
 	"os/exec"
@@ -12,35 +14,87 @@ import (
 	"go.kirha.ai/mcp-installer/internal/adapters/installers"
 	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
 	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/internal/core/ports"
+	"go.kirha.ai/mcp-installer/pkg/jsonschema"
 	"go.kirha.ai/mcp-installer/pkg/security"
 )
 
 const (
+	binaryName     = "claude"
 	configFileName = ".claude.json"
 	mcpKey         = "mcpServers"
+
+	// Local scope servers live under the project's entry in configFileName,
+	// project scope servers in projectConfigFileName at the project root.
+	projectsKey           = "projects"
+	projectConfigFileName = ".mcp.json"
+
+	// Claude Code asks before using servers from projectConfigFileName unless
+	// they are listed under approvedServersKey in the project's local settings.
+	settingsDirName       = ".claude"
+	localSettingsFileName = "settings.local.json"
+	approvedServersKey    = "enabledMcpjsonServers"
 )
 
+// schemaJSON describes the MCP server entries the client accepts.
+//
+//go:embed schema.json
+var schemaJSON []byte
+
+var schema = jsonschema.MustCompile(schemaJSON)
+
 type ClaudeCodeConfig struct {
 	McpServers map[string]McpServerConfig `json:"mcpServers,omitempty"`
+
+	installers.LoadState `json:"-"`
 }
 
 type McpServerConfig struct {
 	Type    string            `json:"type"`
-	URL     string            `json:"url"`
+	URL     string            `json:"url,omitempty"`
 	Headers map[string]string `json:"headers,omitempty"`
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
 }
 
 type Installer struct {
 	*installers.BaseInstaller
+
+	scope   installer.Scope
+	project string
 }
 
 func New() *Installer {
 	return &Installer{
 		BaseInstaller: installers.NewBaseInstaller(),
+		scope:         installer.ScopeUser,
+	}
+}
+
+// WithScope returns an installer for the servers Claude Code keeps at scope:
+// at the top of ~/.claude.json for the user scope, under the project's entry
+// in the same file for the local scope, and in the project's .mcp.json for the
+// project scope.
+func (i *Installer) WithScope(scope installer.Scope, project string) (ports.Installer, error) {
+	switch scope {
+	case installer.ScopeUser:
+		return &Installer{BaseInstaller: i.BaseInstaller, scope: scope}, nil
+	case installer.ScopeLocal, installer.ScopeProject:
+		if !filepath.IsAbs(project) {
+			return nil, fmt.Errorf("the %s scope needs an absolute project directory, got %q", scope, project)
+		}
+		return &Installer{BaseInstaller: i.BaseInstaller, scope: scope, project: filepath.Clean(project)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", errors.ErrScopeNotSupported, scope)
 	}
 }
 
 func (i *Installer) GetConfigPath() (string, error) {
+	if i.scope == installer.ScopeProject {
+		return filepath.Join(i.project, projectConfigFileName), nil
+	}
+
 	home, err := i.GetHomeDir()
 	if err != nil {
 		return "", err
@@ -49,56 +103,110 @@ func (i *Installer) GetConfigPath() (string, error) {
 	return filepath.Join(home, configFileName), nil
 }
 
-func (i *Installer) LoadConfig(ctx context.Context) (interface{}, error) {
+func (i *Installer) GetBinaryPath() (string, error) {
+	return i.LookupBinary(binaryName)
+}
+
+func (i *Installer) documentSpec() (installers.DocumentSpec, error) {
 	path, err := i.GetConfigPath()
 	if err != nil {
-		return nil, err
+		return installers.DocumentSpec{}, err
+	}
+
+	if i.scope == installer.ScopeLocal {
+		// Claude Code keys projects by their path with forward slashes.
+		project := filepath.ToSlash(i.project)
+		return installers.DocumentSpec{
+			Path:    path,
+			Codec:   installers.JSONCodec{},
+			KeyPath: []string{projectsKey, project, mcpKey},
+			Schema: &jsonschema.Schema{Properties: map[string]*jsonschema.Schema{
+				projectsKey: {Properties: map[string]*jsonschema.Schema{project: schema}},
+			}},
+		}, nil
 	}
 
-	if !i.FileExists(path) {
-		slog.InfoContext(ctx, "config file not found, creating new one", slog.String("path", path))
-		return &ClaudeCodeConfig{
-			McpServers: make(map[string]McpServerConfig),
-		}, nil
+	return installers.DocumentSpec{
+		Path:    path,
+		Codec:   installers.JSONCodec{},
+		KeyPath: []string{mcpKey},
+		Schema:  schema,
+	}, nil
+}
+
+func (i *Installer) LoadConfig(ctx context.Context) (interface{}, error) {
+	spec, err := i.documentSpec()
+	if err != nil {
+		return nil, err
 	}
 
-	data, err := i.LoadJSONConfig(ctx, path)
+	state, err := i.LoadDocument(ctx, spec)
 	if err != nil {
 		return nil, err
 	}
 
+	if !state.Snapshot.Exists {
+		slog.InfoContext(ctx, "config file not found, creating new one", slog.String("path", spec.Path))
+	}
+
+	return i.newConfig(state), nil
+}
+
+// newConfig converts the server entries of a loaded document.
+func (i *Installer) newConfig(state *installers.LoadState) *ClaudeCodeConfig {
 	config := &ClaudeCodeConfig{
 		McpServers: make(map[string]McpServerConfig),
+		LoadState:  *state,
+	}
+
+	for name, serverData := range state.Servers {
+		if serverMap, ok := serverData.(map[string]interface{}); ok {
+			config.McpServers[name] = McpServerConfig{
+				Type:    installers.StringValue(serverMap, "type"),
+				URL:     installers.StringValue(serverMap, "url"),
+				Headers: installers.StringMap(serverMap["headers"]),
+				Command: installers.StringValue(serverMap, "command"),
+				Args:    installers.StringSlice(serverMap["args"]),
+				Env:     installers.StringMap(serverMap["env"]),
+			}
+		}
 	}
 
-	if servers, ok := data[mcpKey].(map[string]interface{}); ok {
-		for name, serverData := range servers {
-			if serverMap, ok := serverData.(map[string]interface{}); ok {
-				mcpServer := McpServerConfig{}
+	return config
+}
 
-				if serverType, ok := serverMap["type"].(string); ok {
-					mcpServer.Type = serverType
-				}
+// ParseServers returns the MCP servers of configuration content that is not
+// read from disk, such as a backup.
+func (i *Installer) ParseServers(ctx context.Context, data []byte) ([]*installer.McpServer, error) {
+	spec, err := i.documentSpec()
+	if err != nil {
+		return nil, err
+	}
 
-				if url, ok := serverMap["url"].(string); ok {
-					mcpServer.URL = url
-				}
+	state, err := i.DecodeDocument(ctx, spec, data)
+	if err != nil {
+		return nil, err
+	}
 
-				if headers, ok := serverMap["headers"].(map[string]interface{}); ok {
-					mcpServer.Headers = make(map[string]string)
-					for k, v := range headers {
-						if vStr, ok := v.(string); ok {
-							mcpServer.Headers[k] = vStr
-						}
-					}
-				}
+	return i.ListMcpServers(ctx, i.newConfig(state))
+}
 
-				config.McpServers[name] = mcpServer
-			}
-		}
+func (i *Installer) ParseConfig(data []byte) error {
+	spec, err := i.documentSpec()
+	if err != nil {
+		return err
 	}
 
-	return config, nil
+	return i.ParseDocument(spec, data)
+}
+
+func (i *Installer) RepairConfig(data []byte) ([]byte, []string, error) {
+	spec, err := i.documentSpec()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return i.RepairDocument(spec, data)
 }
 
 func (i *Installer) AddMcpServer(ctx context.Context, config interface{}, server *installer.McpServer) (interface{}, error) {
@@ -111,11 +219,8 @@ func (i *Installer) AddMcpServer(ctx context.Context, config interface{}, server
 		return nil, errors.ErrServerAlreadyExists
 	}
 
-	claudeCodeConfig.McpServers[server.Name] = McpServerConfig{
-		Type:    server.Type,
-		URL:     server.URL,
-		Headers: server.Headers,
-	}
+	claudeCodeConfig.McpServers[server.Name] = fromMcpServer(server)
+	claudeCodeConfig.MarkChanged(server.Name)
 
 	slog.InfoContext(ctx, "added MCP server to configuration",
 		slog.String("server", server.Name))
@@ -135,6 +240,7 @@ func (i *Installer) RemoveMcpServer(ctx context.Context, config interface{}) (in
 	}
 
 	delete(claudeCodeConfig.McpServers, serverName)
+	claudeCodeConfig.MarkChanged(serverName)
 
 	slog.InfoContext(ctx, "removed MCP server from configuration",
 		slog.String("server", serverName))
@@ -148,59 +254,166 @@ func (i *Installer) SaveConfig(ctx context.Context, config interface{}) error {
 		return errors.ErrConfigInvalid
 	}
 
+	spec, err := i.documentSpec()
+	if err != nil {
+		return err
+	}
+
+	patch := installers.BuildPatch(&claudeCodeConfig.LoadState, claudeCodeConfig.McpServers)
+	restore, err := i.approveServers(ctx, patch)
+	if err != nil {
+		return err
+	}
+
+	if err := i.SaveDocument(ctx, spec, &claudeCodeConfig.LoadState, patch); err != nil {
+		restore(ctx)
+		return err
+	}
+	return nil
+}
+
+func (i *Installer) DeleteConfig(ctx context.Context) error {
 	path, err := i.GetConfigPath()
 	if err != nil {
 		return err
 	}
 
-	data := map[string]interface{}{
-		mcpKey: claudeCodeConfig.McpServers,
+	restore, err := i.approveServers(ctx, installers.ServerPatch{Remove: []string{installer.ServerName}})
+	if err != nil {
+		return err
+	}
+
+	if err := i.RemoveFile(path); err != nil {
+		restore(ctx)
+		return err
+	}
+	return nil
+}
+
+// approveServers brings the project's list of approved .mcp.json servers in
+// line with patch, so that Claude Code uses the servers written there without
+// asking first, and stops listing the ones removed. The rest of the local
+// settings is left as it was.
+//
+// It runs before .mcp.json is written, and the returned function puts the
+// settings back when that write fails, so a failed save never leaves the
+// approvals out of line with the servers.
+func (i *Installer) approveServers(ctx context.Context, patch installers.ServerPatch) (func(context.Context), error) {
+	unchanged := func(context.Context) {}
+	if i.scope != installer.ScopeProject {
+		return unchanged, nil
+	}
+
+	path := filepath.Join(i.project, settingsDirName, localSettingsFileName)
+
+	data, snapshot, err := i.TakeSnapshot(path)
+	if err != nil {
+		return nil, err
 	}
 
-	if originalData, err := i.LoadJSONConfig(ctx, path); err == nil {
-		for k, v := range originalData {
-			if k != mcpKey {
-				data[k] = v
+	settings := make(map[string]interface{})
+	if len(bytes.TrimSpace(data)) > 0 {
+		settings, err = installers.JSONCodec{}.Decode(data)
+		if err != nil {
+			if parseErr, ok := err.(*installer.ParseError); ok {
+				parseErr.Path = path
 			}
+			return nil, err
 		}
 	}
 
-	return i.SaveJSONConfig(ctx, path, data)
-}
+	current := installers.StringSlice(settings[approvedServersKey])
+	approved := make([]string, 0, len(current)+len(patch.Upsert))
+	listed := make(map[string]bool)
+	changed := false
+	for _, name := range current {
+		if listed[name] || containsString(patch.Remove, name) {
+			changed = true
+			continue
+		}
+		approved = append(approved, name)
+		listed[name] = true
+	}
+	for _, name := range patch.Names() {
+		if _, upserted := patch.Upsert[name]; upserted && !listed[name] {
+			approved = append(approved, name)
+			changed = true
+		}
+	}
 
-func (i *Installer) BackupConfig(ctx context.Context) (string, error) {
-	path, err := i.GetConfigPath()
+	if !changed {
+		return unchanged, nil
+	}
+
+	var out []byte
+	if len(bytes.TrimSpace(data)) == 0 {
+		out, err = installers.JSONCodec{}.Encode(map[string]interface{}{approvedServersKey: approved})
+	} else {
+		out, err = installers.JSONCodec{}.Replace(data, []string{approvedServersKey}, approved)
+	}
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("%w: %s: %v", errors.ErrConfigInvalid, path, err)
 	}
 
-	if !i.FileExists(path) {
-		slog.InfoContext(ctx, "no existing config to backup")
-		return "", nil
+	if err := i.WriteFile(path, out); err != nil {
+		return nil, err
 	}
 
-	return i.CreateBackup(path)
+	slog.InfoContext(ctx, "updated approved project servers",
+		slog.String("path", path),
+		slog.Any("servers", approved))
+
+	return func(ctx context.Context) {
+		i.restoreSettings(ctx, path, out, data, snapshot.Exists)
+	}, nil
 }
 
-func (i *Installer) RestoreConfig(ctx context.Context, backupPath string) error {
-	if backupPath == "" {
-		return nil
+// restoreSettings puts back the local settings approveServers replaced,
+// unless something else rewrote them in the meantime.
+func (i *Installer) restoreSettings(ctx context.Context, path string, written, previous []byte, existed bool) {
+	current, _, err := i.TakeSnapshot(path)
+	if err == nil && !bytes.Equal(current, written) {
+		err = fmt.Errorf("%s changed since it was written", path)
+	}
+	if err == nil {
+		if existed {
+			err = i.WriteFile(path, previous)
+		} else {
+			err = i.RemoveFile(path)
+		}
 	}
 
-	path, err := i.GetConfigPath()
 	if err != nil {
-		return err
+		slog.ErrorContext(ctx, "failed to restore approved project servers",
+			slog.String("path", path),
+			slog.String("error", err.Error()))
+		return
 	}
 
-	return i.RestoreBackup(backupPath, path)
+	slog.InfoContext(ctx, "restored approved project servers", slog.String("path", path))
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
 
 func (i *Installer) ValidateConfig(ctx context.Context, config interface{}) error {
-	_, ok := config.(*ClaudeCodeConfig)
+	claudeCodeConfig, ok := config.(*ClaudeCodeConfig)
 	if !ok {
 		return errors.ErrConfigInvalid
 	}
-	return nil
+
+	spec, err := i.documentSpec()
+	if err != nil {
+		return err
+	}
+
+	return i.ValidateDocument(spec, &claudeCodeConfig.LoadState, installers.BuildPatch(&claudeCodeConfig.LoadState, claudeCodeConfig.McpServers))
 }
 
 func (i *Installer) IsClientRunning(ctx context.Context) (bool, error) {
@@ -244,12 +457,62 @@ func (i *Installer) GetMcpServerConfig(ctx context.Context, config interface{})
 		return nil, errors.ErrServerNotFound
 	}
 
+	return toMcpServer(serverName, serverConfig), nil
+}
+
+func (i *Installer) ListMcpServers(ctx context.Context, config interface{}) ([]*installer.McpServer, error) {
+	claudeCodeConfig, ok := config.(*ClaudeCodeConfig)
+	if !ok {
+		return nil, errors.ErrConfigInvalid
+	}
+
+	servers := make([]*installer.McpServer, 0, len(claudeCodeConfig.McpServers))
+	for name, serverConfig := range claudeCodeConfig.McpServers {
+		servers = append(servers, toMcpServer(name, serverConfig))
+	}
+
+	return installers.SortServers(servers), nil
+}
+
+// UnsupportedFields reports what Claude Code cannot store: it has no per-server
+// switch to disable a server.
+func (i *Installer) UnsupportedFields(server *installer.McpServer) []string {
+	return installers.UnsupportedFields(server,
+		[]string{installer.TransportStdio, installer.TransportHTTP, installer.TransportSSE}, false)
+}
+
+func toMcpServer(name string, serverConfig McpServerConfig) *installer.McpServer {
+	serverType := serverConfig.Type
+	if serverType == "" && serverConfig.Command != "" {
+		serverType = installer.TransportStdio
+	}
+
 	return &installer.McpServer{
-		Name:    serverName,
-		Type:    serverConfig.Type,
+		Name:    name,
+		Type:    serverType,
 		URL:     serverConfig.URL,
 		Headers: serverConfig.Headers,
-	}, nil
+		Command: serverConfig.Command,
+		Args:    serverConfig.Args,
+		Env:     serverConfig.Env,
+	}
+}
+
+func fromMcpServer(server *installer.McpServer) McpServerConfig {
+	if server.Type == installer.TransportStdio {
+		return McpServerConfig{
+			Type:    installer.TransportStdio,
+			Command: server.Command,
+			Args:    server.Args,
+			Env:     server.Env,
+		}
+	}
+
+	return McpServerConfig{
+		Type:    server.Type,
+		URL:     server.URL,
+		Headers: server.Headers,
+	}
 }
 
 func (i *Installer) FormatConfig(ctx context.Context, config interface{}) (string, error) {
@@ -296,7 +559,11 @@ func (i *Installer) formatServerSection(sectionTitle string, servers map[string]
 	for name, server := range servers {
 		result += fmt.Sprintf("Server: %s\n", name)
 		result += fmt.Sprintf("  Type: %s\n", server.Type)
-		result += fmt.Sprintf("  URL: %s\n", server.URL)
+		if server.Command != "" {
+			result += fmt.Sprintf("  Command: %s\n", strings.Join(append([]string{server.Command}, server.Args...), " "))
+		} else {
+			result += fmt.Sprintf("  URL: %s\n", server.URL)
+		}
 		if len(server.Headers) > 0 {
 			result += "  Headers:\n"
 			for k, v := range server.Headers {
@@ -0,0 +1,59 @@
+package claudecode
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+func TestSaveConfig_RestoresApprovalsWhenSaveFails(t *testing.T) {
+	ctx := context.Background()
+	project := t.TempDir()
+
+	settingsPath := filepath.Join(project, settingsDirName, localSettingsFileName)
+	settings := "{\n  \"enabledMcpjsonServers\": [\"other\"]\n}\n"
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0700); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(settingsPath, []byte(settings), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	scoped, err := New().WithScope(installer.ScopeProject, project)
+	if err != nil {
+		t.Fatalf("WithScope() error = %v", err)
+	}
+
+	config, err := scoped.LoadConfig(ctx)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	config, err = scoped.AddMcpServer(ctx, config, &installer.McpServer{
+		Name: installer.ServerName,
+		URL:  "https://mcp.kirha.com",
+	})
+	if err != nil {
+		t.Fatalf("AddMcpServer() error = %v", err)
+	}
+
+	// .mcp.json cannot be read back once it is a directory, so the save fails
+	// after the approvals were written.
+	if err := os.Mkdir(filepath.Join(project, projectConfigFileName), 0700); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+
+	if err := scoped.SaveConfig(ctx, config); err == nil {
+		t.Fatal("SaveConfig() error = nil, want an error")
+	}
+
+	content, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(content) != settings {
+		t.Errorf("settings = %s, want them restored to %s", content, settings)
+	}
+}
@@ -1,35 +1,51 @@
 package codex
 
 import (
-	"bytes"
 	"context"
+	_ "embed"
 	"fmt"
 	"log/slog"
-	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
 
-	"github.com/BurntSushi/toml"
 	"go.kirha.ai/mcp-installer/internal/adapters/installers"
 	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
 	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/pkg/jsonschema"
 	"go.kirha.ai/mcp-installer/pkg/security"
 )
 
 const (
+	binaryName     = "codex"
 	configFileName = "config.toml"
 	configDir      = ".codex"
+	mcpKey         = "mcp_servers"
 )
 
+// schemaJSON describes the MCP server entries the client accepts.
+//
+//go:embed schema.json
+var schemaJSON []byte
+
+var schema = jsonschema.MustCompile(schemaJSON)
+
 type CodexConfig struct {
 	McpServers map[string]McpServerConfig `toml:"mcp_servers"`
+
+	installers.LoadState `toml:"-"`
 }
 
+// McpServerConfig is a server entry. Codex enables servers unless enabled is
+// set to false, so Enabled is only written for disabled servers.
 type McpServerConfig struct {
-	URL         string            `toml:"url"`
+	URL         string            `toml:"url,omitempty"`
 	HTTPHeaders map[string]string `toml:"http_headers,omitempty"`
+	Command     string            `toml:"command,omitempty"`
+	Args        []string          `toml:"args,omitempty"`
+	Env         map[string]string `toml:"env,omitempty"`
+	Enabled     *bool             `toml:"enabled,omitempty"`
 }
 
 type Installer struct {
@@ -51,34 +67,102 @@ func (i *Installer) GetConfigPath() (string, error) {
 	return filepath.Join(home, configDir, configFileName), nil
 }
 
-func (i *Installer) LoadConfig(ctx context.Context) (interface{}, error) {
+func (i *Installer) GetBinaryPath() (string, error) {
+	return i.LookupBinary(binaryName)
+}
+
+func (i *Installer) documentSpec() (installers.DocumentSpec, error) {
 	path, err := i.GetConfigPath()
+	if err != nil {
+		return installers.DocumentSpec{}, err
+	}
+
+	return installers.DocumentSpec{
+		Path:    path,
+		Codec:   installers.TOMLCodec{},
+		KeyPath: []string{mcpKey},
+		Schema:  schema,
+	}, nil
+}
+
+func (i *Installer) LoadConfig(ctx context.Context) (interface{}, error) {
+	spec, err := i.documentSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := i.LoadDocument(ctx, spec)
 	if err != nil {
 		return nil, err
 	}
 
-	if !i.FileExists(path) {
-		slog.InfoContext(ctx, "config file not found, creating new one", slog.String("path", path))
-		return &CodexConfig{
-			McpServers: make(map[string]McpServerConfig),
-		}, nil
+	if !state.Snapshot.Exists {
+		slog.InfoContext(ctx, "config file not found, creating new one", slog.String("path", spec.Path))
+	}
+
+	return i.newConfig(state), nil
+}
+
+// newConfig converts the server entries of a loaded document.
+func (i *Installer) newConfig(state *installers.LoadState) *CodexConfig {
+	config := &CodexConfig{
+		McpServers: make(map[string]McpServerConfig),
+		LoadState:  *state,
+	}
+
+	for name, serverData := range state.Servers {
+		if serverMap, ok := serverData.(map[string]interface{}); ok {
+			mcpServer := McpServerConfig{
+				URL:         installers.StringValue(serverMap, "url"),
+				HTTPHeaders: installers.StringMap(serverMap["http_headers"]),
+				Command:     installers.StringValue(serverMap, "command"),
+				Args:        installers.StringSlice(serverMap["args"]),
+				Env:         installers.StringMap(serverMap["env"]),
+			}
+
+			if enabled, ok := serverMap["enabled"].(bool); ok {
+				mcpServer.Enabled = &enabled
+			}
+
+			config.McpServers[name] = mcpServer
+		}
+	}
+
+	return config
+}
+
+// ParseServers returns the MCP servers of configuration content that is not
+// read from disk, such as a backup.
+func (i *Installer) ParseServers(ctx context.Context, data []byte) ([]*installer.McpServer, error) {
+	spec, err := i.documentSpec()
+	if err != nil {
+		return nil, err
 	}
 
-	data, err := os.ReadFile(path)
+	state, err := i.DecodeDocument(ctx, spec, data)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", errors.ErrConfigReadFailed, err)
+		return nil, err
 	}
 
-	var config CodexConfig
-	if _, err := toml.Decode(string(data), &config); err != nil {
-		return nil, fmt.Errorf("%w: %v", errors.ErrConfigInvalid, err)
+	return i.ListMcpServers(ctx, i.newConfig(state))
+}
+
+func (i *Installer) ParseConfig(data []byte) error {
+	spec, err := i.documentSpec()
+	if err != nil {
+		return err
 	}
 
-	if config.McpServers == nil {
-		config.McpServers = make(map[string]McpServerConfig)
+	return i.ParseDocument(spec, data)
+}
+
+func (i *Installer) RepairConfig(data []byte) ([]byte, []string, error) {
+	spec, err := i.documentSpec()
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return &config, nil
+	return i.RepairDocument(spec, data)
 }
 
 func (i *Installer) AddMcpServer(ctx context.Context, config interface{}, server *installer.McpServer) (interface{}, error) {
@@ -91,10 +175,8 @@ func (i *Installer) AddMcpServer(ctx context.Context, config interface{}, server
 		return nil, errors.ErrServerAlreadyExists
 	}
 
-	codexConfig.McpServers[server.Name] = McpServerConfig{
-		URL:         server.URL,
-		HTTPHeaders: server.Headers,
-	}
+	codexConfig.McpServers[server.Name] = fromMcpServer(server)
+	codexConfig.MarkChanged(server.Name)
 
 	slog.InfoContext(ctx, "added MCP server to configuration",
 		slog.String("server", server.Name))
@@ -114,6 +196,7 @@ func (i *Installer) RemoveMcpServer(ctx context.Context, config interface{}) (in
 	}
 
 	delete(codexConfig.McpServers, serverName)
+	codexConfig.MarkChanged(serverName)
 
 	slog.InfoContext(ctx, "removed MCP server from configuration",
 		slog.String("server", serverName))
@@ -121,69 +204,44 @@ func (i *Installer) RemoveMcpServer(ctx context.Context, config interface{}) (in
 	return codexConfig, nil
 }
 
+// SaveConfig writes the changed servers. The TOML document is encoded again as
+// a whole, so its settings are kept but its comments are not.
 func (i *Installer) SaveConfig(ctx context.Context, config interface{}) error {
 	codexConfig, ok := config.(*CodexConfig)
 	if !ok {
 		return errors.ErrConfigInvalid
 	}
 
-	path, err := i.GetConfigPath()
+	spec, err := i.documentSpec()
 	if err != nil {
 		return err
 	}
 
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("%w: %v", errors.ErrConfigWriteFailed, err)
-	}
-
-	var buf bytes.Buffer
-	encoder := toml.NewEncoder(&buf)
-	if err := encoder.Encode(codexConfig); err != nil {
-		return fmt.Errorf("%w: %v", errors.ErrConfigWriteFailed, err)
-	}
-
-	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
-		return fmt.Errorf("%w: %v", errors.ErrConfigWriteFailed, err)
-	}
-
-	slog.InfoContext(ctx, "saved configuration", slog.String("path", path))
-	return nil
+	patch := installers.BuildPatch(&codexConfig.LoadState, codexConfig.McpServers)
+	return i.SaveDocument(ctx, spec, &codexConfig.LoadState, patch)
 }
 
-func (i *Installer) BackupConfig(ctx context.Context) (string, error) {
+func (i *Installer) DeleteConfig(ctx context.Context) error {
 	path, err := i.GetConfigPath()
 	if err != nil {
-		return "", err
-	}
-
-	if !i.FileExists(path) {
-		slog.InfoContext(ctx, "no existing config to backup")
-		return "", nil
+		return err
 	}
 
-	return i.CreateBackup(path)
+	return i.RemoveFile(path)
 }
 
-func (i *Installer) RestoreConfig(ctx context.Context, backupPath string) error {
-	if backupPath == "" {
-		return nil
+func (i *Installer) ValidateConfig(ctx context.Context, config interface{}) error {
+	codexConfig, ok := config.(*CodexConfig)
+	if !ok {
+		return errors.ErrConfigInvalid
 	}
 
-	path, err := i.GetConfigPath()
+	spec, err := i.documentSpec()
 	if err != nil {
 		return err
 	}
 
-	return i.RestoreBackup(backupPath, path)
-}
-
-func (i *Installer) ValidateConfig(ctx context.Context, config interface{}) error {
-	_, ok := config.(*CodexConfig)
-	if !ok {
-		return errors.ErrConfigInvalid
-	}
-	return nil
+	return i.ValidateDocument(spec, &codexConfig.LoadState, installers.BuildPatch(&codexConfig.LoadState, codexConfig.McpServers))
 }
 
 func (i *Installer) IsClientRunning(ctx context.Context) (bool, error) {
@@ -227,12 +285,75 @@ func (i *Installer) GetMcpServerConfig(ctx context.Context, config interface{})
 		return nil, errors.ErrServerNotFound
 	}
 
+	return toMcpServer(serverName, serverConfig), nil
+}
+
+func (i *Installer) ListMcpServers(ctx context.Context, config interface{}) ([]*installer.McpServer, error) {
+	codexConfig, ok := config.(*CodexConfig)
+	if !ok {
+		return nil, errors.ErrConfigInvalid
+	}
+
+	servers := make([]*installer.McpServer, 0, len(codexConfig.McpServers))
+	for name, serverConfig := range codexConfig.McpServers {
+		servers = append(servers, toMcpServer(name, serverConfig))
+	}
+
+	return installers.SortServers(servers), nil
+}
+
+// UnsupportedFields reports what Codex cannot store: it only speaks stdio and
+// streamable HTTP.
+func (i *Installer) UnsupportedFields(server *installer.McpServer) []string {
+	return installers.UnsupportedFields(server,
+		[]string{installer.TransportStdio, installer.TransportHTTP}, true)
+}
+
+// toMcpServer derives the transport from the entry, since Codex does not
+// store one: servers with a command run over stdio.
+func toMcpServer(name string, serverConfig McpServerConfig) *installer.McpServer {
+	disabled := serverConfig.Enabled != nil && !*serverConfig.Enabled
+
+	if serverConfig.Command != "" {
+		return &installer.McpServer{
+			Name:     name,
+			Type:     installer.TransportStdio,
+			Command:  serverConfig.Command,
+			Args:     serverConfig.Args,
+			Env:      serverConfig.Env,
+			Disabled: disabled,
+		}
+	}
+
 	return &installer.McpServer{
-		Name:    serverName,
-		Type:    "http",
-		URL:     serverConfig.URL,
-		Headers: serverConfig.HTTPHeaders,
-	}, nil
+		Name:     name,
+		Type:     installer.TransportHTTP,
+		URL:      serverConfig.URL,
+		Headers:  serverConfig.HTTPHeaders,
+		Disabled: disabled,
+	}
+}
+
+func fromMcpServer(server *installer.McpServer) McpServerConfig {
+	var enabled *bool
+	if server.Disabled {
+		enabled = new(bool)
+	}
+
+	if server.Type == installer.TransportStdio {
+		return McpServerConfig{
+			Command: server.Command,
+			Args:    server.Args,
+			Env:     server.Env,
+			Enabled: enabled,
+		}
+	}
+
+	return McpServerConfig{
+		URL:         server.URL,
+		HTTPHeaders: server.Headers,
+		Enabled:     enabled,
+	}
 }
 
 func (i *Installer) FormatConfig(ctx context.Context, config interface{}) (string, error) {
@@ -278,7 +399,11 @@ func (i *Installer) formatServerSection(sectionTitle string, servers map[string]
 
 	for name, server := range servers {
 		result += fmt.Sprintf("Server: %s\n", name)
-		result += fmt.Sprintf("  URL: %s\n", server.URL)
+		if server.Command != "" {
+			result += fmt.Sprintf("  Command: %s\n", strings.Join(append([]string{server.Command}, server.Args...), " "))
+		} else {
+			result += fmt.Sprintf("  URL: %s\n", server.URL)
+		}
 		if len(server.HTTPHeaders) > 0 {
 			result += "  Headers:\n"
 			for k, v := range server.HTTPHeaders {
@@ -289,6 +414,9 @@ func (i *Installer) formatServerSection(sectionTitle string, servers map[string]
 				}
 			}
 		}
+		if server.Enabled != nil && !*server.Enabled {
+			result += "  Disabled: true\n"
+		}
 		result += "\n"
 	}
 
@@ -0,0 +1,111 @@
+package codex
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	path := filepath.Join(home, configDir, configFileName)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestInstaller_DisabledServer(t *testing.T) {
+	ctx := context.Background()
+	path := writeConfig(t, "[mcp_servers.docs]\ncommand = \"npx\"\nenabled = false\n")
+	i := New()
+
+	config, err := i.LoadConfig(ctx)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	servers, err := i.ListMcpServers(ctx, config)
+	if err != nil {
+		t.Fatalf("ListMcpServers() error = %v", err)
+	}
+	if len(servers) != 1 || !servers[0].Disabled {
+		t.Fatalf("ListMcpServers() = %+v, want docs disabled", servers)
+	}
+
+	events := &installer.McpServer{Name: "events", Type: installer.TransportHTTP, URL: "https://events.example.com/mcp", Disabled: true}
+	if fields := i.UnsupportedFields(events); len(fields) != 0 {
+		t.Errorf("UnsupportedFields() = %v, want none", fields)
+	}
+
+	config, err = i.AddMcpServer(ctx, config, events)
+	if err != nil {
+		t.Fatalf("AddMcpServer() error = %v", err)
+	}
+	if err := i.SaveConfig(ctx, config); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	config, err = i.LoadConfig(ctx)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	servers, err = i.ListMcpServers(ctx, config)
+	if err != nil {
+		t.Fatalf("ListMcpServers() error = %v", err)
+	}
+	for _, server := range servers {
+		if !server.Disabled {
+			t.Errorf("server %s enabled after saving, want disabled", server.Name)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Count(string(content), "enabled = false") != 2 {
+		t.Errorf("saved config =\n%s\nwant enabled = false on both servers", content)
+	}
+}
+
+// The TOML file is decoded and encoded again when saved, which keeps every
+// setting but not comments.
+func TestInstaller_SaveDropsComments(t *testing.T) {
+	ctx := context.Background()
+	path := writeConfig(t, "# picked for long sessions\nmodel = \"o3\"\n")
+	i := New()
+
+	config, err := i.LoadConfig(ctx)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	config, err = i.AddMcpServer(ctx, config, installer.NewKirhaRemoteMcpServer("test-api-key-123"))
+	if err != nil {
+		t.Fatalf("AddMcpServer() error = %v", err)
+	}
+	if err := i.SaveConfig(ctx, config); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.Contains(string(content), `model = "o3"`) {
+		t.Errorf("saved config =\n%s\nwant the model setting kept", content)
+	}
+	if strings.Contains(string(content), "#") {
+		t.Errorf("saved config =\n%s\nwant comments dropped", content)
+	}
+}
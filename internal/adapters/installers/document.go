@@ -0,0 +1,951 @@
+package installers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/pkg/atomicfile"
+	"go.kirha.ai/mcp-installer/pkg/jsonrepair"
+	"go.kirha.ai/mcp-installer/pkg/jsonschema"
+	"go.kirha.ai/mcp-installer/pkg/jsonspan"
+)
+
+const (
+	maxSaveAttempts = 3
+
+	// racyWindow is how long after its last modification a file must have
+	// been read for an unchanged size and modification time to prove it has
+	// not been written since. A write within the same timestamp tick as the
+	// one before it would otherwise go unnoticed.
+	racyWindow = 2 * time.Second
+)
+
+// Codec converts a configuration file between its on-disk form and a generic
+// document. Decode reports syntax errors as *installer.ParseError.
+type Codec interface {
+	Decode(data []byte) (map[string]interface{}, error)
+	Encode(doc map[string]interface{}) ([]byte, error)
+	// Repair applies the fixes that are safe to make without guessing what
+	// the author meant, and describes them.
+	Repair(data []byte) ([]byte, []string)
+}
+
+// SpanCodec is implemented by codecs that can find and replace the value at
+// a key path without decoding the rest of the document. Only the server map is
+// then decoded and re-encoded, however large the file, and every other byte is
+// left as it was.
+type SpanCodec interface {
+	Codec
+	Locate(data []byte, keyPath []string) (jsonspan.Span, bool, error)
+	Replace(data []byte, keyPath []string, value interface{}) ([]byte, error)
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+type JSONCodec struct{}
+
+func (JSONCodec) Decode(data []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc map[string]interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, jsonParseError(data, err)
+	}
+
+	return doc, nil
+}
+
+// jsonParseError locates a decoding error. Syntax errors are reported after
+// the offending byte has been read.
+func jsonParseError(data []byte, err error) *installer.ParseError {
+	offset := -1
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case stderrors.As(err, &syntaxErr):
+		offset = max(int(syntaxErr.Offset)-1, 0)
+	case stderrors.As(err, &typeErr):
+		offset = max(int(typeErr.Offset)-1, 0)
+	case stderrors.Is(err, io.ErrUnexpectedEOF):
+		offset = len(data)
+		err = fmt.Errorf("unexpected end of file, the file may be truncated")
+	}
+
+	parseErr := &installer.ParseError{Message: err.Error()}
+	if offset >= 0 {
+		parseErr.Line, parseErr.Column = jsonrepair.Position(data, offset)
+	}
+	return parseErr
+}
+
+func (JSONCodec) Repair(data []byte) ([]byte, []string) {
+	fixed, fixes := jsonrepair.Repair(data)
+	descriptions := make([]string, 0, len(fixes))
+	for _, fix := range fixes {
+		descriptions = append(descriptions, fix.String())
+	}
+	return fixed, descriptions
+}
+
+func (JSONCodec) Encode(doc map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Locate finds the value at keyPath. The scanner does not check what it skips,
+// so data is validated first: a syntax error anywhere in the document makes
+// the caller fall back to the full decode, which reports it.
+func (JSONCodec) Locate(data []byte, keyPath []string) (jsonspan.Span, bool, error) {
+	if !json.Valid(data) {
+		return jsonspan.Span{}, false, fmt.Errorf("invalid JSON document")
+	}
+	return jsonspan.Find(data, keyPath...)
+}
+
+// Replace sets the value at keyPath, indented like the rest of data.
+func (JSONCodec) Replace(data []byte, keyPath []string, value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return jsonspan.Replace(data, keyPath, buf.Bytes())
+}
+
+type TOMLCodec struct{}
+
+func (TOMLCodec) Decode(data []byte) (map[string]interface{}, error) {
+	doc := make(map[string]interface{})
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		parseErr := &installer.ParseError{Message: err.Error()}
+		var tomlErr toml.ParseError
+		if stderrors.As(err, &tomlErr) {
+			parseErr.Message = tomlErr.Message
+			parseErr.Line, parseErr.Column = tomlErr.Position.Line, tomlErr.Position.Col
+		}
+		return nil, parseErr
+	}
+
+	return doc, nil
+}
+
+// Repair only removes a byte order mark; other TOML mistakes are ambiguous.
+func (TOMLCodec) Repair(data []byte) ([]byte, []string) {
+	if !bytes.HasPrefix(data, utf8BOM) {
+		return data, nil
+	}
+	return data[len(utf8BOM):], []string{"line 1, column 1: removed byte order mark"}
+}
+
+func (TOMLCodec) Encode(doc map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(doc); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// DocumentSpec describes where a client keeps its configuration and where the
+// MCP server map lives inside it. Schema, when set, is checked against every
+// document before it is written.
+type DocumentSpec struct {
+	Path    string
+	Codec   Codec
+	KeyPath []string
+	Schema  *jsonschema.Schema
+}
+
+// Snapshot identifies the content of a configuration file at the time it was read.
+type Snapshot struct {
+	Exists  bool
+	Size    int64
+	ModTime time.Time
+	Hash    string
+
+	info   os.FileInfo
+	readAt time.Time
+}
+
+func (s Snapshot) SameContent(other Snapshot) bool {
+	return s.Exists == other.Exists && s.Hash == other.Hash
+}
+
+// LoadState remembers what a configuration looked like when it was loaded, and
+// which servers have been changed since, so that the changes can be re-applied
+// on top of whatever is on disk at save time.
+type LoadState struct {
+	Snapshot Snapshot
+	Servers  map[string]interface{}
+	changed  map[string]bool
+	raw      []byte
+}
+
+func (s *LoadState) MarkChanged(name string) {
+	if s.changed == nil {
+		s.changed = make(map[string]bool)
+	}
+	s.changed[name] = true
+}
+
+func (s *LoadState) Changed() []string {
+	names := make([]string, 0, len(s.changed))
+	for name := range s.changed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ServerPatch lists the server entries to write or delete when saving.
+type ServerPatch struct {
+	Upsert map[string]interface{}
+	Remove []string
+}
+
+func (p ServerPatch) Names() []string {
+	names := make([]string, 0, len(p.Upsert)+len(p.Remove))
+	for name := range p.Upsert {
+		names = append(names, name)
+	}
+	names = append(names, p.Remove...)
+	sort.Strings(names)
+	return names
+}
+
+// BuildPatch turns the servers marked as changed in state into a patch, taking
+// their new values from servers. Changed servers that no longer exist are removed.
+func BuildPatch[T any](state *LoadState, servers map[string]T) ServerPatch {
+	patch := ServerPatch{Upsert: make(map[string]interface{})}
+	for _, name := range state.Changed() {
+		if server, ok := servers[name]; ok {
+			patch.Upsert[name] = server
+		} else {
+			patch.Remove = append(patch.Remove, name)
+		}
+	}
+	return patch
+}
+
+func (b *BaseInstaller) TakeSnapshot(path string) ([]byte, Snapshot, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, Snapshot{}, nil
+	}
+	if err != nil {
+		if os.IsPermission(err) {
+			return nil, Snapshot{}, errors.ErrPermissionDenied
+		}
+		return nil, Snapshot{}, errors.ErrConfigReadFailed
+	}
+
+	data, err := b.ReadFile(path)
+	if err != nil {
+		return nil, Snapshot{}, err
+	}
+
+	return data, newSnapshot(data, info, time.Now()), nil
+}
+
+func newSnapshot(data []byte, info os.FileInfo, readAt time.Time) Snapshot {
+	sum := sha256.Sum256(data)
+	return Snapshot{
+		Exists:  true,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Hash:    hex.EncodeToString(sum[:]),
+		info:    info,
+		readAt:  readAt,
+	}
+}
+
+// provablyUnchanged reports whether a stat alone shows that path still holds
+// the content of snapshot: it is the same file, with the same size and
+// modification time, and it was read long enough after that modification
+// for any later write to have moved the time.
+func provablyUnchanged(path string, snapshot Snapshot) bool {
+	info, err := os.Stat(path)
+	if !snapshot.Exists {
+		return os.IsNotExist(err)
+	}
+	if err != nil || snapshot.info == nil || snapshot.readAt.Sub(snapshot.ModTime) < racyWindow {
+		return false
+	}
+	return os.SameFile(info, snapshot.info) &&
+		info.Size() == snapshot.Size &&
+		info.ModTime().Equal(snapshot.ModTime)
+}
+
+// sameContent reports whether path still holds the content of snapshot,
+// hashing it again unless a stat is enough to tell.
+func (b *BaseInstaller) sameContent(path string, snapshot Snapshot) (bool, error) {
+	if provablyUnchanged(path, snapshot) {
+		return true, nil
+	}
+	_, current, err := b.TakeSnapshot(path)
+	if err != nil {
+		return false, err
+	}
+	return current.SameContent(snapshot), nil
+}
+
+// currentContent returns the content of path, reusing what state was loaded
+// from when a stat shows the file has not been written since.
+func (b *BaseInstaller) currentContent(path string, state *LoadState) ([]byte, Snapshot, error) {
+	if (state.raw != nil || !state.Snapshot.Exists) && provablyUnchanged(path, state.Snapshot) {
+		return state.raw, state.Snapshot, nil
+	}
+	return b.TakeSnapshot(path)
+}
+
+// writtenFile is the content this installer last wrote and verified, kept so
+// that loading the file again right after saving it does not read it back.
+type writtenFile struct {
+	path     string
+	data     []byte
+	snapshot Snapshot
+}
+
+func (b *BaseInstaller) rememberWrite(path string, data []byte) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.written = &writtenFile{path: path, data: data, snapshot: newSnapshot(data, info, time.Now())}
+}
+
+// readDocument is TakeSnapshot, answered from the last write while the file
+// is still the one written. Only loading trusts this: the checks made before
+// writing always compare content.
+func (b *BaseInstaller) readDocument(path string) ([]byte, Snapshot, error) {
+	b.mu.Lock()
+	written := b.written
+	b.mu.Unlock()
+
+	if written != nil && written.path == path {
+		info, err := os.Stat(path)
+		if err == nil && os.SameFile(info, written.snapshot.info) &&
+			info.Size() == written.snapshot.Size && info.ModTime().Equal(written.snapshot.ModTime) {
+			return written.data, written.snapshot, nil
+		}
+	}
+
+	return b.TakeSnapshot(path)
+}
+
+// LoadDocument reads the configuration described by spec and returns its MCP
+// server entries along with the snapshot needed to save it safely later.
+// A missing file loads as an empty configuration.
+func (b *BaseInstaller) LoadDocument(ctx context.Context, spec DocumentSpec) (*LoadState, error) {
+	data, snapshot, err := b.readDocument(spec.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	servers, err := b.decodeServers(ctx, spec, data)
+	if err != nil {
+		return nil, err
+	}
+	if servers == nil {
+		servers = make(map[string]interface{})
+	}
+
+	return &LoadState{
+		Snapshot: snapshot,
+		Servers:  servers,
+		raw:      data,
+	}, nil
+}
+
+// DecodeDocument returns the MCP server entries of data, a configuration
+// described by spec that is not read from spec.Path.
+func (b *BaseInstaller) DecodeDocument(ctx context.Context, spec DocumentSpec, data []byte) (*LoadState, error) {
+	servers, err := b.decodeServers(ctx, spec, data)
+	if err != nil {
+		return nil, err
+	}
+	if servers == nil {
+		servers = make(map[string]interface{})
+	}
+
+	return &LoadState{Servers: servers, raw: data}, nil
+}
+
+// decodeServers returns the server map of data, decoding only that value
+// when the codec can locate it.
+func (b *BaseInstaller) decodeServers(ctx context.Context, spec DocumentSpec, data []byte) (map[string]interface{}, error) {
+	if servers, ok := spanServers(spec, data); ok {
+		return servers, nil
+	}
+
+	doc, err := b.decodeDocument(ctx, spec, data)
+	if err != nil {
+		return nil, err
+	}
+	return lookupServers(doc, spec.KeyPath), nil
+}
+
+// spanServers decodes the server map of data on its own. It fails, leaving
+// the caller to decode the whole document, whenever the codec cannot locate
+// the map or it is not one: the full decode reports errors with their
+// position and handles whatever is in the way.
+func spanServers(spec DocumentSpec, data []byte) (map[string]interface{}, bool) {
+	codec, ok := spec.Codec.(SpanCodec)
+	if !ok || len(spec.KeyPath) == 0 {
+		return nil, false
+	}
+
+	span, found, err := codec.Locate(data, spec.KeyPath)
+	if err != nil {
+		return nil, false
+	}
+	if !found {
+		return nil, true
+	}
+
+	servers, err := codec.Decode(data[span.Start:span.End])
+	if err != nil {
+		return nil, false
+	}
+	return servers, true
+}
+
+// SaveDocument applies patch to the configuration described by spec.
+//
+// The file is re-read right before writing. If it changed since it was loaded
+// (for example because the client rewrote it while running) the patch is
+// re-applied on top of the fresh content, unless the client changed one of the
+// very entries being patched. The swap is aborted if the file changes again
+// between that read and the rename, and nothing is written if the encoded
+// document does not match the spec's schema. Once written, the file is read
+// back and put back as it was if anything besides the patched entries changed.
+//
+// With a SpanCodec only the server map is decoded, patched and spliced back
+// into the content, and the content loaded into state is reused when a stat
+// shows the file has not been written since.
+func (b *BaseInstaller) SaveDocument(ctx context.Context, spec DocumentSpec, state *LoadState, patch ServerPatch) error {
+	for attempt := 1; ; attempt++ {
+		data, snapshot, err := b.currentContent(spec.Path, state)
+		if err != nil {
+			return err
+		}
+
+		out, err := b.patchContent(ctx, spec, data, !snapshot.SameContent(state.Snapshot), state, patch)
+		if err != nil {
+			return err
+		}
+
+		err = b.writeIfUnchanged(spec.Path, out, snapshot)
+		if stderrors.Is(err, errors.ErrConfigModified) && attempt < maxSaveAttempts {
+			slog.WarnContext(ctx, "configuration changed while saving, retrying",
+				slog.String("path", spec.Path),
+				slog.Int("attempt", attempt))
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := b.verifyWrite(ctx, spec, data, snapshot.Exists, out, patch); err != nil {
+			return err
+		}
+
+		slog.InfoContext(ctx, "saved configuration", slog.String("path", spec.Path))
+		return nil
+	}
+}
+
+// patchContent applies patch to data and returns the content to write. When
+// data is not what state was loaded from, the patch only goes ahead if the
+// entries it touches are still as they were loaded.
+func (b *BaseInstaller) patchContent(ctx context.Context, spec DocumentSpec, data []byte, changed bool, state *LoadState, patch ServerPatch) ([]byte, error) {
+	servers, spanned := spanServers(spec, data)
+
+	doc := make(map[string]interface{})
+	if spanned {
+		if servers != nil {
+			parent := ensureServers(doc, spec.KeyPath[:len(spec.KeyPath)-1])
+			parent[spec.KeyPath[len(spec.KeyPath)-1]] = servers
+		}
+	} else {
+		var err error
+		doc, err = b.decodeDocument(ctx, spec, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if changed {
+		slog.WarnContext(ctx, "configuration changed on disk since it was loaded, re-applying changes",
+			slog.String("path", spec.Path))
+
+		if err := checkConflicts(doc, spec.KeyPath, state, patch); err != nil {
+			return nil, err
+		}
+	}
+
+	baseline, err := encodeBaseline(spec, doc)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to encode config", slog.String("error", err.Error()))
+		return nil, errors.ErrConfigInvalid
+	}
+
+	applyPatch(doc, spec.KeyPath, patch)
+
+	encoded, err := spec.Codec.Encode(doc)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to encode config", slog.String("error", err.Error()))
+		return nil, errors.ErrConfigInvalid
+	}
+
+	if err := validateEncoded(spec, baseline, encoded); err != nil {
+		slog.ErrorContext(ctx, "refusing to write config that does not match the client schema",
+			slog.String("path", spec.Path),
+			slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if !spanned {
+		return encoded, nil
+	}
+
+	out, err := spec.Codec.(SpanCodec).Replace(data, spec.KeyPath, lookupServers(doc, spec.KeyPath))
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to encode config", slog.String("error", err.Error()))
+		return nil, errors.ErrConfigInvalid
+	}
+	return out, nil
+}
+
+// writeIfUnchanged atomically replaces path with content, provided the file
+// still matches expected right before the rename.
+func (b *BaseInstaller) writeIfUnchanged(path string, content []byte, expected Snapshot) error {
+	if err := b.ensureDir(path); err != nil {
+		return err
+	}
+
+	err := atomicfile.Write(path, content, atomicfile.Options{
+		Perm: SecretFileMode,
+		BeforeRename: func() error {
+			same, err := b.sameContent(path, expected)
+			if err != nil {
+				return err
+			}
+			if same {
+				return nil
+			}
+			return errors.ErrConfigModified
+		},
+	})
+	if err == nil {
+		return nil
+	}
+
+	if stderrors.Is(err, errors.ErrConfigModified) {
+		return err
+	}
+	if stderrors.Is(err, os.ErrPermission) {
+		return errors.ErrPermissionDenied
+	}
+
+	slog.Error("failed to write file", slog.String("error", err.Error()), slog.String("path", path))
+	return errors.ErrConfigWriteFailed
+}
+
+// verifyWrite reads back the file just written from out and compares it with
+// the previous content: everything but the patched server entries must be
+// semantically identical. On any other difference the previous content is
+// put back.
+//
+// A file that no longer holds out was rewritten by another process after the
+// rename; it is left alone rather than blamed on this write.
+func (b *BaseInstaller) verifyWrite(ctx context.Context, spec DocumentSpec, previous []byte, existed bool, out []byte, patch ServerPatch) error {
+	written, err := os.ReadFile(spec.Path)
+	if err == nil && !bytes.Equal(written, out) {
+		slog.WarnContext(ctx, "configuration changed right after saving, skipping verification",
+			slog.String("path", spec.Path))
+		return nil
+	}
+
+	var changes []string
+	if err != nil {
+		err = fmt.Errorf("failed to read back: %w", err)
+	} else {
+		changes, err = writtenChanges(spec, previous, written, patch)
+	}
+	if err == nil && len(changes) == 0 {
+		b.rememberWrite(spec.Path, written)
+		return nil
+	}
+	if err != nil {
+		changes = []string{"$ (" + err.Error() + ")"}
+	}
+
+	integrityErr := &installer.IntegrityError{Path: spec.Path, Changes: changes}
+	integrityErr.RestoreErr = b.restoreContent(spec.Path, written, previous, existed)
+
+	slog.ErrorContext(ctx, "write changed configuration outside the targeted servers",
+		slog.String("path", spec.Path),
+		slog.Any("changes", changes),
+		slog.Bool("restored", integrityErr.RestoreErr == nil))
+
+	return integrityErr
+}
+
+func writtenChanges(spec DocumentSpec, previous, written []byte, patch ServerPatch) ([]string, error) {
+	targets := make(map[string]bool)
+	for _, name := range patch.Names() {
+		targets[name] = true
+	}
+
+	if changes, ok := spanChanges(spec, previous, written, targets); ok {
+		return changes, nil
+	}
+
+	before, err := parseDocument(spec, previous)
+	if err != nil {
+		return nil, err
+	}
+	after, err := parseDocument(spec, written)
+	if err != nil {
+		return nil, fmt.Errorf("written file does not parse: %w", err)
+	}
+
+	return untouchedChanges(before, after, spec.KeyPath, targets, "$"), nil
+}
+
+// spanChanges compares previous and written without decoding them, when the
+// server map is the only part that differs byte for byte. Only the two maps
+// are decoded then. Any other outcome is left to the full comparison, which
+// can name what changed.
+func spanChanges(spec DocumentSpec, previous, written []byte, targets map[string]bool) ([]string, bool) {
+	codec, ok := spec.Codec.(SpanCodec)
+	if !ok || len(spec.KeyPath) == 0 {
+		return nil, false
+	}
+
+	before, beforeFound, err := codec.Locate(previous, spec.KeyPath)
+	if err != nil || !beforeFound {
+		return nil, false
+	}
+	after, afterFound, err := codec.Locate(written, spec.KeyPath)
+	if err != nil || !afterFound {
+		return nil, false
+	}
+
+	if !bytes.Equal(previous[:before.Start], written[:after.Start]) || !bytes.Equal(previous[before.End:], written[after.End:]) {
+		return nil, false
+	}
+
+	beforeServers, err := codec.Decode(previous[before.Start:before.End])
+	if err != nil {
+		return nil, false
+	}
+	afterServers, err := codec.Decode(written[after.Start:after.End])
+	if err != nil {
+		return nil, false
+	}
+
+	path := "$"
+	for _, key := range spec.KeyPath {
+		path = jsonPath(path, key)
+	}
+	return entryChanges(beforeServers, afterServers, targets, path), true
+}
+
+// untouchedChanges lists the paths where after differs from before, leaving
+// out the target entries of the server map found at keyPath.
+func untouchedChanges(before, after map[string]interface{}, keyPath []string, targets map[string]bool, path string) []string {
+	var changes []string
+	for _, key := range unionKeys(before, after) {
+		childPath := jsonPath(path, key)
+		beforeValue, beforeOK := before[key]
+		afterValue, afterOK := after[key]
+
+		if len(keyPath) > 0 && key == keyPath[0] {
+			beforeMap, beforeIsMap := beforeValue.(map[string]interface{})
+			afterMap, afterIsMap := afterValue.(map[string]interface{})
+			if (beforeIsMap || !beforeOK) && afterIsMap {
+				if len(keyPath) == 1 {
+					changes = append(changes, entryChanges(beforeMap, afterMap, targets, childPath)...)
+				} else {
+					changes = append(changes, untouchedChanges(beforeMap, afterMap, keyPath[1:], targets, childPath)...)
+				}
+				continue
+			}
+		}
+
+		if beforeOK != afterOK || !reflect.DeepEqual(beforeValue, afterValue) {
+			changes = append(changes, childPath)
+		}
+	}
+	return changes
+}
+
+func entryChanges(before, after map[string]interface{}, targets map[string]bool, path string) []string {
+	var changes []string
+	for _, name := range unionKeys(before, after) {
+		if targets[name] {
+			continue
+		}
+		beforeValue, beforeOK := before[name]
+		afterValue, afterOK := after[name]
+		if beforeOK != afterOK || !reflect.DeepEqual(beforeValue, afterValue) {
+			changes = append(changes, jsonPath(path, name))
+		}
+	}
+	return changes
+}
+
+func unionKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var plainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func jsonPath(parent, key string) string {
+	if plainKey.MatchString(key) {
+		return parent + "." + key
+	}
+	return parent + "[" + strconv.Quote(key) + "]"
+}
+
+// restoreContent puts back the content a file had before it was written, or
+// removes it if it did not exist, provided the file still holds written. A
+// file another process rewrote since is left alone.
+func (b *BaseInstaller) restoreContent(path string, written, content []byte, existed bool) error {
+	current, snapshot, err := b.TakeSnapshot(path)
+	if err != nil {
+		return err
+	}
+	if !snapshot.Exists || !bytes.Equal(current, written) {
+		return errors.ErrConfigModified
+	}
+
+	if !existed {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return b.writeIfUnchanged(path, content, snapshot)
+}
+
+func (b *BaseInstaller) decodeDocument(ctx context.Context, spec DocumentSpec, data []byte) (map[string]interface{}, error) {
+	doc, err := parseDocument(spec, data)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to parse config",
+			slog.String("error", err.Error()),
+			slog.String("path", spec.Path))
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+func parseDocument(spec DocumentSpec, data []byte) (map[string]interface{}, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return make(map[string]interface{}), nil
+	}
+
+	doc, err := spec.Codec.Decode(data)
+	if err != nil {
+		var parseErr *installer.ParseError
+		if !stderrors.As(err, &parseErr) {
+			parseErr = &installer.ParseError{Message: err.Error()}
+		}
+		parseErr.Path = spec.Path
+		return nil, parseErr
+	}
+
+	if doc == nil {
+		doc = make(map[string]interface{})
+	}
+
+	return doc, nil
+}
+
+// ParseDocument checks that data is a valid configuration for spec.
+func (b *BaseInstaller) ParseDocument(spec DocumentSpec, data []byte) error {
+	_, err := parseDocument(spec, data)
+	return err
+}
+
+// RepairDocument applies the safe fixes of the spec's codec to data. The
+// error tells whether the fixed content still fails to parse.
+func (b *BaseInstaller) RepairDocument(spec DocumentSpec, data []byte) ([]byte, []string, error) {
+	fixed, fixes := spec.Codec.Repair(data)
+	return fixed, fixes, b.ParseDocument(spec, fixed)
+}
+
+// ValidateDocument checks the server map state would hold once patch is
+// applied against the spec's schema.
+func (b *BaseInstaller) ValidateDocument(spec DocumentSpec, state *LoadState, patch ServerPatch) error {
+	if spec.Schema == nil {
+		return nil
+	}
+
+	servers := make(map[string]interface{}, len(state.Servers))
+	for name, server := range state.Servers {
+		servers[name] = server
+	}
+
+	doc := make(map[string]interface{})
+	if len(spec.KeyPath) > 0 {
+		parent := ensureServers(doc, spec.KeyPath[:len(spec.KeyPath)-1])
+		parent[spec.KeyPath[len(spec.KeyPath)-1]] = servers
+	}
+
+	baseline, err := encodeBaseline(spec, doc)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrConfigInvalid, err)
+	}
+
+	applyPatch(doc, spec.KeyPath, patch)
+
+	out, err := spec.Codec.Encode(doc)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrConfigInvalid, err)
+	}
+
+	return validateEncoded(spec, baseline, out)
+}
+
+// encodeBaseline encodes doc before it is patched, for validateEncoded to
+// tell the violations the patch introduces.
+func encodeBaseline(spec DocumentSpec, doc map[string]interface{}) ([]byte, error) {
+	if spec.Schema == nil {
+		return nil, nil
+	}
+	return spec.Codec.Encode(doc)
+}
+
+// validateEncoded decodes out again, as the client will, and checks it
+// against the spec's schema. Violations already in baseline, the document
+// before the patch, are ignored: entries the installer does not touch must
+// not block every change to the file.
+func validateEncoded(spec DocumentSpec, baseline, out []byte) error {
+	if spec.Schema == nil {
+		return nil
+	}
+
+	doc, err := parseDocument(spec, out)
+	if err != nil {
+		return err
+	}
+
+	violations := spec.Schema.Validate(doc)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	existing := make(map[jsonschema.Violation]bool)
+	if before, err := parseDocument(spec, baseline); err == nil {
+		for _, violation := range spec.Schema.Validate(before) {
+			existing[violation] = true
+		}
+	}
+
+	schemaErr := &installer.SchemaError{Path: spec.Path}
+	for _, violation := range violations {
+		if existing[violation] {
+			continue
+		}
+		schemaErr.Violations = append(schemaErr.Violations, installer.SchemaViolation{Path: violation.Path, Message: violation.Message})
+	}
+	if len(schemaErr.Violations) == 0 {
+		return nil
+	}
+	return schemaErr
+}
+
+func checkConflicts(doc map[string]interface{}, keyPath []string, state *LoadState, patch ServerPatch) error {
+	current := lookupServers(doc, keyPath)
+
+	for _, name := range patch.Names() {
+		before, hadBefore := state.Servers[name]
+		now, hasNow := current[name]
+
+		if hadBefore != hasNow || !reflect.DeepEqual(before, now) {
+			return fmt.Errorf("%w: server %q was changed by another process", errors.ErrConfigModified, name)
+		}
+	}
+
+	return nil
+}
+
+func applyPatch(doc map[string]interface{}, keyPath []string, patch ServerPatch) {
+	servers := ensureServers(doc, keyPath)
+
+	for name, server := range patch.Upsert {
+		servers[name] = server
+	}
+
+	for _, name := range patch.Remove {
+		delete(servers, name)
+	}
+}
+
+func lookupServers(doc map[string]interface{}, keyPath []string) map[string]interface{} {
+	current := doc
+	for _, key := range keyPath {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = next
+	}
+	return current
+}
+
+func ensureServers(doc map[string]interface{}, keyPath []string) map[string]interface{} {
+	current := doc
+	for _, key := range keyPath {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			current[key] = next
+		}
+		current = next
+	}
+	return current
+}
@@ -0,0 +1,454 @@
+package installers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	domainErrors "go.kirha.ai/mcp-installer/internal/core/domain/errors"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/pkg/jsonschema"
+)
+
+func newTestSpec(t *testing.T, content string) DocumentSpec {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	return DocumentSpec{
+		Path:    path,
+		Codec:   JSONCodec{},
+		KeyPath: []string{"mcpServers"},
+	}
+}
+
+func TestSaveDocument_ReappliesPatchOnConcurrentChange(t *testing.T) {
+	ctx := context.Background()
+	base := NewBaseInstaller()
+	spec := newTestSpec(t, `{"mcpServers": {"other": {"command": "node"}}}`)
+
+	state, err := base.LoadDocument(ctx, spec)
+	if err != nil {
+		t.Fatalf("LoadDocument() error = %v", err)
+	}
+
+	// The client rewrites its config between our load and save.
+	concurrent := `{"mcpServers": {"other": {"command": "node"}, "added": {"command": "deno"}}, "theme": "dark"}`
+	if err := os.WriteFile(spec.Path, []byte(concurrent), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	state.MarkChanged("kirha")
+	patch := BuildPatch(state, map[string]interface{}{"kirha": map[string]interface{}{"url": "https://mcp.kirha.com"}})
+
+	if err := base.SaveDocument(ctx, spec, state, patch); err != nil {
+		t.Fatalf("SaveDocument() error = %v, want nil", err)
+	}
+
+	saved, err := base.LoadDocument(ctx, spec)
+	if err != nil {
+		t.Fatalf("LoadDocument() error = %v", err)
+	}
+
+	for _, name := range []string{"other", "added", "kirha"} {
+		if _, ok := saved.Servers[name]; !ok {
+			t.Errorf("SaveDocument() lost server %q", name)
+		}
+	}
+}
+
+func TestSaveDocument_AbortsWhenPatchedEntryChanged(t *testing.T) {
+	ctx := context.Background()
+	base := NewBaseInstaller()
+	spec := newTestSpec(t, `{"mcpServers": {"kirha": {"url": "https://old.example"}}}`)
+
+	state, err := base.LoadDocument(ctx, spec)
+	if err != nil {
+		t.Fatalf("LoadDocument() error = %v", err)
+	}
+
+	concurrent := `{"mcpServers": {"kirha": {"url": "https://edited.example"}}}`
+	if err := os.WriteFile(spec.Path, []byte(concurrent), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	state.MarkChanged("kirha")
+	patch := BuildPatch(state, map[string]interface{}{})
+
+	err = base.SaveDocument(ctx, spec, state, patch)
+	if !errors.Is(err, domainErrors.ErrConfigModified) {
+		t.Fatalf("SaveDocument() error = %v, want %v", err, domainErrors.ErrConfigModified)
+	}
+
+	data, _ := os.ReadFile(spec.Path)
+	if string(data) != concurrent {
+		t.Errorf("SaveDocument() overwrote concurrent change: %s", data)
+	}
+}
+
+func TestLoadDocument_ReportsParseErrorLocation(t *testing.T) {
+	tests := []struct {
+		name    string
+		codec   Codec
+		content string
+		line    int
+		column  int
+	}{
+		{"json syntax", JSONCodec{}, "{\n  \"mcpServers\": {\n    \"kirha\": {]\n  }\n}", 3, 15},
+		{"json truncated", JSONCodec{}, "{\n  \"mcpServers\": {", 2, 18},
+		{"json syntax outside servers", JSONCodec{}, "{\n  \"theme\": {\"a\": tru},\n  \"mcpServers\": {}\n}", 2, 21},
+		{"toml syntax", TOMLCodec{}, "[mcp_servers.kirha]\nurl = \n", 2, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := newTestSpec(t, tt.content)
+			spec.Codec = tt.codec
+
+			_, err := NewBaseInstaller().LoadDocument(context.Background(), spec)
+			if !errors.Is(err, domainErrors.ErrConfigInvalid) {
+				t.Fatalf("LoadDocument() error = %v, want ErrConfigInvalid", err)
+			}
+
+			var parseErr *installer.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("LoadDocument() error = %T, want *installer.ParseError", err)
+			}
+			if parseErr.Path != spec.Path || parseErr.Line != tt.line || parseErr.Column != tt.column {
+				t.Errorf("ParseError at %s:%d:%d, want %s:%d:%d", parseErr.Path, parseErr.Line, parseErr.Column, spec.Path, tt.line, tt.column)
+			}
+		})
+	}
+}
+
+func TestRepairDocument_FixesTrailingCommaAndBOM(t *testing.T) {
+	spec := newTestSpec(t, "")
+	data := []byte("\xEF\xBB\xBF{\"mcpServers\": {\"kirha\": {\"type\": \"http\",},},}")
+
+	if err := NewBaseInstaller().ParseDocument(spec, data); err == nil {
+		t.Fatal("ParseDocument() error = nil, want a parse error")
+	}
+
+	fixed, fixes, err := NewBaseInstaller().RepairDocument(spec, data)
+	if err != nil {
+		t.Fatalf("RepairDocument() error = %v", err)
+	}
+	if len(fixes) != 4 {
+		t.Errorf("RepairDocument() fixes = %v, want 4", fixes)
+	}
+	if want := `{"mcpServers": {"kirha": {"type": "http"}}}`; string(fixed) != want {
+		t.Errorf("RepairDocument() = %s, want %s", fixed, want)
+	}
+}
+
+func TestSaveDocument_RefusesSchemaViolation(t *testing.T) {
+	ctx := context.Background()
+	base := NewBaseInstaller()
+	original := `{"mcpServers": {"other": {"command": "node"}}}`
+	spec := newTestSpec(t, original)
+	spec.Schema = jsonschema.MustCompile([]byte(`{
+	  "properties": {
+	    "mcpServers": {
+	      "additionalProperties": {
+	        "properties": {
+	          "url": {"type": "string", "pattern": "^https?://"},
+	          "headers": {"type": "object", "additionalProperties": {"type": "string"}}
+	        },
+	        "anyOf": [{"required": ["url"]}, {"required": ["command"]}]
+	      }
+	    }
+	  }
+	}`))
+
+	state, err := base.LoadDocument(ctx, spec)
+	if err != nil {
+		t.Fatalf("LoadDocument() error = %v", err)
+	}
+
+	state.MarkChanged("kirha")
+	patch := BuildPatch(state, map[string]interface{}{
+		"kirha": map[string]interface{}{"url": "mcp.kirha.com", "headers": map[string]interface{}{"Authorization": nil}},
+	})
+
+	if err := base.ValidateDocument(spec, state, patch); !errors.Is(err, domainErrors.ErrConfigInvalid) {
+		t.Errorf("ValidateDocument() error = %v, want ErrConfigInvalid", err)
+	}
+
+	err = base.SaveDocument(ctx, spec, state, patch)
+	var schemaErr *installer.SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("SaveDocument() error = %v, want *installer.SchemaError", err)
+	}
+
+	want := []installer.SchemaViolation{
+		{Path: "$.mcpServers.kirha.headers.Authorization", Message: "must be a string, got null"},
+		{Path: "$.mcpServers.kirha.url", Message: "must match ^https?://"},
+	}
+	if len(schemaErr.Violations) != len(want) {
+		t.Fatalf("violations = %v, want %v", schemaErr.Violations, want)
+	}
+	for i, violation := range schemaErr.Violations {
+		if violation != want[i] {
+			t.Errorf("violation %d = %v, want %v", i, violation, want[i])
+		}
+	}
+
+	content, err := os.ReadFile(spec.Path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(content) != original {
+		t.Errorf("SaveDocument() wrote %s despite the violation", content)
+	}
+}
+
+func TestSaveDocument_IgnoresExistingSchemaViolations(t *testing.T) {
+	ctx := context.Background()
+	base := NewBaseInstaller()
+	spec := newTestSpec(t, `{"mcpServers": {"legacy": {"type": "streamable", "url": "localhost:3000"}}}`)
+	spec.Schema = jsonschema.MustCompile([]byte(`{
+	  "properties": {
+	    "mcpServers": {
+	      "additionalProperties": {
+	        "properties": {
+	          "type": {"enum": ["stdio", "http", "sse"]},
+	          "url": {"type": "string", "pattern": "^https?://"}
+	        }
+	      }
+	    }
+	  }
+	}`))
+
+	state, err := base.LoadDocument(ctx, spec)
+	if err != nil {
+		t.Fatalf("LoadDocument() error = %v", err)
+	}
+
+	state.MarkChanged("kirha")
+	patch := BuildPatch(state, map[string]interface{}{
+		"kirha": map[string]interface{}{"type": "http", "url": "https://mcp.kirha.com"},
+	})
+
+	if err := base.ValidateDocument(spec, state, patch); err != nil {
+		t.Errorf("ValidateDocument() error = %v, want nil", err)
+	}
+	if err := base.SaveDocument(ctx, spec, state, patch); err != nil {
+		t.Fatalf("SaveDocument() error = %v, want the untouched legacy entry ignored", err)
+	}
+}
+
+// lossyCodec drops a top-level key when encoding, as a buggy encoder might.
+type lossyCodec struct {
+	Codec
+	drop string
+}
+
+func (c lossyCodec) Encode(doc map[string]interface{}) ([]byte, error) {
+	delete(doc, c.drop)
+	return c.Codec.Encode(doc)
+}
+
+// lossySpanCodec drops a top-level key when splicing in the server map.
+type lossySpanCodec struct {
+	JSONCodec
+	drop string
+}
+
+func (c lossySpanCodec) Replace(data []byte, keyPath []string, value interface{}) ([]byte, error) {
+	out, err := c.JSONCodec.Replace(data, keyPath, value)
+	return bytes.Replace(out, []byte(`"`+c.drop+`": "dark", `), nil, 1), err
+}
+
+func TestSaveDocument_RestoresOnUnrelatedChange(t *testing.T) {
+	codecs := map[string]Codec{
+		"encode": lossyCodec{Codec: JSONCodec{}, drop: "theme"},
+		"span":   lossySpanCodec{drop: "theme"},
+	}
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			base := NewBaseInstaller()
+			original := `{"theme": "dark", "mcpServers": {"other": {"command": "node"}}}`
+			spec := newTestSpec(t, original)
+			spec.Codec = codec
+
+			state, err := base.LoadDocument(ctx, spec)
+			if err != nil {
+				t.Fatalf("LoadDocument() error = %v", err)
+			}
+
+			state.MarkChanged("kirha")
+			patch := BuildPatch(state, map[string]interface{}{"kirha": map[string]interface{}{"url": "https://mcp.kirha.com"}})
+
+			err = base.SaveDocument(ctx, spec, state, patch)
+			var integrityErr *installer.IntegrityError
+			if !errors.As(err, &integrityErr) || !errors.Is(err, domainErrors.ErrUnexpectedChange) {
+				t.Fatalf("SaveDocument() error = %v, want *installer.IntegrityError", err)
+			}
+			if len(integrityErr.Changes) != 1 || integrityErr.Changes[0] != "$.theme" {
+				t.Errorf("changes = %v, want [$.theme]", integrityErr.Changes)
+			}
+
+			content, err := os.ReadFile(spec.Path)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if string(content) != original {
+				t.Errorf("content after failed save = %s, want the original restored", content)
+			}
+		})
+	}
+}
+
+// racingCodec rewrites the file with concurrent once the written content,
+// which lacks the dropped key, is decoded to be checked.
+type racingCodec struct {
+	lossyCodec
+	path       string
+	concurrent string
+	raced      *bool
+}
+
+func (c racingCodec) Decode(data []byte) (map[string]interface{}, error) {
+	if !*c.raced && !bytes.Contains(data, []byte(c.drop)) {
+		*c.raced = true
+		if err := os.WriteFile(c.path, []byte(c.concurrent), 0600); err != nil {
+			return nil, err
+		}
+	}
+	return c.lossyCodec.Decode(data)
+}
+
+func TestSaveDocument_KeepsConcurrentWriteWhenRestoring(t *testing.T) {
+	ctx := context.Background()
+	base := NewBaseInstaller()
+	original := `{"theme": "dark", "mcpServers": {}}`
+	concurrent := `{"theme": "light", "mcpServers": {}}`
+	spec := newTestSpec(t, original)
+	spec.Codec = racingCodec{
+		lossyCodec: lossyCodec{Codec: JSONCodec{}, drop: "theme"},
+		path:       spec.Path,
+		concurrent: concurrent,
+		raced:      new(bool),
+	}
+
+	state, err := base.LoadDocument(ctx, spec)
+	if err != nil {
+		t.Fatalf("LoadDocument() error = %v", err)
+	}
+
+	state.MarkChanged("kirha")
+	patch := BuildPatch(state, map[string]interface{}{"kirha": map[string]interface{}{"url": "https://mcp.kirha.com"}})
+
+	err = base.SaveDocument(ctx, spec, state, patch)
+	var integrityErr *installer.IntegrityError
+	if !errors.As(err, &integrityErr) {
+		t.Fatalf("SaveDocument() error = %v, want *installer.IntegrityError", err)
+	}
+	if !errors.Is(integrityErr.RestoreErr, domainErrors.ErrConfigModified) {
+		t.Errorf("RestoreErr = %v, want %v", integrityErr.RestoreErr, domainErrors.ErrConfigModified)
+	}
+
+	content, err := os.ReadFile(spec.Path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(content) != concurrent {
+		t.Errorf("content after failed save = %s, want the concurrent write kept", content)
+	}
+}
+
+func TestSaveDocument_SplicesServerMap(t *testing.T) {
+	ctx := context.Background()
+	base := NewBaseInstaller()
+	original := "{\n    \"tips\":[1,2],   \"theme\": \"dark\",\n    \"mcpServers\": {\"other\": {\"command\": \"node\"}},\n    \"zeta\": {\"b\": 1, \"a\": 2}\n}\n"
+	spec := newTestSpec(t, original)
+
+	state, err := base.LoadDocument(ctx, spec)
+	if err != nil {
+		t.Fatalf("LoadDocument() error = %v", err)
+	}
+
+	state.MarkChanged("kirha")
+	patch := BuildPatch(state, map[string]interface{}{"kirha": map[string]interface{}{"url": "https://mcp.kirha.com"}})
+	if err := base.SaveDocument(ctx, spec, state, patch); err != nil {
+		t.Fatalf("SaveDocument() error = %v", err)
+	}
+
+	content, err := os.ReadFile(spec.Path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	want := "{\n    \"tips\":[1,2],   \"theme\": \"dark\",\n    \"mcpServers\": {\n        \"kirha\": {\n            \"url\": \"https://mcp.kirha.com\"\n        },\n        \"other\": {\n            \"command\": \"node\"\n        }\n    },\n    \"zeta\": {\"b\": 1, \"a\": 2}\n}\n"
+	if string(content) != want {
+		t.Errorf("SaveDocument() wrote\n%s\nwant\n%s", content, want)
+	}
+}
+
+// BenchmarkInstall loads a configuration, adds a server and loads it again,
+// as an install does, on files with a growing project history. The span path
+// only decodes the server map; the decode path hides the SpanCodec to decode
+// everything, as before.
+func BenchmarkInstall(b *testing.B) {
+	for _, size := range []int{1 << 20, 8 << 20, 32 << 20} {
+		content := largeConfig(size)
+
+		for _, codec := range []struct {
+			name  string
+			codec Codec
+		}{
+			{name: "span", codec: JSONCodec{}},
+			{name: "decode", codec: struct{ Codec }{JSONCodec{}}},
+		} {
+			b.Run(fmt.Sprintf("%dMB/%s", size>>20, codec.name), func(b *testing.B) {
+				ctx := context.Background()
+				path := filepath.Join(b.TempDir(), "claude.json")
+				spec := DocumentSpec{Path: path, Codec: codec.codec, KeyPath: []string{"mcpServers"}}
+
+				b.SetBytes(int64(len(content)))
+				for i := 0; i < b.N; i++ {
+					b.StopTimer()
+					if err := os.WriteFile(path, content, 0600); err != nil {
+						b.Fatal(err)
+					}
+					base := NewBaseInstaller()
+					b.StartTimer()
+
+					state, err := base.LoadDocument(ctx, spec)
+					if err != nil {
+						b.Fatal(err)
+					}
+					state.MarkChanged("kirha")
+					patch := BuildPatch(state, map[string]interface{}{"kirha": map[string]interface{}{"type": "http", "url": "https://mcp.kirha.com"}})
+					if err := base.SaveDocument(ctx, spec, state, patch); err != nil {
+						b.Fatal(err)
+					}
+					if _, err := base.LoadDocument(ctx, spec); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func largeConfig(size int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("{\n  \"numStartups\": 412,\n  \"projects\": {\n")
+	for i := 0; buf.Len() < size; i++ {
+		if i > 0 {
+			buf.WriteString(",\n")
+		}
+		fmt.Fprintf(&buf, `    "/home/user/project-%d": {"history": [{"display": "fix the \"parser\" {edge} case %d", "pastedContents": {}}], "allowedTools": []}`, i, i)
+	}
+	buf.WriteString("\n  },\n  \"mcpServers\": {\n    \"other\": {\n      \"command\": \"node\"\n    }\n  }\n}\n")
+	return buf.Bytes()
+}
@@ -2,6 +2,7 @@ package droid
 
 import (
 	"context"
+	_ "embed"
 	"fmt"
 	"log/slog"
 	"os/exec"
@@ -12,23 +13,37 @@ import (
 	"go.kirha.ai/mcp-installer/internal/adapters/installers"
 	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
 	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/pkg/jsonschema"
 	"go.kirha.ai/mcp-installer/pkg/security"
 )
 
 const (
+	binaryName     = "droid"
 	configFileName = "mcp.json"
 	configDir      = ".factory"
 	mcpKey         = "mcpServers"
 )
 
+// schemaJSON describes the MCP server entries the client accepts.
+//
+//go:embed schema.json
+var schemaJSON []byte
+
+var schema = jsonschema.MustCompile(schemaJSON)
+
 type DroidConfig struct {
 	McpServers map[string]McpServerConfig `json:"mcpServers,omitempty"`
+
+	installers.LoadState `json:"-"`
 }
 
 type McpServerConfig struct {
 	Type     string            `json:"type"`
-	URL      string            `json:"url"`
+	URL      string            `json:"url,omitempty"`
 	Headers  map[string]string `json:"headers,omitempty"`
+	Command  string            `json:"command,omitempty"`
+	Args     []string          `json:"args,omitempty"`
+	Env      map[string]string `json:"env,omitempty"`
 	Disabled bool              `json:"disabled,omitempty"`
 }
 
@@ -51,60 +66,103 @@ func (i *Installer) GetConfigPath() (string, error) {
 	return filepath.Join(home, configDir, configFileName), nil
 }
 
-func (i *Installer) LoadConfig(ctx context.Context) (interface{}, error) {
+func (i *Installer) GetBinaryPath() (string, error) {
+	return i.LookupBinary(binaryName)
+}
+
+func (i *Installer) documentSpec() (installers.DocumentSpec, error) {
 	path, err := i.GetConfigPath()
 	if err != nil {
-		return nil, err
+		return installers.DocumentSpec{}, err
 	}
 
-	if !i.FileExists(path) {
-		slog.InfoContext(ctx, "config file not found, creating new one", slog.String("path", path))
-		return &DroidConfig{
-			McpServers: make(map[string]McpServerConfig),
-		}, nil
+	return installers.DocumentSpec{
+		Path:    path,
+		Codec:   installers.JSONCodec{},
+		KeyPath: []string{mcpKey},
+		Schema:  schema,
+	}, nil
+}
+
+func (i *Installer) LoadConfig(ctx context.Context) (interface{}, error) {
+	spec, err := i.documentSpec()
+	if err != nil {
+		return nil, err
 	}
 
-	data, err := i.LoadJSONConfig(ctx, path)
+	state, err := i.LoadDocument(ctx, spec)
 	if err != nil {
 		return nil, err
 	}
 
+	if !state.Snapshot.Exists {
+		slog.InfoContext(ctx, "config file not found, creating new one", slog.String("path", spec.Path))
+	}
+
+	return i.newConfig(state), nil
+}
+
+// newConfig converts the server entries of a loaded document.
+func (i *Installer) newConfig(state *installers.LoadState) *DroidConfig {
 	config := &DroidConfig{
 		McpServers: make(map[string]McpServerConfig),
+		LoadState:  *state,
+	}
+
+	for name, serverData := range state.Servers {
+		if serverMap, ok := serverData.(map[string]interface{}); ok {
+			mcpServer := McpServerConfig{
+				Type:    installers.StringValue(serverMap, "type"),
+				URL:     installers.StringValue(serverMap, "url"),
+				Headers: installers.StringMap(serverMap["headers"]),
+				Command: installers.StringValue(serverMap, "command"),
+				Args:    installers.StringSlice(serverMap["args"]),
+				Env:     installers.StringMap(serverMap["env"]),
+			}
+
+			if disabled, ok := serverMap["disabled"].(bool); ok {
+				mcpServer.Disabled = disabled
+			}
+
+			config.McpServers[name] = mcpServer
+		}
 	}
 
-	if servers, ok := data[mcpKey].(map[string]interface{}); ok {
-		for name, serverData := range servers {
-			if serverMap, ok := serverData.(map[string]interface{}); ok {
-				mcpServer := McpServerConfig{}
+	return config
+}
 
-				if serverType, ok := serverMap["type"].(string); ok {
-					mcpServer.Type = serverType
-				}
+// ParseServers returns the MCP servers of configuration content that is not
+// read from disk, such as a backup.
+func (i *Installer) ParseServers(ctx context.Context, data []byte) ([]*installer.McpServer, error) {
+	spec, err := i.documentSpec()
+	if err != nil {
+		return nil, err
+	}
 
-				if url, ok := serverMap["url"].(string); ok {
-					mcpServer.URL = url
-				}
+	state, err := i.DecodeDocument(ctx, spec, data)
+	if err != nil {
+		return nil, err
+	}
 
-				if disabled, ok := serverMap["disabled"].(bool); ok {
-					mcpServer.Disabled = disabled
-				}
+	return i.ListMcpServers(ctx, i.newConfig(state))
+}
 
-				if headers, ok := serverMap["headers"].(map[string]interface{}); ok {
-					mcpServer.Headers = make(map[string]string)
-					for k, v := range headers {
-						if vStr, ok := v.(string); ok {
-							mcpServer.Headers[k] = vStr
-						}
-					}
-				}
+func (i *Installer) ParseConfig(data []byte) error {
+	spec, err := i.documentSpec()
+	if err != nil {
+		return err
+	}
 
-				config.McpServers[name] = mcpServer
-			}
-		}
+	return i.ParseDocument(spec, data)
+}
+
+func (i *Installer) RepairConfig(data []byte) ([]byte, []string, error) {
+	spec, err := i.documentSpec()
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return config, nil
+	return i.RepairDocument(spec, data)
 }
 
 func (i *Installer) AddMcpServer(ctx context.Context, config interface{}, server *installer.McpServer) (interface{}, error) {
@@ -117,11 +175,8 @@ func (i *Installer) AddMcpServer(ctx context.Context, config interface{}, server
 		return nil, errors.ErrServerAlreadyExists
 	}
 
-	droidConfig.McpServers[server.Name] = McpServerConfig{
-		Type:    server.Type,
-		URL:     server.URL,
-		Headers: server.Headers,
-	}
+	droidConfig.McpServers[server.Name] = fromMcpServer(server)
+	droidConfig.MarkChanged(server.Name)
 
 	slog.InfoContext(ctx, "added MCP server to configuration",
 		slog.String("server", server.Name))
@@ -141,6 +196,7 @@ func (i *Installer) RemoveMcpServer(ctx context.Context, config interface{}) (in
 	}
 
 	delete(droidConfig.McpServers, serverName)
+	droidConfig.MarkChanged(serverName)
 
 	slog.InfoContext(ctx, "removed MCP server from configuration",
 		slog.String("server", serverName))
@@ -154,59 +210,36 @@ func (i *Installer) SaveConfig(ctx context.Context, config interface{}) error {
 		return errors.ErrConfigInvalid
 	}
 
-	path, err := i.GetConfigPath()
+	spec, err := i.documentSpec()
 	if err != nil {
 		return err
 	}
 
-	data := map[string]interface{}{
-		mcpKey: droidConfig.McpServers,
-	}
-
-	if originalData, err := i.LoadJSONConfig(ctx, path); err == nil {
-		for k, v := range originalData {
-			if k != mcpKey {
-				data[k] = v
-			}
-		}
-	}
-
-	return i.SaveJSONConfig(ctx, path, data)
+	patch := installers.BuildPatch(&droidConfig.LoadState, droidConfig.McpServers)
+	return i.SaveDocument(ctx, spec, &droidConfig.LoadState, patch)
 }
 
-func (i *Installer) BackupConfig(ctx context.Context) (string, error) {
+func (i *Installer) DeleteConfig(ctx context.Context) error {
 	path, err := i.GetConfigPath()
 	if err != nil {
-		return "", err
-	}
-
-	if !i.FileExists(path) {
-		slog.InfoContext(ctx, "no existing config to backup")
-		return "", nil
+		return err
 	}
 
-	return i.CreateBackup(path)
+	return i.RemoveFile(path)
 }
 
-func (i *Installer) RestoreConfig(ctx context.Context, backupPath string) error {
-	if backupPath == "" {
-		return nil
+func (i *Installer) ValidateConfig(ctx context.Context, config interface{}) error {
+	droidConfig, ok := config.(*DroidConfig)
+	if !ok {
+		return errors.ErrConfigInvalid
 	}
 
-	path, err := i.GetConfigPath()
+	spec, err := i.documentSpec()
 	if err != nil {
 		return err
 	}
 
-	return i.RestoreBackup(backupPath, path)
-}
-
-func (i *Installer) ValidateConfig(ctx context.Context, config interface{}) error {
-	_, ok := config.(*DroidConfig)
-	if !ok {
-		return errors.ErrConfigInvalid
-	}
-	return nil
+	return i.ValidateDocument(spec, &droidConfig.LoadState, installers.BuildPatch(&droidConfig.LoadState, droidConfig.McpServers))
 }
 
 func (i *Installer) IsClientRunning(ctx context.Context) (bool, error) {
@@ -250,12 +283,65 @@ func (i *Installer) GetMcpServerConfig(ctx context.Context, config interface{})
 		return nil, errors.ErrServerNotFound
 	}
 
+	return toMcpServer(serverName, serverConfig), nil
+}
+
+func (i *Installer) ListMcpServers(ctx context.Context, config interface{}) ([]*installer.McpServer, error) {
+	droidConfig, ok := config.(*DroidConfig)
+	if !ok {
+		return nil, errors.ErrConfigInvalid
+	}
+
+	servers := make([]*installer.McpServer, 0, len(droidConfig.McpServers))
+	for name, serverConfig := range droidConfig.McpServers {
+		servers = append(servers, toMcpServer(name, serverConfig))
+	}
+
+	return installers.SortServers(servers), nil
+}
+
+// UnsupportedFields reports what Droid cannot store: it only speaks stdio and
+// streamable HTTP.
+func (i *Installer) UnsupportedFields(server *installer.McpServer) []string {
+	return installers.UnsupportedFields(server,
+		[]string{installer.TransportStdio, installer.TransportHTTP}, true)
+}
+
+func toMcpServer(name string, serverConfig McpServerConfig) *installer.McpServer {
+	serverType := serverConfig.Type
+	if serverType == "" && serverConfig.Command != "" {
+		serverType = installer.TransportStdio
+	}
+
 	return &installer.McpServer{
-		Name:    serverName,
-		Type:    serverConfig.Type,
-		URL:     serverConfig.URL,
-		Headers: serverConfig.Headers,
-	}, nil
+		Name:     name,
+		Type:     serverType,
+		URL:      serverConfig.URL,
+		Headers:  serverConfig.Headers,
+		Command:  serverConfig.Command,
+		Args:     serverConfig.Args,
+		Env:      serverConfig.Env,
+		Disabled: serverConfig.Disabled,
+	}
+}
+
+func fromMcpServer(server *installer.McpServer) McpServerConfig {
+	if server.Type == installer.TransportStdio {
+		return McpServerConfig{
+			Type:     installer.TransportStdio,
+			Command:  server.Command,
+			Args:     server.Args,
+			Env:      server.Env,
+			Disabled: server.Disabled,
+		}
+	}
+
+	return McpServerConfig{
+		Type:     server.Type,
+		URL:      server.URL,
+		Headers:  server.Headers,
+		Disabled: server.Disabled,
+	}
 }
 
 func (i *Installer) FormatConfig(ctx context.Context, config interface{}) (string, error) {
@@ -302,7 +388,11 @@ func (i *Installer) formatServerSection(sectionTitle string, servers map[string]
 	for name, server := range servers {
 		result += fmt.Sprintf("Server: %s\n", name)
 		result += fmt.Sprintf("  Type: %s\n", server.Type)
-		result += fmt.Sprintf("  URL: %s\n", server.URL)
+		if server.Command != "" {
+			result += fmt.Sprintf("  Command: %s\n", strings.Join(append([]string{server.Command}, server.Args...), " "))
+		} else {
+			result += fmt.Sprintf("  URL: %s\n", server.URL)
+		}
 		if server.Disabled {
 			result += "  Disabled: true\n"
 		}
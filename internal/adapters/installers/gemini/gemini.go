@@ -2,6 +2,8 @@ package gemini
 
 import (
 	"context"
+	_ "embed"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os/exec"
@@ -12,25 +14,42 @@ import (
 	"go.kirha.ai/mcp-installer/internal/adapters/installers"
 	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
 	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/pkg/jsonschema"
 	"go.kirha.ai/mcp-installer/pkg/security"
 )
 
 const (
+	binaryName     = "gemini"
 	configFileName = "settings.json"
 	configDir      = ".gemini"
 	mcpKey         = "mcpServers"
+
+	contentTypeHeader = "Content-Type"
+	contentTypeJSON   = "application/json"
 )
 
+// schemaJSON describes the MCP server entries the client accepts.
+//
+//go:embed schema.json
+var schemaJSON []byte
+
+var schema = jsonschema.MustCompile(schemaJSON)
+
 type GeminiConfig struct {
 	McpServers map[string]McpServerConfig `json:"mcpServers,omitempty"`
+
+	installers.LoadState `json:"-"`
 }
 
 type McpServerConfig struct {
 	// Gemini CLI consolidated format: url + type
-	URL     string            `json:"url"`
+	URL     string            `json:"url,omitempty"`
 	Type    string            `json:"type,omitempty"`
 	Headers map[string]string `json:"headers,omitempty"`
 	Timeout int               `json:"timeout,omitempty"`
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
 }
 
 type Installer struct {
@@ -52,60 +71,105 @@ func (i *Installer) GetConfigPath() (string, error) {
 	return filepath.Join(home, configDir, configFileName), nil
 }
 
-func (i *Installer) LoadConfig(ctx context.Context) (interface{}, error) {
+func (i *Installer) GetBinaryPath() (string, error) {
+	return i.LookupBinary(binaryName)
+}
+
+func (i *Installer) documentSpec() (installers.DocumentSpec, error) {
 	path, err := i.GetConfigPath()
 	if err != nil {
-		return nil, err
+		return installers.DocumentSpec{}, err
 	}
 
-	if !i.FileExists(path) {
-		slog.InfoContext(ctx, "config file not found, creating new one", slog.String("path", path))
-		return &GeminiConfig{
-			McpServers: make(map[string]McpServerConfig),
-		}, nil
+	return installers.DocumentSpec{
+		Path:    path,
+		Codec:   installers.JSONCodec{},
+		KeyPath: []string{mcpKey},
+		Schema:  schema,
+	}, nil
+}
+
+func (i *Installer) LoadConfig(ctx context.Context) (interface{}, error) {
+	spec, err := i.documentSpec()
+	if err != nil {
+		return nil, err
 	}
 
-	data, err := i.LoadJSONConfig(ctx, path)
+	state, err := i.LoadDocument(ctx, spec)
 	if err != nil {
 		return nil, err
 	}
 
+	if !state.Snapshot.Exists {
+		slog.InfoContext(ctx, "config file not found, creating new one", slog.String("path", spec.Path))
+	}
+
+	return i.newConfig(state), nil
+}
+
+// newConfig converts the server entries of a loaded document.
+func (i *Installer) newConfig(state *installers.LoadState) *GeminiConfig {
 	config := &GeminiConfig{
 		McpServers: make(map[string]McpServerConfig),
+		LoadState:  *state,
+	}
+
+	for name, serverData := range state.Servers {
+		if serverMap, ok := serverData.(map[string]interface{}); ok {
+			mcpServer := McpServerConfig{
+				URL:     installers.StringValue(serverMap, "url"),
+				Type:    installers.StringValue(serverMap, "type"),
+				Headers: installers.StringMap(serverMap["headers"]),
+				Command: installers.StringValue(serverMap, "command"),
+				Args:    installers.StringSlice(serverMap["args"]),
+				Env:     installers.StringMap(serverMap["env"]),
+			}
+
+			if timeout, ok := serverMap["timeout"].(json.Number); ok {
+				if value, err := timeout.Int64(); err == nil {
+					mcpServer.Timeout = int(value)
+				}
+			}
+
+			config.McpServers[name] = mcpServer
+		}
 	}
 
-	if servers, ok := data[mcpKey].(map[string]interface{}); ok {
-		for name, serverData := range servers {
-			if serverMap, ok := serverData.(map[string]interface{}); ok {
-				mcpServer := McpServerConfig{}
+	return config
+}
 
-				if url, ok := serverMap["url"].(string); ok {
-					mcpServer.URL = url
-				}
+// ParseServers returns the MCP servers of configuration content that is not
+// read from disk, such as a backup.
+func (i *Installer) ParseServers(ctx context.Context, data []byte) ([]*installer.McpServer, error) {
+	spec, err := i.documentSpec()
+	if err != nil {
+		return nil, err
+	}
 
-				if serverType, ok := serverMap["type"].(string); ok {
-					mcpServer.Type = serverType
-				}
+	state, err := i.DecodeDocument(ctx, spec, data)
+	if err != nil {
+		return nil, err
+	}
 
-				if timeout, ok := serverMap["timeout"].(float64); ok {
-					mcpServer.Timeout = int(timeout)
-				}
+	return i.ListMcpServers(ctx, i.newConfig(state))
+}
 
-				if headers, ok := serverMap["headers"].(map[string]interface{}); ok {
-					mcpServer.Headers = make(map[string]string)
-					for k, v := range headers {
-						if vStr, ok := v.(string); ok {
-							mcpServer.Headers[k] = vStr
-						}
-					}
-				}
+func (i *Installer) ParseConfig(data []byte) error {
+	spec, err := i.documentSpec()
+	if err != nil {
+		return err
+	}
 
-				config.McpServers[name] = mcpServer
-			}
-		}
+	return i.ParseDocument(spec, data)
+}
+
+func (i *Installer) RepairConfig(data []byte) ([]byte, []string, error) {
+	spec, err := i.documentSpec()
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return config, nil
+	return i.RepairDocument(spec, data)
 }
 
 func (i *Installer) AddMcpServer(ctx context.Context, config interface{}, server *installer.McpServer) (interface{}, error) {
@@ -118,19 +182,8 @@ func (i *Installer) AddMcpServer(ctx context.Context, config interface{}, server
 		return nil, errors.ErrServerAlreadyExists
 	}
 
-	// Add Content-Type header for Gemini CLI
-	headers := make(map[string]string)
-	for k, v := range server.Headers {
-		headers[k] = v
-	}
-	headers["Content-Type"] = "application/json"
-
-	geminiConfig.McpServers[server.Name] = McpServerConfig{
-		URL:     server.URL,
-		Type:    "http",
-		Headers: headers,
-		Timeout: 30000,
-	}
+	geminiConfig.McpServers[server.Name] = fromMcpServer(server)
+	geminiConfig.MarkChanged(server.Name)
 
 	slog.InfoContext(ctx, "added MCP server to configuration",
 		slog.String("server", server.Name))
@@ -150,6 +203,7 @@ func (i *Installer) RemoveMcpServer(ctx context.Context, config interface{}) (in
 	}
 
 	delete(geminiConfig.McpServers, serverName)
+	geminiConfig.MarkChanged(serverName)
 
 	slog.InfoContext(ctx, "removed MCP server from configuration",
 		slog.String("server", serverName))
@@ -163,59 +217,36 @@ func (i *Installer) SaveConfig(ctx context.Context, config interface{}) error {
 		return errors.ErrConfigInvalid
 	}
 
-	path, err := i.GetConfigPath()
+	spec, err := i.documentSpec()
 	if err != nil {
 		return err
 	}
 
-	data := map[string]interface{}{
-		mcpKey: geminiConfig.McpServers,
-	}
-
-	if originalData, err := i.LoadJSONConfig(ctx, path); err == nil {
-		for k, v := range originalData {
-			if k != mcpKey {
-				data[k] = v
-			}
-		}
-	}
-
-	return i.SaveJSONConfig(ctx, path, data)
+	patch := installers.BuildPatch(&geminiConfig.LoadState, geminiConfig.McpServers)
+	return i.SaveDocument(ctx, spec, &geminiConfig.LoadState, patch)
 }
 
-func (i *Installer) BackupConfig(ctx context.Context) (string, error) {
+func (i *Installer) DeleteConfig(ctx context.Context) error {
 	path, err := i.GetConfigPath()
 	if err != nil {
-		return "", err
-	}
-
-	if !i.FileExists(path) {
-		slog.InfoContext(ctx, "no existing config to backup")
-		return "", nil
+		return err
 	}
 
-	return i.CreateBackup(path)
+	return i.RemoveFile(path)
 }
 
-func (i *Installer) RestoreConfig(ctx context.Context, backupPath string) error {
-	if backupPath == "" {
-		return nil
+func (i *Installer) ValidateConfig(ctx context.Context, config interface{}) error {
+	geminiConfig, ok := config.(*GeminiConfig)
+	if !ok {
+		return errors.ErrConfigInvalid
 	}
 
-	path, err := i.GetConfigPath()
+	spec, err := i.documentSpec()
 	if err != nil {
 		return err
 	}
 
-	return i.RestoreBackup(backupPath, path)
-}
-
-func (i *Installer) ValidateConfig(ctx context.Context, config interface{}) error {
-	_, ok := config.(*GeminiConfig)
-	if !ok {
-		return errors.ErrConfigInvalid
-	}
-	return nil
+	return i.ValidateDocument(spec, &geminiConfig.LoadState, installers.BuildPatch(&geminiConfig.LoadState, geminiConfig.McpServers))
 }
 
 func (i *Installer) IsClientRunning(ctx context.Context) (bool, error) {
@@ -259,12 +290,89 @@ func (i *Installer) GetMcpServerConfig(ctx context.Context, config interface{})
 		return nil, errors.ErrServerNotFound
 	}
 
+	return toMcpServer(serverName, serverConfig), nil
+}
+
+func (i *Installer) ListMcpServers(ctx context.Context, config interface{}) ([]*installer.McpServer, error) {
+	geminiConfig, ok := config.(*GeminiConfig)
+	if !ok {
+		return nil, errors.ErrConfigInvalid
+	}
+
+	servers := make([]*installer.McpServer, 0, len(geminiConfig.McpServers))
+	for name, serverConfig := range geminiConfig.McpServers {
+		servers = append(servers, toMcpServer(name, serverConfig))
+	}
+
+	return installers.SortServers(servers), nil
+}
+
+// UnsupportedFields reports what Gemini CLI cannot store: it has no per-server
+// switch to disable a server.
+func (i *Installer) UnsupportedFields(server *installer.McpServer) []string {
+	return installers.UnsupportedFields(server,
+		[]string{installer.TransportStdio, installer.TransportHTTP, installer.TransportSSE}, false)
+}
+
+// toMcpServer leaves out the Content-Type header added by fromMcpServer, so
+// that the entry compares equal to the server it was written from.
+func toMcpServer(name string, serverConfig McpServerConfig) *installer.McpServer {
+	if serverConfig.Command != "" {
+		return &installer.McpServer{
+			Name:    name,
+			Type:    installer.TransportStdio,
+			Command: serverConfig.Command,
+			Args:    serverConfig.Args,
+			Env:     serverConfig.Env,
+		}
+	}
+
+	serverType := serverConfig.Type
+	if serverType == "" {
+		serverType = installer.TransportHTTP
+	}
+
+	var headers map[string]string
+	for k, v := range serverConfig.Headers {
+		if k == contentTypeHeader && v == contentTypeJSON {
+			continue
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[k] = v
+	}
+
 	return &installer.McpServer{
-		Name:    serverName,
-		Type:    "http",
+		Name:    name,
+		Type:    serverType,
 		URL:     serverConfig.URL,
-		Headers: serverConfig.Headers,
-	}, nil
+		Headers: headers,
+	}
+}
+
+func fromMcpServer(server *installer.McpServer) McpServerConfig {
+	if server.Type == installer.TransportStdio {
+		return McpServerConfig{
+			Command: server.Command,
+			Args:    server.Args,
+			Env:     server.Env,
+		}
+	}
+
+	// Add Content-Type header for Gemini CLI
+	headers := make(map[string]string)
+	for k, v := range server.Headers {
+		headers[k] = v
+	}
+	headers[contentTypeHeader] = contentTypeJSON
+
+	return McpServerConfig{
+		URL:     server.URL,
+		Type:    server.Type,
+		Headers: headers,
+		Timeout: 30000,
+	}
 }
 
 func (i *Installer) FormatConfig(ctx context.Context, config interface{}) (string, error) {
@@ -310,7 +418,11 @@ func (i *Installer) formatServerSection(sectionTitle string, servers map[string]
 
 	for name, server := range servers {
 		result += fmt.Sprintf("Server: %s\n", name)
-		result += fmt.Sprintf("  URL: %s\n", server.URL)
+		if server.Command != "" {
+			result += fmt.Sprintf("  Command: %s\n", strings.Join(append([]string{server.Command}, server.Args...), " "))
+		} else {
+			result += fmt.Sprintf("  URL: %s\n", server.URL)
+		}
 		if len(server.Headers) > 0 {
 			result += "  Headers:\n"
 			for k, v := range server.Headers {
@@ -2,6 +2,7 @@ package opencode
 
 import (
 	"context"
+	_ "embed"
 	"fmt"
 	"log/slog"
 	"os/exec"
@@ -12,26 +13,45 @@ import (
 	"go.kirha.ai/mcp-installer/internal/adapters/installers"
 	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
 	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/pkg/jsonschema"
 	"go.kirha.ai/mcp-installer/pkg/security"
 )
 
 const (
+	binaryName     = "opencode"
 	configFileName = "opencode.json"
 	configDir      = "opencode"
 	mcpKey         = "mcp"
 )
 
+// schemaJSON describes the MCP server entries the client accepts.
+//
+//go:embed schema.json
+var schemaJSON []byte
+
+var schema = jsonschema.MustCompile(schemaJSON)
+
 type OpenCodeConfig struct {
 	McpServers map[string]McpServerConfig `json:"mcp,omitempty"`
+
+	installers.LoadState `json:"-"`
 }
 
 type McpServerConfig struct {
-	Type    string            `json:"type"`
-	URL     string            `json:"url"`
-	Enabled bool              `json:"enabled"`
-	Headers map[string]string `json:"headers,omitempty"`
+	Type        string            `json:"type"`
+	URL         string            `json:"url,omitempty"`
+	Command     []string          `json:"command,omitempty"`
+	Environment map[string]string `json:"environment,omitempty"`
+	Enabled     bool              `json:"enabled"`
+	Headers     map[string]string `json:"headers,omitempty"`
 }
 
+// OpenCode names its transports after where the server runs.
+const (
+	typeLocal  = "local"
+	typeRemote = "remote"
+)
+
 type Installer struct {
 	*installers.BaseInstaller
 }
@@ -51,6 +71,24 @@ func (i *Installer) GetConfigPath() (string, error) {
 	return filepath.Join(configBase, configDir, configFileName), nil
 }
 
+func (i *Installer) GetBinaryPath() (string, error) {
+	return i.LookupBinary(binaryName)
+}
+
+func (i *Installer) documentSpec() (installers.DocumentSpec, error) {
+	path, err := i.GetConfigPath()
+	if err != nil {
+		return installers.DocumentSpec{}, err
+	}
+
+	return installers.DocumentSpec{
+		Path:    path,
+		Codec:   installers.JSONCodec{},
+		KeyPath: []string{mcpKey},
+		Schema:  schema,
+	}, nil
+}
+
 func (i *Installer) GetConfigDir() (string, error) {
 	switch runtime.GOOS {
 	case "darwin", "linux":
@@ -67,59 +105,83 @@ func (i *Installer) GetConfigDir() (string, error) {
 }
 
 func (i *Installer) LoadConfig(ctx context.Context) (interface{}, error) {
-	path, err := i.GetConfigPath()
+	spec, err := i.documentSpec()
 	if err != nil {
 		return nil, err
 	}
 
-	if !i.FileExists(path) {
-		slog.InfoContext(ctx, "config file not found, creating new one", slog.String("path", path))
-		return &OpenCodeConfig{
-			McpServers: make(map[string]McpServerConfig),
-		}, nil
-	}
-
-	data, err := i.LoadJSONConfig(ctx, path)
+	state, err := i.LoadDocument(ctx, spec)
 	if err != nil {
 		return nil, err
 	}
 
+	if !state.Snapshot.Exists {
+		slog.InfoContext(ctx, "config file not found, creating new one", slog.String("path", spec.Path))
+	}
+
+	return i.newConfig(state), nil
+}
+
+// newConfig converts the server entries of a loaded document.
+func (i *Installer) newConfig(state *installers.LoadState) *OpenCodeConfig {
 	config := &OpenCodeConfig{
 		McpServers: make(map[string]McpServerConfig),
+		LoadState:  *state,
 	}
 
-	if servers, ok := data[mcpKey].(map[string]interface{}); ok {
-		for name, serverData := range servers {
-			if serverMap, ok := serverData.(map[string]interface{}); ok {
-				mcpServer := McpServerConfig{}
+	for name, serverData := range state.Servers {
+		if serverMap, ok := serverData.(map[string]interface{}); ok {
+			mcpServer := McpServerConfig{
+				Type:        installers.StringValue(serverMap, "type"),
+				URL:         installers.StringValue(serverMap, "url"),
+				Command:     installers.StringSlice(serverMap["command"]),
+				Environment: installers.StringMap(serverMap["environment"]),
+				Headers:     installers.StringMap(serverMap["headers"]),
+			}
 
-				if serverType, ok := serverMap["type"].(string); ok {
-					mcpServer.Type = serverType
-				}
+			if enabled, ok := serverMap["enabled"].(bool); ok {
+				mcpServer.Enabled = enabled
+			}
 
-				if url, ok := serverMap["url"].(string); ok {
-					mcpServer.URL = url
-				}
+			config.McpServers[name] = mcpServer
+		}
+	}
 
-				if enabled, ok := serverMap["enabled"].(bool); ok {
-					mcpServer.Enabled = enabled
-				}
+	return config
+}
 
-				if headers, ok := serverMap["headers"].(map[string]interface{}); ok {
-					mcpServer.Headers = make(map[string]string)
-					for k, v := range headers {
-						if vStr, ok := v.(string); ok {
-							mcpServer.Headers[k] = vStr
-						}
-					}
-				}
+// ParseServers returns the MCP servers of configuration content that is not
+// read from disk, such as a backup.
+func (i *Installer) ParseServers(ctx context.Context, data []byte) ([]*installer.McpServer, error) {
+	spec, err := i.documentSpec()
+	if err != nil {
+		return nil, err
+	}
 
-				config.McpServers[name] = mcpServer
-			}
-		}
+	state, err := i.DecodeDocument(ctx, spec, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.ListMcpServers(ctx, i.newConfig(state))
+}
+
+func (i *Installer) ParseConfig(data []byte) error {
+	spec, err := i.documentSpec()
+	if err != nil {
+		return err
 	}
 
-	return config, nil
+	return i.ParseDocument(spec, data)
+}
+
+func (i *Installer) RepairConfig(data []byte) ([]byte, []string, error) {
+	spec, err := i.documentSpec()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return i.RepairDocument(spec, data)
 }
 
 func (i *Installer) AddMcpServer(ctx context.Context, config interface{}, server *installer.McpServer) (interface{}, error) {
@@ -132,18 +194,8 @@ func (i *Installer) AddMcpServer(ctx context.Context, config interface{}, server
 		return nil, errors.ErrServerAlreadyExists
 	}
 
-	// OpenCode uses "remote" type for HTTP MCP servers
-	serverType := server.Type
-	if serverType == "http" {
-		serverType = "remote"
-	}
-
-	openCodeConfig.McpServers[server.Name] = McpServerConfig{
-		Type:    serverType,
-		URL:     server.URL,
-		Enabled: true,
-		Headers: server.Headers,
-	}
+	openCodeConfig.McpServers[server.Name] = fromMcpServer(server)
+	openCodeConfig.MarkChanged(server.Name)
 
 	slog.InfoContext(ctx, "added MCP server to configuration",
 		slog.String("server", server.Name))
@@ -163,6 +215,7 @@ func (i *Installer) RemoveMcpServer(ctx context.Context, config interface{}) (in
 	}
 
 	delete(openCodeConfig.McpServers, serverName)
+	openCodeConfig.MarkChanged(serverName)
 
 	slog.InfoContext(ctx, "removed MCP server from configuration",
 		slog.String("server", serverName))
@@ -176,59 +229,36 @@ func (i *Installer) SaveConfig(ctx context.Context, config interface{}) error {
 		return errors.ErrConfigInvalid
 	}
 
-	path, err := i.GetConfigPath()
+	spec, err := i.documentSpec()
 	if err != nil {
 		return err
 	}
 
-	data := map[string]interface{}{
-		mcpKey: openCodeConfig.McpServers,
-	}
-
-	if originalData, err := i.LoadJSONConfig(ctx, path); err == nil {
-		for k, v := range originalData {
-			if k != mcpKey {
-				data[k] = v
-			}
-		}
-	}
-
-	return i.SaveJSONConfig(ctx, path, data)
+	patch := installers.BuildPatch(&openCodeConfig.LoadState, openCodeConfig.McpServers)
+	return i.SaveDocument(ctx, spec, &openCodeConfig.LoadState, patch)
 }
 
-func (i *Installer) BackupConfig(ctx context.Context) (string, error) {
+func (i *Installer) DeleteConfig(ctx context.Context) error {
 	path, err := i.GetConfigPath()
 	if err != nil {
-		return "", err
-	}
-
-	if !i.FileExists(path) {
-		slog.InfoContext(ctx, "no existing config to backup")
-		return "", nil
+		return err
 	}
 
-	return i.CreateBackup(path)
+	return i.RemoveFile(path)
 }
 
-func (i *Installer) RestoreConfig(ctx context.Context, backupPath string) error {
-	if backupPath == "" {
-		return nil
+func (i *Installer) ValidateConfig(ctx context.Context, config interface{}) error {
+	openCodeConfig, ok := config.(*OpenCodeConfig)
+	if !ok {
+		return errors.ErrConfigInvalid
 	}
 
-	path, err := i.GetConfigPath()
+	spec, err := i.documentSpec()
 	if err != nil {
 		return err
 	}
 
-	return i.RestoreBackup(backupPath, path)
-}
-
-func (i *Installer) ValidateConfig(ctx context.Context, config interface{}) error {
-	_, ok := config.(*OpenCodeConfig)
-	if !ok {
-		return errors.ErrConfigInvalid
-	}
-	return nil
+	return i.ValidateDocument(spec, &openCodeConfig.LoadState, installers.BuildPatch(&openCodeConfig.LoadState, openCodeConfig.McpServers))
 }
 
 func (i *Installer) IsClientRunning(ctx context.Context) (bool, error) {
@@ -272,12 +302,72 @@ func (i *Installer) GetMcpServerConfig(ctx context.Context, config interface{})
 		return nil, errors.ErrServerNotFound
 	}
 
+	return toMcpServer(serverName, serverConfig), nil
+}
+
+func (i *Installer) ListMcpServers(ctx context.Context, config interface{}) ([]*installer.McpServer, error) {
+	openCodeConfig, ok := config.(*OpenCodeConfig)
+	if !ok {
+		return nil, errors.ErrConfigInvalid
+	}
+
+	servers := make([]*installer.McpServer, 0, len(openCodeConfig.McpServers))
+	for name, serverConfig := range openCodeConfig.McpServers {
+		servers = append(servers, toMcpServer(name, serverConfig))
+	}
+
+	return installers.SortServers(servers), nil
+}
+
+// UnsupportedFields reports what OpenCode cannot store: remote servers are
+// written as streamable HTTP, so SSE is not kept.
+func (i *Installer) UnsupportedFields(server *installer.McpServer) []string {
+	return installers.UnsupportedFields(server,
+		[]string{installer.TransportStdio, installer.TransportHTTP}, true)
+}
+
+// toMcpServer splits the command line of local servers into the command and
+// its arguments.
+func toMcpServer(name string, serverConfig McpServerConfig) *installer.McpServer {
+	if serverConfig.Type == typeLocal {
+		server := &installer.McpServer{
+			Name:     name,
+			Type:     installer.TransportStdio,
+			Env:      serverConfig.Environment,
+			Disabled: !serverConfig.Enabled,
+		}
+		if len(serverConfig.Command) > 0 {
+			server.Command = serverConfig.Command[0]
+			server.Args = serverConfig.Command[1:]
+		}
+		return server
+	}
+
 	return &installer.McpServer{
-		Name:    serverName,
-		Type:    serverConfig.Type,
-		URL:     serverConfig.URL,
-		Headers: serverConfig.Headers,
-	}, nil
+		Name:     name,
+		Type:     installer.TransportHTTP,
+		URL:      serverConfig.URL,
+		Headers:  serverConfig.Headers,
+		Disabled: !serverConfig.Enabled,
+	}
+}
+
+func fromMcpServer(server *installer.McpServer) McpServerConfig {
+	if server.Type == installer.TransportStdio {
+		return McpServerConfig{
+			Type:        typeLocal,
+			Command:     append([]string{server.Command}, server.Args...),
+			Environment: server.Env,
+			Enabled:     !server.Disabled,
+		}
+	}
+
+	return McpServerConfig{
+		Type:    typeRemote,
+		URL:     server.URL,
+		Enabled: !server.Disabled,
+		Headers: server.Headers,
+	}
 }
 
 func (i *Installer) FormatConfig(ctx context.Context, config interface{}) (string, error) {
@@ -324,7 +414,11 @@ func (i *Installer) formatServerSection(sectionTitle string, servers map[string]
 	for name, server := range servers {
 		result += fmt.Sprintf("Server: %s\n", name)
 		result += fmt.Sprintf("  Type: %s\n", server.Type)
-		result += fmt.Sprintf("  URL: %s\n", server.URL)
+		if len(server.Command) > 0 {
+			result += fmt.Sprintf("  Command: %s\n", strings.Join(server.Command, " "))
+		} else {
+			result += fmt.Sprintf("  URL: %s\n", server.URL)
+		}
 		if len(server.Headers) > 0 {
 			result += "  Headers:\n"
 			for k, v := range server.Headers {
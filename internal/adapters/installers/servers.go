@@ -0,0 +1,79 @@
+package installers
+
+import (
+	"sort"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+// StringValue returns the string stored under key in a decoded server entry.
+func StringValue(entry map[string]interface{}, key string) string {
+	value, _ := entry[key].(string)
+	return value
+}
+
+// StringMap converts a decoded table of strings, such as headers or
+// environment variables. Non-string values are skipped.
+func StringMap(value interface{}) map[string]string {
+	table, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	result := make(map[string]string, len(table))
+	for k, v := range table {
+		if vStr, ok := v.(string); ok {
+			result[k] = vStr
+		}
+	}
+	return result
+}
+
+// StringSlice converts a decoded array of strings, such as command arguments.
+func StringSlice(value interface{}) []string {
+	switch items := value.(type) {
+	case []interface{}:
+		result := make([]string, 0, len(items))
+		for _, item := range items {
+			if itemStr, ok := item.(string); ok {
+				result = append(result, itemStr)
+			}
+		}
+		return result
+	case []string:
+		return items
+	default:
+		return nil
+	}
+}
+
+// SortServers orders servers by name so that listings are stable.
+func SortServers(servers []*installer.McpServer) []*installer.McpServer {
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].Name < servers[j].Name
+	})
+	return servers
+}
+
+// UnsupportedFields lists the settings of server that a client cannot store,
+// given the transports it accepts and whether it can disable a server.
+func UnsupportedFields(server *installer.McpServer, transports []string, disable bool) []string {
+	var fields []string
+
+	supported := false
+	for _, transport := range transports {
+		if server.Type == transport {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		fields = append(fields, "transport "+server.Type)
+	}
+
+	if server.Disabled && !disable {
+		fields = append(fields, "disabled state")
+	}
+
+	return fields
+}
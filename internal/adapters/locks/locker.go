@@ -0,0 +1,122 @@
+package locks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+	"go.kirha.ai/mcp-installer/internal/core/ports"
+	"go.kirha.ai/mcp-installer/pkg/appdirs"
+	"go.kirha.ai/mcp-installer/pkg/filelock"
+)
+
+const (
+	installerLockFile = "installer.lock"
+	configLocksDir    = "locks"
+)
+
+// Locker hands out advisory locks kept in the installer state directory. The
+// installer-wide lock is reentrant within the process so that batch operations
+// can hold it while each client operation acquires it again.
+type Locker struct {
+	mu            sync.Mutex
+	installerLock *filelock.Lock
+	installerRefs int
+}
+
+func New() ports.Locker {
+	return &Locker{}
+}
+
+func (l *Locker) LockInstaller(ctx context.Context, timeout time.Duration) (ports.Unlocker, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.installerRefs == 0 {
+		path, err := appdirs.StatePath(installerLockFile)
+		if err != nil {
+			return nil, err
+		}
+
+		lock, err := acquire(ctx, path, timeout)
+		if err != nil {
+			return nil, err
+		}
+		l.installerLock = lock
+	}
+	l.installerRefs++
+
+	return unlockFunc(l.releaseInstaller), nil
+}
+
+func (l *Locker) releaseInstaller() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.installerRefs--
+	if l.installerRefs > 0 {
+		return nil
+	}
+
+	lock := l.installerLock
+	l.installerLock = nil
+	return lock.Unlock()
+}
+
+func (l *Locker) LockConfig(ctx context.Context, configPath string, timeout time.Duration) (ports.Unlocker, error) {
+	path, err := configLockPath(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := acquire(ctx, path, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return lock, nil
+}
+
+// configLockPath derives a stable lock file name from the absolute config path,
+// keeping the base name so the lock directory stays readable.
+func configLockPath(configPath string) (string, error) {
+	absPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(absPath))
+	name := fmt.Sprintf("%s-%s.lock", filepath.Base(absPath), hex.EncodeToString(sum[:])[:12])
+
+	return appdirs.StatePath(configLocksDir, name)
+}
+
+func acquire(ctx context.Context, path string, timeout time.Duration) (*filelock.Lock, error) {
+	lock, err := filelock.Acquire(ctx, path, timeout)
+	if err == nil {
+		return lock, nil
+	}
+
+	var held *filelock.HeldError
+	if stderrors.As(err, &held) {
+		slog.WarnContext(ctx, "timed out waiting for lock",
+			slog.String("path", held.Path),
+			slog.Int("holder_pid", held.PID))
+		return nil, fmt.Errorf("%w: %v", errors.ErrLocked, held)
+	}
+
+	return nil, err
+}
+
+type unlockFunc func() error
+
+func (f unlockFunc) Unlock() error {
+	return f()
+}
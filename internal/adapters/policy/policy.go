@@ -0,0 +1,96 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/internal/core/ports"
+	"go.kirha.ai/mcp-installer/pkg/appdirs"
+)
+
+const policyFile = "policy.json"
+
+// filePolicy mirrors policy.json. A missing list leaves its rule off.
+type filePolicy struct {
+	AllowedURLs         []string `json:"allowed_urls"`
+	ForbidPlaintextHTTP bool     `json:"forbid_plaintext_http"`
+	RequireEnvKeys      bool     `json:"require_env_keys"`
+	AllowedCommands     []string `json:"allowed_commands"`
+}
+
+// Provider reads policy.json from the system-wide and the user config
+// directories.
+type Provider struct {
+	systemDir string
+}
+
+func New() ports.PolicyProvider {
+	return &Provider{systemDir: appdirs.SystemConfigDir()}
+}
+
+func (p *Provider) Load(ctx context.Context) ([]*installer.Policy, error) {
+	userPath, err := appdirs.ConfigPath(policyFile)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{filepath.Join(p.systemDir, policyFile), userPath}
+
+	var policies []*installer.Policy
+	for _, path := range paths {
+		policy, err := load(path)
+		if err != nil {
+			return nil, err
+		}
+		if policy != nil {
+			policies = append(policies, policy)
+		}
+	}
+
+	return policies, nil
+}
+
+// load reads one policy file, or returns nil when it does not exist. A file
+// that cannot be read or parsed is an error rather than no policy, so a broken
+// policy never lets everything through.
+func load(path string) (*installer.Policy, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", errors.ErrPolicyInvalid, path, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var file filePolicy
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", errors.ErrPolicyInvalid, path, err)
+	}
+
+	for i, entry := range file.AllowedURLs {
+		if entry == "" {
+			return nil, fmt.Errorf("%w: %s: allowed_urls[%d] is empty", errors.ErrPolicyInvalid, path, i)
+		}
+	}
+	for i, entry := range file.AllowedCommands {
+		if entry == "" {
+			return nil, fmt.Errorf("%w: %s: allowed_commands[%d] is empty", errors.ErrPolicyInvalid, path, i)
+		}
+	}
+
+	return &installer.Policy{
+		Path:                path,
+		AllowedURLs:         file.AllowedURLs,
+		ForbidPlaintextHTTP: file.ForbidPlaintextHTTP,
+		RequireEnvKeys:      file.RequireEnvKeys,
+		AllowedCommands:     file.AllowedCommands,
+	}, nil
+}
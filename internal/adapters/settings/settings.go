@@ -0,0 +1,122 @@
+package settings
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/internal/core/ports"
+	"go.kirha.ai/mcp-installer/pkg/appdirs"
+	"go.kirha.ai/mcp-installer/pkg/signing"
+)
+
+const settingsFile = "settings.json"
+
+// fileSettings mirrors settings.json. Pointers distinguish settings that are
+// absent, and therefore use their defaults, from explicit zero values.
+type fileSettings struct {
+	Backups struct {
+		KeepLast *int    `json:"keep_last"`
+		MaxAge   *string `json:"max_age"`
+	} `json:"backups"`
+	Profiles   map[string]fileProfile `json:"profiles"`
+	Signatures struct {
+		TrustedKeys []string `json:"trusted_keys"`
+		Require     bool     `json:"require"`
+	} `json:"signatures"`
+}
+
+type fileProfile struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+}
+
+// Provider reads the installer's settings.json from the user config directory.
+type Provider struct{}
+
+func New() ports.SettingsProvider {
+	return &Provider{}
+}
+
+func (p *Provider) Load(ctx context.Context) (*installer.Settings, error) {
+	settings := installer.DefaultSettings()
+
+	path, err := appdirs.ConfigPath(settingsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var file fileSettings
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", errors.ErrSettingsInvalid, path, err)
+	}
+
+	if file.Backups.KeepLast != nil {
+		if *file.Backups.KeepLast < 0 {
+			return nil, fmt.Errorf("%w: backups.keep_last must not be negative", errors.ErrSettingsInvalid)
+		}
+		settings.Backups.KeepLast = *file.Backups.KeepLast
+	}
+
+	if file.Backups.MaxAge != nil {
+		maxAge, err := installer.ParseAge(*file.Backups.MaxAge)
+		if err != nil {
+			return nil, fmt.Errorf("%w: backups.max_age: %v", errors.ErrSettingsInvalid, err)
+		}
+		settings.Backups.MaxAge = maxAge
+	}
+
+	for name, profile := range file.Profiles {
+		parsed, err := parseProfile(profile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: profiles.%s: %v", errors.ErrSettingsInvalid, name, err)
+		}
+		settings.Profiles[name] = parsed
+	}
+
+	for i, key := range file.Signatures.TrustedKeys {
+		if _, err := signing.ParsePublicKey(key); err != nil {
+			return nil, fmt.Errorf("%w: signatures.trusted_keys[%d]: %v", errors.ErrSettingsInvalid, i, err)
+		}
+	}
+	settings.Signatures = installer.SignaturePolicy{
+		TrustedKeys: file.Signatures.TrustedKeys,
+		Require:     file.Signatures.Require,
+	}
+
+	return settings, nil
+}
+
+func parseProfile(profile fileProfile) (installer.ServerProfile, error) {
+	parsed := installer.DefaultServerProfile()
+
+	if profile.URL != "" {
+		u, err := url.Parse(profile.URL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return parsed, fmt.Errorf("url must be an https URL")
+		}
+		parsed.URL = profile.URL
+	}
+
+	for name := range profile.Headers {
+		if strings.EqualFold(name, "Authorization") {
+			return parsed, fmt.Errorf("API keys are not stored in profiles, pass them with --key")
+		}
+	}
+	parsed.Headers = profile.Headers
+
+	return parsed, nil
+}
@@ -0,0 +1,237 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/internal/core/ports"
+	"go.kirha.ai/mcp-installer/pkg/appdirs"
+	"go.kirha.ai/mcp-installer/pkg/atomicfile"
+)
+
+const (
+	stateFile    = "state.json"
+	stateVersion = 1
+
+	fileMode = 0600
+	dirMode  = 0700
+)
+
+type document struct {
+	Version int      `json:"version"`
+	Servers []record `json:"servers"`
+}
+
+type record struct {
+	Client      string    `json:"client"`
+	Scope       string    `json:"scope"`
+	ConfigPath  string    `json:"config_path"`
+	Project     string    `json:"project,omitempty"`
+	Server      string    `json:"server"`
+	Profile     string    `json:"profile,omitempty"`
+	CreatedFile bool      `json:"created_file"`
+	ServerHash  string    `json:"server_hash"`
+	ContentHash string    `json:"content_hash"`
+	OperationID string    `json:"operation_id,omitempty"`
+	InstalledAt time.Time `json:"installed_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Store keeps the managed servers in state.json in the installer state
+// directory. Other installer processes are kept out by the installer-wide
+// lock held during mutating operations; the mutex serializes the clients of
+// a batch running in this process.
+type Store struct {
+	mu sync.Mutex
+}
+
+func New() ports.StateStore {
+	return &Store{}
+}
+
+func (s *Store) Get(ctx context.Context, key installer.ManagedKey) (*installer.ManagedServer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range doc.Servers {
+		if matches(r, key) {
+			return toManagedServer(r), nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *Store) List(ctx context.Context) ([]*installer.ManagedServer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	servers := make([]*installer.ManagedServer, 0, len(doc.Servers))
+	for _, r := range doc.Servers {
+		servers = append(servers, toManagedServer(r))
+	}
+
+	return servers, nil
+}
+
+func (s *Store) Put(ctx context.Context, server *installer.ManagedServer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	updated := fromManagedServer(server)
+	replaced := false
+	for idx, r := range doc.Servers {
+		if matches(r, server.Key()) {
+			doc.Servers[idx] = updated
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		doc.Servers = append(doc.Servers, updated)
+	}
+
+	return s.save(doc)
+}
+
+func (s *Store) Delete(ctx context.Context, key installer.ManagedKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	kept := doc.Servers[:0]
+	for _, r := range doc.Servers {
+		if !matches(r, key) {
+			kept = append(kept, r)
+		}
+	}
+	doc.Servers = kept
+
+	return s.save(doc)
+}
+
+func (s *Store) load() (*document, error) {
+	path, err := appdirs.StatePath(stateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &document{Version: stateVersion}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read installer state: %w", err)
+	}
+
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse installer state %s: %w", path, err)
+	}
+	if doc.Version > stateVersion {
+		return nil, fmt.Errorf("installer state %s was written by a newer version", path)
+	}
+
+	return &doc, nil
+}
+
+func (s *Store) save(doc *document) error {
+	path, err := appdirs.StatePath(stateFile)
+	if err != nil {
+		return err
+	}
+
+	doc.Version = stateVersion
+	sort.SliceStable(doc.Servers, func(i, j int) bool {
+		if doc.Servers[i].Client != doc.Servers[j].Client {
+			return doc.Servers[i].Client < doc.Servers[j].Client
+		}
+		if doc.Servers[i].Scope != doc.Servers[j].Scope {
+			return doc.Servers[i].Scope < doc.Servers[j].Scope
+		}
+		if doc.Servers[i].ConfigPath != doc.Servers[j].ConfigPath {
+			return doc.Servers[i].ConfigPath < doc.Servers[j].ConfigPath
+		}
+		return doc.Servers[i].Project < doc.Servers[j].Project
+	})
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), dirMode); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(path, data, fileMode); err != nil {
+		return fmt.Errorf("failed to write installer state: %w", err)
+	}
+
+	return nil
+}
+
+func matches(r record, key installer.ManagedKey) bool {
+	return r.Client == string(key.Client) && r.Scope == string(key.Scope) &&
+		r.ConfigPath == key.ConfigPath && r.Project == key.Project
+}
+
+func toManagedServer(r record) *installer.ManagedServer {
+	return &installer.ManagedServer{
+		Client:      installer.ClientType(r.Client),
+		Scope:       installer.Scope(r.Scope),
+		ConfigPath:  r.ConfigPath,
+		Project:     r.Project,
+		Server:      r.Server,
+		Profile:     r.Profile,
+		CreatedFile: r.CreatedFile,
+		ServerHash:  r.ServerHash,
+		ContentHash: r.ContentHash,
+		OperationID: r.OperationID,
+		InstalledAt: r.InstalledAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+func fromManagedServer(server *installer.ManagedServer) record {
+	return record{
+		Client:      string(server.Client),
+		Scope:       string(server.Scope),
+		ConfigPath:  server.ConfigPath,
+		Project:     server.Project,
+		Server:      server.Server,
+		Profile:     server.Profile,
+		CreatedFile: server.CreatedFile,
+		ServerHash:  server.ServerHash,
+		ContentHash: server.ContentHash,
+		OperationID: server.OperationID,
+		InstalledAt: server.InstalledAt,
+		UpdatedAt:   server.UpdatedAt,
+	}
+}
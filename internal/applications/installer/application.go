@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
 	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
 	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
@@ -12,42 +13,88 @@ import (
 	"go.kirha.ai/mcp-installer/internal/core/ports/factories"
 )
 
+const defaultLockTimeout = 10 * time.Second
+
 type Application struct {
 	installerFactory factories.InstallerFactory
+	locker           ports.Locker
+	backups          ports.BackupStore
+	settings         ports.SettingsProvider
+	auditLog         ports.AuditLog
+	state            ports.StateStore
+	bundles          ports.BundleCodec
+	policies         ports.PolicyProvider
+	prober           ports.EndpointProber
 }
 
-func New(installerFactory factories.InstallerFactory) *Application {
+func New(installerFactory factories.InstallerFactory, locker ports.Locker, backups ports.BackupStore, settings ports.SettingsProvider, auditLog ports.AuditLog, state ports.StateStore, bundles ports.BundleCodec, policies ports.PolicyProvider, prober ports.EndpointProber) *Application {
 	return &Application{
 		installerFactory: installerFactory,
+		locker:           locker,
+		backups:          backups,
+		settings:         settings,
+		auditLog:         auditLog,
+		state:            state,
+		bundles:          bundles,
+		policies:         policies,
+		prober:           prober,
 	}
 }
 
 func (a *Application) Execute(ctx context.Context, config *installer.Config) (*installer.InstallResult, error) {
+	return a.executeIn(ctx, config, nil)
+}
+
+// executeIn runs one operation, as part of tx when it is not nil.
+func (a *Application) executeIn(ctx context.Context, config *installer.Config, tx *transaction) (*installer.InstallResult, error) {
+	if config.Mutates() {
+		assignOperationID([]*installer.Config{config})
+
+		unlock, err := a.lockInstaller(ctx, config.LockTimeout)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+
+		result, key, err := a.execute(ctx, config, tx)
+		a.auditOperation(ctx, config, key, result, err)
+		return result, err
+	}
+
+	result, _, err := a.execute(ctx, config, tx)
+	return result, err
+}
+
+// execute runs one operation. Besides its result it returns the API key the
+// operation wrote, or removed, for the audit log.
+func (a *Application) execute(ctx context.Context, config *installer.Config, tx *transaction) (*installer.InstallResult, string, error) {
 	switch config.Operation {
 	case installer.OperationInstall:
-		return a.install(ctx, config)
+		result, err := a.install(ctx, config, tx)
+		return result, config.ApiKey, err
 	case installer.OperationUpdate:
-		return a.update(ctx, config)
-	case installer.OperationRemove:
-		return a.remove(ctx, config)
+		result, err := a.update(ctx, config, tx)
+		return result, config.ApiKey, err
+	case installer.OperationRemove, installer.OperationPurge:
+		return a.remove(ctx, config, tx)
 	case installer.OperationShow:
 		showResult, err := a.show(ctx, config)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		return &installer.InstallResult{
 			Success:    showResult.Success,
 			ConfigPath: showResult.ConfigPath,
 			Message:    showResult.Message,
-		}, nil
+		}, "", nil
 	default:
 		slog.ErrorContext(ctx, "unknown operation requested",
 			slog.String("operation", string(config.Operation)))
-		return nil, errors.ErrUnknownOperation
+		return nil, "", errors.ErrUnknownOperation
 	}
 }
 
-func (a *Application) install(ctx context.Context, config *installer.Config) (*installer.InstallResult, error) {
+func (a *Application) install(ctx context.Context, config *installer.Config, tx *transaction) (*installer.InstallResult, error) {
 	slog.InfoContext(ctx, "starting installation",
 		slog.String("client", string(config.Client)),
 		slog.Bool("dry_run", config.DryRun))
@@ -56,7 +103,7 @@ func (a *Application) install(ctx context.Context, config *installer.Config) (*i
 		return nil, err
 	}
 
-	clientInstaller, err := a.installerFactory.GetInstaller(ctx, config.Client)
+	clientInstaller, err := a.configInstaller(ctx, config)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to get installer for client",
 			slog.String("error", err.Error()),
@@ -64,6 +111,10 @@ func (a *Application) install(ctx context.Context, config *installer.Config) (*i
 		return nil, err
 	}
 
+	if err := checkCancelled(ctx, "checking if the client is running"); err != nil {
+		return nil, err
+	}
+
 	running, err := clientInstaller.IsClientRunning(ctx)
 	if err != nil {
 		slog.WarnContext(ctx, "failed to check if client is running", slog.String("error", err.Error()))
@@ -80,6 +131,16 @@ func (a *Application) install(ctx context.Context, config *installer.Config) (*i
 		return nil, err
 	}
 
+	unlock, err := a.lockConfig(ctx, config, configPath)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := checkCancelled(ctx, "loading the config"); err != nil {
+		return nil, err
+	}
+
 	currentConfig, err := clientInstaller.LoadConfig(ctx)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to load config", slog.String("error", err.Error()))
@@ -97,6 +158,10 @@ func (a *Application) install(ctx context.Context, config *installer.Config) (*i
 		return nil, errors.ErrServerExistsUseUpdate
 	}
 
+	if err := a.enforcePolicy(ctx, config.Client, config.McpServer()); err != nil {
+		return nil, err
+	}
+
 	if config.DryRun {
 		slog.InfoContext(ctx, "dry run - would install server",
 			slog.String("path", configPath))
@@ -107,15 +172,15 @@ func (a *Application) install(ctx context.Context, config *installer.Config) (*i
 		}, nil
 	}
 
-	return a.performInstallOrUpdate(ctx, config, currentConfig, clientInstaller, "installed")
+	return a.performInstallOrUpdate(ctx, config, tx, currentConfig, clientInstaller, "installed")
 }
 
-func (a *Application) update(ctx context.Context, config *installer.Config) (*installer.InstallResult, error) {
+func (a *Application) update(ctx context.Context, config *installer.Config, tx *transaction) (*installer.InstallResult, error) {
 	slog.InfoContext(ctx, "starting update",
 		slog.String("client", string(config.Client)),
 		slog.Bool("dry_run", config.DryRun))
 
-	clientInstaller, err := a.installerFactory.GetInstaller(ctx, config.Client)
+	clientInstaller, err := a.configInstaller(ctx, config)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to get installer for client",
 			slog.String("error", err.Error()),
@@ -123,6 +188,10 @@ func (a *Application) update(ctx context.Context, config *installer.Config) (*in
 		return nil, err
 	}
 
+	if err := checkCancelled(ctx, "checking if the client is running"); err != nil {
+		return nil, err
+	}
+
 	running, err := clientInstaller.IsClientRunning(ctx)
 	if err != nil {
 		slog.WarnContext(ctx, "failed to check if client is running", slog.String("error", err.Error()))
@@ -139,6 +208,18 @@ func (a *Application) update(ctx context.Context, config *installer.Config) (*in
 		return nil, err
 	}
 
+	unlock, err := a.lockConfig(ctx, config, configPath)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := checkCancelled(ctx, "loading the config"); err != nil {
+		return nil, err
+	}
+
+	a.applyManagedProfile(ctx, config, configPath)
+
 	currentConfig, err := clientInstaller.LoadConfig(ctx)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to load config", slog.String("error", err.Error()))
@@ -162,16 +243,19 @@ func (a *Application) update(ctx context.Context, config *installer.Config) (*in
 		return nil, err
 	}
 
+	previousKey := existingServer.ApiKey()
 	if config.ApiKey == "" {
-		if auth, ok := existingServer.Headers["Authorization"]; ok {
-			config.ApiKey = strings.TrimPrefix(auth, "Bearer ")
-		}
+		config.ApiKey = previousKey
 	} else {
 		if err := a.validateApiKey(config.ApiKey); err != nil {
 			return nil, err
 		}
 	}
 
+	if err := a.enforcePolicy(ctx, config.Client, config.McpServer()); err != nil {
+		return nil, err
+	}
+
 	if config.DryRun {
 		slog.InfoContext(ctx, "dry run - would update server",
 			slog.String("path", configPath))
@@ -182,26 +266,45 @@ func (a *Application) update(ctx context.Context, config *installer.Config) (*in
 		}, nil
 	}
 
+	handEdited := false
+	if managed := a.managedServer(ctx, config, configPath); managed != nil && existingServer.Hash() != managed.ServerHash {
+		slog.WarnContext(ctx, "Kirha MCP server entry was edited outside the installer, replacing it",
+			slog.String("client", string(config.Client)),
+			slog.String("path", configPath))
+		handEdited = true
+	}
+
 	configWithoutServer, err := clientInstaller.RemoveMcpServer(ctx, currentConfig)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to remove existing server", slog.String("error", err.Error()))
 		return nil, err
 	}
 
-	return a.performInstallOrUpdate(ctx, config, configWithoutServer, clientInstaller, "updated")
+	result, err := a.performInstallOrUpdate(ctx, config, tx, configWithoutServer, clientInstaller, "updated")
+	if err == nil && previousKey != config.ApiKey {
+		a.revokeKey(ctx, tx, previousKey)
+	}
+	if err == nil && handEdited {
+		result.Message = strings.TrimSuffix(result.Message, ".") + ". The entry had been edited by hand; those changes were replaced."
+	}
+	return result, err
 }
 
-func (a *Application) remove(ctx context.Context, config *installer.Config) (*installer.InstallResult, error) {
+func (a *Application) remove(ctx context.Context, config *installer.Config, tx *transaction) (*installer.InstallResult, string, error) {
 	slog.InfoContext(ctx, "starting removal",
 		slog.String("client", string(config.Client)),
 		slog.Bool("dry_run", config.DryRun))
 
-	clientInstaller, err := a.installerFactory.GetInstaller(ctx, config.Client)
+	clientInstaller, err := a.configInstaller(ctx, config)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to get installer for client",
 			slog.String("error", err.Error()),
 			slog.String("client", string(config.Client)))
-		return nil, err
+		return nil, "", err
+	}
+
+	if err := checkCancelled(ctx, "checking if the client is running"); err != nil {
+		return nil, "", err
 	}
 
 	running, err := clientInstaller.IsClientRunning(ctx)
@@ -211,30 +314,40 @@ func (a *Application) remove(ctx context.Context, config *installer.Config) (*in
 	if running && !config.DryRun && !config.Force {
 		slog.WarnContext(ctx, "client is currently running",
 			slog.String("client", string(config.Client)))
-		return nil, errors.ErrClientRunning
+		return nil, "", errors.ErrClientRunning
 	}
 
 	configPath, err := clientInstaller.GetConfigPath()
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to get config path", slog.String("error", err.Error()))
-		return nil, err
+		return nil, "", err
+	}
+
+	unlock, err := a.lockConfig(ctx, config, configPath)
+	if err != nil {
+		return nil, "", err
+	}
+	defer unlock()
+
+	if err := checkCancelled(ctx, "loading the config"); err != nil {
+		return nil, "", err
 	}
 
 	currentConfig, err := clientInstaller.LoadConfig(ctx)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to load config", slog.String("error", err.Error()))
-		return nil, err
+		return nil, "", err
 	}
 
 	exists, err := clientInstaller.HasMcpServer(ctx, currentConfig)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to check if server exists", slog.String("error", err.Error()))
-		return nil, err
+		return nil, "", err
 	}
 
 	if !exists {
 		slog.ErrorContext(ctx, "MCP server not found, nothing to remove")
-		return nil, errors.ErrServerNotFoundForRemove
+		return nil, "", errors.ErrServerNotFoundForRemove
 	}
 
 	if config.DryRun {
@@ -244,64 +357,97 @@ func (a *Application) remove(ctx context.Context, config *installer.Config) (*in
 			Success:    true,
 			ConfigPath: configPath,
 			Message:    fmt.Sprintf("Would remove Kirha MCP server from %s", configPath),
-		}, nil
+		}, "", nil
 	}
 
-	backupPath, err := clientInstaller.BackupConfig(ctx)
+	if err := ctx.Err(); err != nil {
+		slog.ErrorContext(ctx, "operation cancelled before modifying config", slog.String("error", err.Error()))
+		return nil, "", err
+	}
+
+	backup, err := a.backupConfig(ctx, tx, config, configPath)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to create backup", slog.String("error", err.Error()))
 	}
 
+	var removedKey string
+	if server, err := clientInstaller.GetMcpServerConfig(ctx, currentConfig); err == nil {
+		removedKey = server.ApiKey()
+	}
+	if tx != nil {
+		tx.recordRemovedKey(managedKey(config, configPath), removedKey)
+	}
+
+	// A file the installer created and nobody touched since holds nothing but
+	// the Kirha entry, so it is deleted rather than left behind empty.
+	deleteFile := a.createdFileUnchanged(ctx, a.managedServer(ctx, config, configPath))
+
 	updatedConfig, err := clientInstaller.RemoveMcpServer(ctx, currentConfig)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to remove MCP server", slog.String("error", err.Error()))
 
-		if backupPath != "" {
-			if restoreErr := clientInstaller.RestoreConfig(ctx, backupPath); restoreErr != nil {
-				slog.ErrorContext(ctx, "failed to restore backup", slog.String("error", restoreErr.Error()))
-			}
-		}
+		a.restoreConfig(ctx, clientInstaller, backup, false)
 
-		return nil, err
+		return nil, removedKey, err
 	}
 
-	if err := clientInstaller.SaveConfig(ctx, updatedConfig); err != nil {
+	if err := checkCancelled(ctx, "saving the config"); err != nil {
+		return nil, removedKey, err
+	}
+
+	if deleteFile {
+		err = clientInstaller.DeleteConfig(ctx)
+	} else {
+		err = clientInstaller.SaveConfig(ctx, updatedConfig)
+	}
+	if err != nil {
 		slog.ErrorContext(ctx, "failed to save config", slog.String("error", err.Error()))
 
-		if backupPath != "" {
-			if restoreErr := clientInstaller.RestoreConfig(ctx, backupPath); restoreErr != nil {
-				slog.ErrorContext(ctx, "failed to restore backup", slog.String("error", restoreErr.Error()))
-			}
-		}
+		a.restoreConfig(ctx, clientInstaller, backup, false)
 
-		return nil, err
+		return nil, removedKey, err
 	}
 
+	backup = a.recordAfter(ctx, tx, backup)
+	a.forgetManaged(ctx, config, configPath)
+	a.revokeKey(ctx, tx, removedKey)
+
 	message := fmt.Sprintf("Successfully removed Kirha MCP server from %s", config.Client)
+	if deleteFile {
+		message += " and deleted the configuration file it had created"
+	}
 	if running {
 		message += ". Please restart the application to apply changes."
 	}
 
 	slog.InfoContext(ctx, "removal completed successfully",
 		slog.String("config_path", configPath),
-		slog.String("backup_path", backupPath))
+		slog.String("backup_id", backupID(backup)))
 
 	return &installer.InstallResult{
-		Success:    true,
-		ConfigPath: configPath,
-		BackupPath: backupPath,
-		Message:    message,
-	}, nil
+		Success:     true,
+		ConfigPath:  configPath,
+		BackupID:    backupID(backup),
+		OperationID: config.ID,
+		Message:     message,
+	}, removedKey, nil
 }
 
-func (a *Application) performInstallOrUpdate(ctx context.Context, config *installer.Config, currentConfig interface{}, clientInstaller ports.Installer, operation string) (*installer.InstallResult, error) {
+func (a *Application) performInstallOrUpdate(ctx context.Context, config *installer.Config, tx *transaction, currentConfig interface{}, clientInstaller ports.Installer, operation string) (*installer.InstallResult, error) {
 	configPath, err := clientInstaller.GetConfigPath()
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to get config path", slog.String("error", err.Error()))
 		return nil, err
 	}
 
-	backupPath, err := clientInstaller.BackupConfig(ctx)
+	if err := ctx.Err(); err != nil {
+		slog.ErrorContext(ctx, "operation cancelled before modifying config", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	created := !clientInstaller.FileExists(configPath)
+
+	backup, err := a.backupConfig(ctx, tx, config, configPath)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to create backup", slog.String("error", err.Error()))
 	}
@@ -311,29 +457,25 @@ func (a *Application) performInstallOrUpdate(ctx context.Context, config *instal
 		return nil, err
 	}
 
-	mcpServer := installer.NewKirhaRemoteMcpServer(config.ApiKey)
+	mcpServer := config.McpServer()
 
 	updatedConfig, err := clientInstaller.AddMcpServer(ctx, currentConfig, mcpServer)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to add MCP server", slog.String("error", err.Error()))
 
-		if backupPath != "" {
-			if restoreErr := clientInstaller.RestoreConfig(ctx, backupPath); restoreErr != nil {
-				slog.ErrorContext(ctx, "failed to restore backup", slog.String("error", restoreErr.Error()))
-			}
-		}
+		a.restoreConfig(ctx, clientInstaller, backup, created)
 
 		return nil, err
 	}
 
+	if err := checkCancelled(ctx, "saving the config"); err != nil {
+		return nil, err
+	}
+
 	if err := clientInstaller.SaveConfig(ctx, updatedConfig); err != nil {
 		slog.ErrorContext(ctx, "failed to save config", slog.String("error", err.Error()))
 
-		if backupPath != "" {
-			if restoreErr := clientInstaller.RestoreConfig(ctx, backupPath); restoreErr != nil {
-				slog.ErrorContext(ctx, "failed to restore backup", slog.String("error", restoreErr.Error()))
-			}
-		}
+		a.restoreConfig(ctx, clientInstaller, backup, created)
 
 		return nil, err
 	}
@@ -342,11 +484,7 @@ func (a *Application) performInstallOrUpdate(ctx context.Context, config *instal
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to load saved config for validation", slog.String("error", err.Error()))
 
-		if backupPath != "" {
-			if restoreErr := clientInstaller.RestoreConfig(ctx, backupPath); restoreErr != nil {
-				slog.ErrorContext(ctx, "failed to restore backup", slog.String("error", restoreErr.Error()))
-			}
-		}
+		a.restoreConfig(ctx, clientInstaller, backup, created)
 
 		return nil, fmt.Errorf("%w: config validation failed", errors.ErrInstallationFailed)
 	}
@@ -354,15 +492,14 @@ func (a *Application) performInstallOrUpdate(ctx context.Context, config *instal
 	if err := clientInstaller.ValidateConfig(ctx, savedConfig); err != nil {
 		slog.ErrorContext(ctx, "saved config validation failed", slog.String("error", err.Error()))
 
-		if backupPath != "" {
-			if restoreErr := clientInstaller.RestoreConfig(ctx, backupPath); restoreErr != nil {
-				slog.ErrorContext(ctx, "failed to restore backup", slog.String("error", restoreErr.Error()))
-			}
-		}
+		a.restoreConfig(ctx, clientInstaller, backup, created)
 
-		return nil, fmt.Errorf("%w: saved config invalid", errors.ErrInstallationFailed)
+		return nil, fmt.Errorf("%w: saved config invalid: %w", errors.ErrInstallationFailed, err)
 	}
 
+	backup = a.recordAfter(ctx, tx, backup)
+	a.recordManaged(ctx, config, clientInstaller, configPath, created)
+
 	running, _ := clientInstaller.IsClientRunning(ctx)
 
 	message := fmt.Sprintf("Successfully %s Kirha MCP server for %s", operation, config.Client)
@@ -372,21 +509,147 @@ func (a *Application) performInstallOrUpdate(ctx context.Context, config *instal
 
 	slog.InfoContext(ctx, fmt.Sprintf("%s completed successfully", operation),
 		slog.String("config_path", configPath),
-		slog.String("backup_path", backupPath))
+		slog.String("backup_id", backupID(backup)))
 
 	return &installer.InstallResult{
-		Success:    true,
-		ConfigPath: configPath,
-		BackupPath: backupPath,
-		Message:    message,
+		Success:     true,
+		ConfigPath:  configPath,
+		BackupID:    backupID(backup),
+		OperationID: config.ID,
+		Message:     message,
+	}, nil
+}
+
+func (a *Application) lockInstaller(ctx context.Context, timeout time.Duration) (func(), error) {
+	if timeout <= 0 {
+		timeout = defaultLockTimeout
+	}
+
+	lock, err := a.locker.LockInstaller(ctx, timeout)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to acquire installer lock", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return func() {
+		if err := lock.Unlock(); err != nil {
+			slog.WarnContext(ctx, "failed to release installer lock", slog.String("error", err.Error()))
+		}
+	}, nil
+}
+
+// lockConfig takes the advisory lock for a client configuration file so that no
+// other installer process can interleave its own load and save. Dry runs only
+// read the file and are not locked.
+func (a *Application) lockConfig(ctx context.Context, config *installer.Config, configPath string) (func(), error) {
+	if !config.Mutates() {
+		return func() {}, nil
+	}
+
+	return a.lockConfigFile(ctx, configPath, config.LockTimeout)
+}
+
+func (a *Application) lockConfigFile(ctx context.Context, configPath string, timeout time.Duration) (func(), error) {
+	if timeout <= 0 {
+		timeout = defaultLockTimeout
+	}
+
+	lock, err := a.locker.LockConfig(ctx, configPath, timeout)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to acquire config lock",
+			slog.String("path", configPath),
+			slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return func() {
+		if err := lock.Unlock(); err != nil {
+			slog.WarnContext(ctx, "failed to release config lock", slog.String("error", err.Error()))
+		}
 	}, nil
 }
 
+// backupConfig saves the configuration file to the backup store before it is
+// changed. Inside a transaction the backup taken when the transaction started
+// is reused.
+func (a *Application) backupConfig(ctx context.Context, tx *transaction, config *installer.Config, configPath string) (*installer.Backup, error) {
+	if tx != nil {
+		if entry := tx.entry(managedKey(config, configPath)); entry != nil {
+			return entry.backup, nil
+		}
+	}
+
+	backup, err := a.createBackup(ctx, config, configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	a.applyRetention(ctx)
+
+	return backup, nil
+}
+
+func (a *Application) createBackup(ctx context.Context, config *installer.Config, configPath string) (*installer.Backup, error) {
+	return a.backups.Create(ctx, &installer.Backup{
+		OperationID: config.ID,
+		Client:      config.Client,
+		Scope:       configScope(config),
+		Project:     configProject(config),
+		Operation:   config.Operation,
+		ConfigPath:  configPath,
+	})
+}
+
+// backupInstaller returns the installer bound to the scope backup was taken
+// at.
+func (a *Application) backupInstaller(ctx context.Context, backup *installer.Backup) (ports.Installer, error) {
+	scope := backup.Scope
+	if scope == "" {
+		scope = installer.ScopeUser
+	}
+	return a.scopedInstaller(ctx, backup.Client, scope, backup.Project)
+}
+
+// recordAfter marks a backup's operation as completed by recording the state
+// the file was left in. Inside a transaction this happens once it commits.
+func (a *Application) recordAfter(ctx context.Context, tx *transaction, backup *installer.Backup) *installer.Backup {
+	if backup == nil || tx != nil {
+		return backup
+	}
+
+	completed, err := a.backups.RecordAfter(ctx, backup)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to record operation result, it cannot be undone",
+			slog.String("backup_id", backup.ID),
+			slog.String("error", err.Error()))
+		return backup
+	}
+
+	return completed
+}
+
+// restoreConfig rolls a client configuration back after a failed change. When no
+// backup exists because the file was created by this operation, the file is deleted.
+func (a *Application) restoreConfig(ctx context.Context, clientInstaller ports.Installer, backup *installer.Backup, created bool) {
+	if backup != nil {
+		if restoreErr := a.backups.Restore(ctx, backup); restoreErr != nil {
+			slog.ErrorContext(ctx, "failed to restore backup", slog.String("error", restoreErr.Error()))
+		}
+		return
+	}
+
+	if created {
+		if deleteErr := clientInstaller.DeleteConfig(ctx); deleteErr != nil {
+			slog.ErrorContext(ctx, "failed to delete created config", slog.String("error", deleteErr.Error()))
+		}
+	}
+}
+
 func (a *Application) show(ctx context.Context, config *installer.Config) (*installer.ShowResult, error) {
 	slog.InfoContext(ctx, "showing configuration",
 		slog.String("client", string(config.Client)))
 
-	clientInstaller, err := a.installerFactory.GetInstaller(ctx, config.Client)
+	clientInstaller, err := a.configInstaller(ctx, config)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to get installer for client",
 			slog.String("error", err.Error()),
@@ -453,6 +716,16 @@ func (a *Application) show(ctx context.Context, config *installer.Config) (*inst
 	}, nil
 }
 
+// checkCancelled returns the context error when the operation was cancelled or
+// ran out of time, so that it stops before starting the next step.
+func checkCancelled(ctx context.Context, step string) error {
+	if err := ctx.Err(); err != nil {
+		slog.ErrorContext(ctx, "operation cancelled", slog.String("before", step), slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
 func (a *Application) validateApiKey(apiKey string) error {
 	if apiKey == "" {
 		return errors.ErrApiKeyRequired
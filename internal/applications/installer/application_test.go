@@ -3,22 +3,40 @@ package installer
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	domainErrors "go.kirha.ai/mcp-installer/internal/core/domain/errors"
 	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
 	"go.kirha.ai/mcp-installer/internal/core/ports"
+	"go.kirha.ai/mcp-installer/pkg/security"
 )
 
 type MockInstaller struct {
-	configPath       string
-	config           interface{}
-	isRunning        bool
-	shouldFailLoad   bool
-	shouldFailSave   bool
-	shouldFailAdd    bool
-	shouldFailBackup bool
-	backupPath       string
-	hasServer        bool
+	configPath     string
+	binaryPath     string
+	configExists   bool
+	config         interface{}
+	isRunning      bool
+	shouldFailLoad bool
+	shouldFailSave bool
+	shouldFailAdd  bool
+	hasServer      bool
+	// kirha, when set, is returned as the Kirha entry.
+	kirha       *installer.McpServer
+	deleteCalls int
+	servers     []*installer.McpServer
+	unsupported []string
+	added       []*installer.McpServer
+	parseErr    error
+	fixes       []string
+	fixed       []byte
+	// hang, when set, blocks LoadConfig until it is closed.
+	hang chan struct{}
 }
 
 func (m *MockInstaller) GetConfigPath() (string, error) {
@@ -28,7 +46,21 @@ func (m *MockInstaller) GetConfigPath() (string, error) {
 	return m.configPath, nil
 }
 
+func (m *MockInstaller) GetBinaryPath() (string, error) {
+	if m.binaryPath == "" {
+		return "", errors.New("binary not found")
+	}
+	return m.binaryPath, nil
+}
+
+func (m *MockInstaller) FileExists(path string) bool {
+	return m.configExists
+}
+
 func (m *MockInstaller) LoadConfig(ctx context.Context) (interface{}, error) {
+	if m.hang != nil {
+		<-m.hang
+	}
 	if m.shouldFailLoad {
 		return nil, errors.New("mock load error")
 	}
@@ -38,10 +70,22 @@ func (m *MockInstaller) LoadConfig(ctx context.Context) (interface{}, error) {
 	return m.config, nil
 }
 
+func (m *MockInstaller) ParseConfig(data []byte) error {
+	return m.parseErr
+}
+
+func (m *MockInstaller) RepairConfig(data []byte) ([]byte, []string, error) {
+	if len(m.fixes) == 0 {
+		return data, nil, m.parseErr
+	}
+	return m.fixed, m.fixes, nil
+}
+
 func (m *MockInstaller) AddMcpServer(ctx context.Context, config interface{}, server *installer.McpServer) (interface{}, error) {
 	if m.shouldFailAdd {
 		return nil, errors.New("mock add error")
 	}
+	m.added = append(m.added, server)
 	return config, nil
 }
 
@@ -56,14 +100,8 @@ func (m *MockInstaller) SaveConfig(ctx context.Context, config interface{}) erro
 	return nil
 }
 
-func (m *MockInstaller) BackupConfig(ctx context.Context) (string, error) {
-	if m.shouldFailBackup {
-		return "", errors.New("mock backup error")
-	}
-	return m.backupPath, nil
-}
-
-func (m *MockInstaller) RestoreConfig(ctx context.Context, backupPath string) error {
+func (m *MockInstaller) DeleteConfig(ctx context.Context) error {
+	m.deleteCalls++
 	return nil
 }
 
@@ -83,6 +121,9 @@ func (m *MockInstaller) GetMcpServerConfig(ctx context.Context, config interface
 	if !m.hasServer {
 		return nil, errors.New("server not found")
 	}
+	if m.kirha != nil {
+		return m.kirha, nil
+	}
 	return &installer.McpServer{
 		Name:    installer.ServerName,
 		Type:    "http",
@@ -91,6 +132,18 @@ func (m *MockInstaller) GetMcpServerConfig(ctx context.Context, config interface
 	}, nil
 }
 
+func (m *MockInstaller) ListMcpServers(ctx context.Context, config interface{}) ([]*installer.McpServer, error) {
+	return m.servers, nil
+}
+
+func (m *MockInstaller) ParseServers(ctx context.Context, data []byte) ([]*installer.McpServer, error) {
+	return m.servers, nil
+}
+
+func (m *MockInstaller) UnsupportedFields(server *installer.McpServer) []string {
+	return m.unsupported
+}
+
 func (m *MockInstaller) FormatConfig(ctx context.Context, config interface{}) (string, error) {
 	if !m.hasServer {
 		return "No MCP servers configured", nil
@@ -107,21 +160,232 @@ func (m *MockInstaller) FormatSpecificServer(ctx context.Context, config interfa
 
 type MockFactory struct {
 	installer ports.Installer
+	clients   map[installer.ClientType]ports.Installer
+	// supported, when set, replaces the single supported client.
+	supported []installer.ClientType
 }
 
 func (f *MockFactory) GetInstaller(ctx context.Context, clientType installer.ClientType) (ports.Installer, error) {
+	if clientInstaller, ok := f.clients[clientType]; ok {
+		return clientInstaller, nil
+	}
 	return f.installer, nil
 }
 
+func (f *MockFactory) GetSupportedClients() []installer.ClientType {
+	if f.supported != nil {
+		return f.supported
+	}
+	return []installer.ClientType{installer.ClientTypeClaudecode}
+}
+
+type MockLocker struct{}
+
+type mockUnlocker struct{}
+
+func (mockUnlocker) Unlock() error {
+	return nil
+}
+
+func (l *MockLocker) LockInstaller(ctx context.Context, timeout time.Duration) (ports.Unlocker, error) {
+	return mockUnlocker{}, nil
+}
+
+func (l *MockLocker) LockConfig(ctx context.Context, configPath string, timeout time.Duration) (ports.Unlocker, error) {
+	return mockUnlocker{}, nil
+}
+
+type MockBackupStore struct {
+	mu           sync.Mutex
+	backups      []*installer.Backup
+	restoreCalls int
+	currentHash  string
+	// contents, when set, holds the content of backups by ID.
+	contents map[string]string
+	deleted  []string
+}
+
+func (s *MockBackupStore) Create(ctx context.Context, backup *installer.Backup) (*installer.Backup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	created := *backup
+	created.ID = fmt.Sprintf("backup-%d", len(s.backups)+1)
+	created.CreatedAt = time.Now()
+	s.backups = append(s.backups, &created)
+	return &created, nil
+}
+
+func (s *MockBackupStore) RecordAfter(ctx context.Context, backup *installer.Backup) (*installer.Backup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	backup.After = &installer.FileState{Exists: true, Hash: "after"}
+	return backup, nil
+}
+
+func (s *MockBackupStore) FileState(ctx context.Context, path string) (installer.FileState, error) {
+	if s.currentHash != "" {
+		return installer.FileState{Exists: true, Hash: s.currentHash}, nil
+	}
+	return installer.FileState{Exists: true, Hash: "after"}, nil
+}
+
+func (s *MockBackupStore) List(ctx context.Context, filter installer.BackupFilter) ([]*installer.Backup, error) {
+	var backups []*installer.Backup
+	for idx := len(s.backups) - 1; idx >= 0; idx-- {
+		if filter.Matches(s.backups[idx]) {
+			backups = append(backups, s.backups[idx])
+		}
+	}
+	return backups, nil
+}
+
+func (s *MockBackupStore) Get(ctx context.Context, id string) (*installer.Backup, error) {
+	for _, backup := range s.backups {
+		if backup.ID == id {
+			return backup, nil
+		}
+	}
+	return nil, domainErrors.ErrBackupNotFound
+}
+
+func (s *MockBackupStore) Content(ctx context.Context, backup *installer.Backup) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if content, ok := s.contents[backup.ID]; ok {
+		return []byte(content), nil
+	}
+	return []byte("{}"), nil
+}
+
+func (s *MockBackupStore) Restore(ctx context.Context, backup *installer.Backup) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.restoreCalls++
+	return nil
+}
+
+func (s *MockBackupStore) Rewrite(ctx context.Context, backup *installer.Backup, content []byte) (*installer.Backup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.contents != nil {
+		s.contents[backup.ID] = string(content)
+	}
+	rewritten := *backup
+	return &rewritten, nil
+}
+
+func (s *MockBackupStore) Delete(ctx context.Context, backup *installer.Backup) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleted = append(s.deleted, backup.ID)
+	return nil
+}
+
+func (s *MockBackupStore) Prune(ctx context.Context, policy installer.RetentionPolicy, now time.Time, dryRun bool) ([]*installer.Backup, error) {
+	return nil, nil
+}
+
+type MockSettings struct {
+	profiles map[string]installer.ServerProfile
+}
+
+func (m MockSettings) Load(ctx context.Context) (*installer.Settings, error) {
+	settings := installer.DefaultSettings()
+	for name, profile := range m.profiles {
+		settings.Profiles[name] = profile
+	}
+	return settings, nil
+}
+
+type MockPolicies struct {
+	policies []*installer.Policy
+}
+
+func (p *MockPolicies) Load(ctx context.Context) ([]*installer.Policy, error) {
+	return p.policies, nil
+}
+
+type MockAuditLog struct {
+	mu      sync.Mutex
+	records []*installer.AuditRecord
+}
+
+func (l *MockAuditLog) Append(ctx context.Context, record *installer.AuditRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.records = append(l.records, record)
+	return nil
+}
+
+func (l *MockAuditLog) Read(ctx context.Context, filter installer.AuditFilter) ([]*installer.AuditRecord, error) {
+	return l.records, nil
+}
+
+type MockStateStore struct {
+	mu      sync.Mutex
+	servers []*installer.ManagedServer
+}
+
+func (s *MockStateStore) Get(ctx context.Context, key installer.ManagedKey) (*installer.ManagedServer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, server := range s.servers {
+		if server.Key() == key {
+			return server, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *MockStateStore) List(ctx context.Context) ([]*installer.ManagedServer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*installer.ManagedServer(nil), s.servers...), nil
+}
+
+func (s *MockStateStore) Put(ctx context.Context, server *installer.ManagedServer) error {
+	if err := s.Delete(ctx, server.Key()); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.servers = append(s.servers, server)
+	return nil
+}
+
+func (s *MockStateStore) Delete(ctx context.Context, key installer.ManagedKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.servers[:0]
+	for _, server := range s.servers {
+		if server.Key() != key {
+			kept = append(kept, server)
+		}
+	}
+	s.servers = kept
+	return nil
+}
+
 func TestApplication_Execute_Install_Success(t *testing.T) {
 	mockInstaller := &MockInstaller{
 		configPath: "/test/config.json",
-		backupPath: "/test/config.json.backup",
 		hasServer:  false,
 	}
 
 	mockFactory := &MockFactory{installer: mockInstaller}
-	app := New(mockFactory)
+	app := New(mockFactory, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, &MockPolicies{}, nil)
 
 	config := &installer.Config{
 		Client:    installer.ClientTypeClaudecode,
@@ -146,6 +410,111 @@ func TestApplication_Execute_Install_Success(t *testing.T) {
 	}
 }
 
+// MockScopedInstaller hands out a separate installer per scope.
+type MockScopedInstaller struct {
+	*MockInstaller
+	scopes   map[installer.Scope]*MockInstaller
+	projects []string
+}
+
+func (m *MockScopedInstaller) WithScope(scope installer.Scope, project string) (ports.Installer, error) {
+	m.projects = append(m.projects, project)
+	return m.scopes[scope], nil
+}
+
+func TestApplication_Execute_Remove_Scope(t *testing.T) {
+	local := &MockInstaller{configPath: "/home/user/.claude.json", configExists: true, hasServer: true}
+	scoped := &MockScopedInstaller{
+		MockInstaller: &MockInstaller{configPath: "/home/user/.claude.json", configExists: true, hasServer: true},
+		scopes:        map[installer.Scope]*MockInstaller{installer.ScopeLocal: local},
+	}
+
+	state := &MockStateStore{}
+	entries := []*installer.ManagedServer{
+		{Client: installer.ClientTypeClaudecode, Scope: installer.ScopeUser, ConfigPath: "/home/user/.claude.json"},
+		{Client: installer.ClientTypeClaudecode, Scope: installer.ScopeLocal, ConfigPath: "/home/user/.claude.json", Project: "/work/app"},
+		{Client: installer.ClientTypeClaudecode, Scope: installer.ScopeLocal, ConfigPath: "/home/user/.claude.json", Project: "/work/other"},
+	}
+	for _, entry := range entries {
+		_ = state.Put(context.Background(), entry)
+	}
+
+	app := New(&MockFactory{installer: scoped}, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, &MockAuditLog{}, state, nil, &MockPolicies{}, nil)
+	_, err := app.Execute(context.Background(), &installer.Config{
+		Client:    installer.ClientTypeClaudecode,
+		Scope:     installer.ScopeLocal,
+		Project:   "/work/app",
+		Operation: installer.OperationRemove,
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if len(scoped.projects) == 0 || scoped.projects[0] != "/work/app" {
+		t.Errorf("WithScope() projects = %v, want /work/app", scoped.projects)
+	}
+	if len(state.servers) != 2 {
+		t.Fatalf("state holds %d servers after remove, want 2", len(state.servers))
+	}
+	for _, server := range state.servers {
+		if server.Project == "/work/app" {
+			t.Errorf("state still holds the removed local entry")
+		}
+	}
+}
+
+func TestApplication_Execute_Install_ScopeNotSupported(t *testing.T) {
+	mockInstaller := &MockInstaller{configPath: "/test/config.json"}
+	app := New(&MockFactory{installer: mockInstaller}, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, &MockPolicies{}, nil)
+
+	_, err := app.Execute(context.Background(), &installer.Config{
+		Client:    installer.ClientTypeCodex,
+		Scope:     installer.ScopeProject,
+		Project:   "/work/app",
+		ApiKey:    "valid-api-key-123",
+		Operation: installer.OperationInstall,
+	})
+	if !errors.Is(err, domainErrors.ErrScopeNotSupported) {
+		t.Fatalf("Execute() error = %v, want ErrScopeNotSupported", err)
+	}
+	if len(mockInstaller.added) != 0 {
+		t.Errorf("AddMcpServer() called %d times, want 0", len(mockInstaller.added))
+	}
+}
+
+func TestApplication_Execute_Install_BlockedByPolicy(t *testing.T) {
+	mockInstaller := &MockInstaller{configPath: "/test/config.json"}
+	policies := &MockPolicies{policies: []*installer.Policy{
+		{Path: "/etc/policy.json", AllowedURLs: []string{"*.example.com"}, RequireEnvKeys: true},
+	}}
+	app := New(&MockFactory{installer: mockInstaller}, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, policies, nil)
+
+	config := &installer.Config{
+		Client:    installer.ClientTypeClaudecode,
+		ApiKey:    "valid-api-key-123",
+		Operation: installer.OperationInstall,
+	}
+
+	_, err := app.Execute(context.Background(), config)
+	if !errors.Is(err, domainErrors.ErrPolicyViolation) {
+		t.Fatalf("Execute() error = %v, want ErrPolicyViolation", err)
+	}
+
+	var policyErr *installer.PolicyError
+	if !errors.As(err, &policyErr) || len(policyErr.Violations) != 2 {
+		t.Fatalf("Execute() violations = %v, want allowed_urls and require_env_keys", err)
+	}
+	if len(mockInstaller.added) != 0 {
+		t.Errorf("AddMcpServer() called %d times, want 0", len(mockInstaller.added))
+	}
+
+	config.ApiKey = "${KIRHA_API_KEY}"
+	policies.policies[0].AllowedURLs = []string{"https://mcp.kirha.com/"}
+	if _, err := app.Execute(context.Background(), config); err != nil {
+		t.Errorf("Execute() with env-referenced key error = %v, want nil", err)
+	}
+}
+
 func TestApplication_Execute_Show_Success(t *testing.T) {
 	mockInstaller := &MockInstaller{
 		configPath: "/test/config.json",
@@ -153,7 +522,7 @@ func TestApplication_Execute_Show_Success(t *testing.T) {
 	}
 
 	mockFactory := &MockFactory{installer: mockInstaller}
-	app := New(mockFactory)
+	app := New(mockFactory, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, &MockPolicies{}, nil)
 
 	config := &installer.Config{
 		Client:    installer.ClientTypeClaudecode,
@@ -185,7 +554,7 @@ func TestApplication_Execute_Show_NoServer(t *testing.T) {
 	}
 
 	mockFactory := &MockFactory{installer: mockInstaller}
-	app := New(mockFactory)
+	app := New(mockFactory, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, &MockPolicies{}, nil)
 
 	config := &installer.Config{
 		Client:    installer.ClientTypeClaudecode,
@@ -210,6 +579,940 @@ func TestApplication_Execute_Show_NoServer(t *testing.T) {
 	}
 }
 
+func TestApplication_Detect(t *testing.T) {
+	mockInstaller := &MockInstaller{
+		configPath:   "/test/config.json",
+		binaryPath:   "/usr/local/bin/claude",
+		configExists: true,
+		isRunning:    true,
+		hasServer:    true,
+	}
+
+	mockFactory := &MockFactory{installer: mockInstaller}
+	app := New(mockFactory, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, &MockPolicies{}, nil)
+
+	results, err := app.Detect(context.Background())
+	if err != nil {
+		t.Fatalf("Detect() error = %v, want nil", err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("Detect() returned %d results, want 1", len(results))
+	}
+
+	result := results[0]
+	if !result.Detected() {
+		t.Errorf("Detect().Detected() = false, want true")
+	}
+	if result.ConfigPath != "/test/config.json" {
+		t.Errorf("Detect().ConfigPath = %v, want /test/config.json", result.ConfigPath)
+	}
+	if !result.Running || !result.HasServer {
+		t.Errorf("Detect() = running %v, has server %v, want both true", result.Running, result.HasServer)
+	}
+}
+
+func TestApplication_ExecuteBatch(t *testing.T) {
+	mockInstaller := &MockInstaller{
+		configPath: "/test/config.json",
+		hasServer:  false,
+	}
+
+	mockFactory := &MockFactory{installer: mockInstaller}
+	app := New(mockFactory, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, &MockPolicies{}, nil)
+
+	configs := []*installer.Config{
+		{Client: installer.ClientTypeClaudecode, ApiKey: "valid-api-key-123", Operation: installer.OperationInstall},
+		{Client: installer.ClientTypeCodex, ApiKey: "valid-api-key-123", Operation: installer.OperationInstall},
+		{Client: installer.ClientTypeOpencode, ApiKey: "short", Operation: installer.OperationInstall},
+	}
+
+	results := app.ExecuteBatch(context.Background(), configs, installer.BatchOptions{Concurrency: 2})
+
+	if len(results) != len(configs) {
+		t.Fatalf("ExecuteBatch() returned %d results, want %d", len(results), len(configs))
+	}
+
+	for idx, result := range results {
+		if result.Client != configs[idx].Client {
+			t.Errorf("ExecuteBatch()[%d].Client = %v, want %v", idx, result.Client, configs[idx].Client)
+		}
+	}
+
+	if results[0].Err != nil || results[1].Err != nil {
+		t.Errorf("ExecuteBatch() unexpected errors: %v, %v", results[0].Err, results[1].Err)
+	}
+
+	if results[2].Err == nil {
+		t.Errorf("ExecuteBatch()[2].Err = nil, want invalid API key error")
+	}
+}
+
+func TestApplication_ExecuteBatch_TimesOutHungClient(t *testing.T) {
+	hung := &MockInstaller{configPath: "/test/hung.json", hang: make(chan struct{})}
+	defer close(hung.hang)
+
+	mockFactory := &MockFactory{
+		installer: &MockInstaller{configPath: "/test/config.json"},
+		clients:   map[installer.ClientType]ports.Installer{installer.ClientTypeCodex: hung},
+	}
+	app := New(mockFactory, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, &MockPolicies{}, nil)
+
+	configs := []*installer.Config{
+		{Client: installer.ClientTypeClaudecode, ApiKey: "valid-api-key-123", Operation: installer.OperationInstall},
+		{Client: installer.ClientTypeCodex, ApiKey: "valid-api-key-123", Operation: installer.OperationInstall},
+	}
+
+	start := time.Now()
+	results := app.ExecuteBatch(context.Background(), configs, installer.BatchOptions{Concurrency: 2, Timeout: 50 * time.Millisecond})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("ExecuteBatch() took %s, want it bounded by the timeout", elapsed)
+	}
+
+	if results[0].Err != nil {
+		t.Errorf("ExecuteBatch()[0].Err = %v, want nil", results[0].Err)
+	}
+	if !errors.Is(results[1].Err, context.DeadlineExceeded) {
+		t.Errorf("ExecuteBatch()[1].Err = %v, want %v", results[1].Err, context.DeadlineExceeded)
+	}
+}
+
+// pathLocker serializes config locks per path, as the file locks do between
+// goroutines of one process.
+type pathLocker struct {
+	MockLocker
+
+	mu   sync.Mutex
+	held map[string]chan struct{}
+}
+
+func (l *pathLocker) LockConfig(ctx context.Context, configPath string, timeout time.Duration) (ports.Unlocker, error) {
+	deadline := time.After(timeout)
+	for {
+		l.mu.Lock()
+		released, busy := l.held[configPath]
+		if !busy {
+			if l.held == nil {
+				l.held = make(map[string]chan struct{})
+			}
+			released = make(chan struct{})
+			l.held[configPath] = released
+			l.mu.Unlock()
+
+			return unlockFunc(func() error {
+				l.mu.Lock()
+				defer l.mu.Unlock()
+				delete(l.held, configPath)
+				close(released)
+				return nil
+			}), nil
+		}
+		l.mu.Unlock()
+
+		select {
+		case <-released:
+		case <-deadline:
+			return nil, domainErrors.ErrLocked
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+type unlockFunc func() error
+
+func (f unlockFunc) Unlock() error {
+	return f()
+}
+
+// slowInstaller saves only once release is closed, then closes saved.
+type slowInstaller struct {
+	*MockInstaller
+	release chan struct{}
+	saved   chan struct{}
+}
+
+func (s *slowInstaller) SaveConfig(ctx context.Context, config interface{}) error {
+	<-s.release
+	close(s.saved)
+	return nil
+}
+
+// orderedBackupStore records whether configPath was restored before saved was
+// closed.
+type orderedBackupStore struct {
+	*MockBackupStore
+	configPath string
+	saved      chan struct{}
+	early      bool
+}
+
+func (s *orderedBackupStore) Restore(ctx context.Context, backup *installer.Backup) error {
+	if backup.ConfigPath == s.configPath {
+		select {
+		case <-s.saved:
+		default:
+			s.early = true
+		}
+	}
+	return s.MockBackupStore.Restore(ctx, backup)
+}
+
+func TestApplication_ExecuteTransaction_RollbackWaitsForAbandonedClient(t *testing.T) {
+	slow := &slowInstaller{
+		MockInstaller: &MockInstaller{configPath: "/test/slow.json", configExists: true},
+		release:       make(chan struct{}),
+		saved:         make(chan struct{}),
+	}
+	mockFactory := &MockFactory{
+		installer: &MockInstaller{configPath: "/test/config.json", configExists: true},
+		clients:   map[installer.ClientType]ports.Installer{installer.ClientTypeCodex: slow},
+	}
+	mockStore := &orderedBackupStore{MockBackupStore: &MockBackupStore{}, configPath: "/test/slow.json", saved: slow.saved}
+	app := New(mockFactory, &pathLocker{}, mockStore, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, &MockPolicies{}, nil)
+
+	configs := []*installer.Config{
+		{Client: installer.ClientTypeClaudecode, ApiKey: "valid-api-key-123", Operation: installer.OperationInstall},
+		{Client: installer.ClientTypeCodex, ApiKey: "valid-api-key-123", Operation: installer.OperationInstall},
+	}
+
+	// The slow client saves well after its timeout, while the rollback runs.
+	time.AfterFunc(200*time.Millisecond, func() { close(slow.release) })
+
+	_, err := app.ExecuteTransaction(context.Background(), configs, installer.BatchOptions{Concurrency: 2, Timeout: 50 * time.Millisecond})
+	if !errors.Is(err, domainErrors.ErrTransactionRolledBack) {
+		t.Fatalf("ExecuteTransaction() error = %v, want %v", err, domainErrors.ErrTransactionRolledBack)
+	}
+
+	if mockStore.early {
+		t.Error("rollback restored the slow client before its save returned, want it to wait for the config lock")
+	}
+	if mockStore.restoreCalls != 2 {
+		t.Errorf("Restore() calls = %d, want 2", mockStore.restoreCalls)
+	}
+}
+
+func TestApplication_ExecuteTransaction_AuditsRolledBackRemoval(t *testing.T) {
+	mockFactory := &MockFactory{
+		installer: &MockInstaller{configPath: "/test/config.json", configExists: true, hasServer: true},
+		clients: map[installer.ClientType]ports.Installer{
+			installer.ClientTypeCodex: &MockInstaller{configPath: "/test/codex.toml", configExists: true, hasServer: true, shouldFailSave: true},
+		},
+	}
+	auditLog := &MockAuditLog{}
+	app := New(mockFactory, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, auditLog, &MockStateStore{}, nil, &MockPolicies{}, nil)
+
+	configs := []*installer.Config{
+		{Client: installer.ClientTypeClaudecode, Operation: installer.OperationRemove},
+		{Client: installer.ClientTypeCodex, Operation: installer.OperationRemove},
+	}
+	if _, err := app.ExecuteTransaction(context.Background(), configs, installer.BatchOptions{Concurrency: 1}); !errors.Is(err, domainErrors.ErrTransactionRolledBack) {
+		t.Fatalf("ExecuteTransaction() error = %v, want %v", err, domainErrors.ErrTransactionRolledBack)
+	}
+
+	rolledBack := 0
+	for _, record := range auditLog.records {
+		if record.Result != installer.AuditResultRolledBack {
+			continue
+		}
+		rolledBack++
+		if record.KeyFingerprint != security.KeyFingerprint("test-key") {
+			t.Errorf("rollback audit record of %s key fingerprint = %q, want the removed key's", record.Client, record.KeyFingerprint)
+		}
+	}
+	if rolledBack != 2 {
+		t.Errorf("audit holds %d rollback records, want 2", rolledBack)
+	}
+	if configs[0].ApiKey != "" {
+		t.Errorf("remove set the config's API key to %q, want it left empty", configs[0].ApiKey)
+	}
+}
+
+func TestApplication_ExecuteTransaction_RollsBackOnFailure(t *testing.T) {
+	mockInstaller := &MockInstaller{
+		configPath:   "/test/config.json",
+		configExists: true,
+		hasServer:    false,
+	}
+
+	mockFactory := &MockFactory{installer: mockInstaller}
+	mockStore := &MockBackupStore{}
+	app := New(mockFactory, &MockLocker{}, mockStore, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, &MockPolicies{}, nil)
+
+	configs := []*installer.Config{
+		{Client: installer.ClientTypeClaudecode, ApiKey: "valid-api-key-123", Operation: installer.OperationInstall},
+		{Client: installer.ClientTypeCodex, ApiKey: "short", Operation: installer.OperationInstall},
+	}
+
+	results, err := app.ExecuteTransaction(context.Background(), configs, installer.BatchOptions{Concurrency: 1})
+	if !errors.Is(err, domainErrors.ErrTransactionRolledBack) {
+		t.Fatalf("ExecuteTransaction() error = %v, want %v", err, domainErrors.ErrTransactionRolledBack)
+	}
+
+	if !results[0].RolledBack {
+		t.Errorf("ExecuteTransaction()[0].RolledBack = false, want true")
+	}
+
+	if mockStore.restoreCalls != 2 {
+		t.Errorf("Restore() called %d times, want 2", mockStore.restoreCalls)
+	}
+}
+
+func TestApplication_Execute_RecordsAudit(t *testing.T) {
+	mockInstaller := &MockInstaller{configPath: "/test/config.json"}
+	auditLog := &MockAuditLog{}
+	app := New(&MockFactory{installer: mockInstaller}, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, auditLog, &MockStateStore{}, nil, &MockPolicies{}, nil)
+
+	config := &installer.Config{
+		Client:    installer.ClientTypeClaudecode,
+		ApiKey:    "valid-api-key-123",
+		Operation: installer.OperationInstall,
+	}
+	if _, err := app.Execute(context.Background(), config); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if len(auditLog.records) != 1 {
+		t.Fatalf("audit records = %d, want 1", len(auditLog.records))
+	}
+
+	record := auditLog.records[0]
+	if record.OperationID != config.ID || record.Result != installer.AuditResultSuccess {
+		t.Errorf("audit record = %+v, want operation %s with success", record, config.ID)
+	}
+	if record.KeyFingerprint == "" || strings.Contains(record.KeyFingerprint, config.ApiKey) {
+		t.Errorf("audit record key fingerprint = %q, want a fingerprint that hides the key", record.KeyFingerprint)
+	}
+
+	mockInstaller.hasServer = true
+	removeConfig := &installer.Config{Client: installer.ClientTypeClaudecode, Operation: installer.OperationRemove}
+	if _, err := app.Execute(context.Background(), removeConfig); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if removed := auditLog.records[1]; removed.KeyFingerprint != security.KeyFingerprint("test-key") {
+		t.Errorf("remove audit record key fingerprint = %q, want the removed key's", removed.KeyFingerprint)
+	}
+	if removeConfig.ApiKey != "" {
+		t.Errorf("remove set the config's API key to %q, want it left empty", removeConfig.ApiKey)
+	}
+}
+
+func TestApplication_Undo(t *testing.T) {
+	mockInstaller := &MockInstaller{
+		configPath:   "/test/config.json",
+		configExists: true,
+	}
+
+	mockStore := &MockBackupStore{}
+	app := New(&MockFactory{installer: mockInstaller}, &MockLocker{}, mockStore, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, &MockPolicies{}, nil)
+
+	installResult, err := app.Execute(context.Background(), &installer.Config{
+		Client:    installer.ClientTypeClaudecode,
+		ApiKey:    "valid-api-key-123",
+		Operation: installer.OperationInstall,
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if installResult.OperationID == "" {
+		t.Fatalf("Execute().OperationID is empty")
+	}
+
+	mockInstaller.isRunning = true
+	_, err = app.Undo(context.Background(), "", &installer.Config{Operation: installer.OperationUndo})
+	var runningErr *installer.ClientRunningError
+	if !errors.As(err, &runningErr) || runningErr.Client != installer.ClientTypeClaudecode {
+		t.Fatalf("Undo() error = %v, want the running client named", err)
+	}
+	mockInstaller.isRunning = false
+
+	mockStore.currentHash = "changed"
+	_, err = app.Undo(context.Background(), "", &installer.Config{Operation: installer.OperationUndo})
+	if !errors.Is(err, domainErrors.ErrUndoConflict) {
+		t.Fatalf("Undo() error = %v, want %v", err, domainErrors.ErrUndoConflict)
+	}
+	if mockStore.restoreCalls != 0 {
+		t.Errorf("Undo() restored %d files despite a conflict", mockStore.restoreCalls)
+	}
+
+	mockStore.currentHash = ""
+	result, err := app.Undo(context.Background(), "", &installer.Config{Operation: installer.OperationUndo})
+	if err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if result.OperationID != installResult.OperationID {
+		t.Errorf("Undo().OperationID = %s, want %s", result.OperationID, installResult.OperationID)
+	}
+	if mockStore.restoreCalls != 1 {
+		t.Errorf("Restore() called %d times, want 1", mockStore.restoreCalls)
+	}
+}
+
+func TestApplication_RestoreBackup_BlockedByPolicy(t *testing.T) {
+	mockInstaller := &MockInstaller{
+		configPath:   "/test/config.json",
+		configExists: true,
+		servers:      []*installer.McpServer{{Name: "tracker", Type: installer.TransportHTTP, URL: "https://tracker.example.net/mcp"}},
+	}
+	mockStore := &MockBackupStore{backups: []*installer.Backup{
+		{ID: "backup-1", Client: installer.ClientTypeClaudecode, ConfigPath: "/test/config.json", Existed: true, Hash: "before"},
+	}}
+	policies := &MockPolicies{policies: []*installer.Policy{
+		{Path: "/etc/policy.json", AllowedURLs: []string{"https://mcp.kirha.com/"}},
+	}}
+	app := New(&MockFactory{installer: mockInstaller}, &MockLocker{}, mockStore, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, policies, nil)
+
+	_, err := app.RestoreBackup(context.Background(), "backup-1", &installer.Config{Operation: installer.OperationRestore})
+	if !errors.Is(err, domainErrors.ErrPolicyViolation) {
+		t.Fatalf("RestoreBackup() error = %v, want %v", err, domainErrors.ErrPolicyViolation)
+	}
+	if mockStore.restoreCalls != 0 {
+		t.Errorf("Restore() called %d times, want 0", mockStore.restoreCalls)
+	}
+
+	policies.policies[0].AllowedURLs = append(policies.policies[0].AllowedURLs, "*.example.net")
+	if _, err := app.RestoreBackup(context.Background(), "backup-1", &installer.Config{Operation: installer.OperationRestore}); err != nil {
+		t.Errorf("RestoreBackup() with an allowed server error = %v", err)
+	}
+}
+
+func TestApplication_RestoreBackup_BlockedByPolicyAtLocalScope(t *testing.T) {
+	local := &MockInstaller{
+		configPath:   "/home/user/.claude.json",
+		configExists: true,
+		servers:      []*installer.McpServer{{Name: "tracker", Type: installer.TransportHTTP, URL: "https://tracker.example.net/mcp"}},
+	}
+	scoped := &MockScopedInstaller{
+		MockInstaller: &MockInstaller{configPath: "/home/user/.claude.json", configExists: true},
+		scopes:        map[installer.Scope]*MockInstaller{installer.ScopeLocal: local},
+	}
+	mockStore := &MockBackupStore{backups: []*installer.Backup{
+		{ID: "backup-1", OperationID: "op-1", Client: installer.ClientTypeClaudecode, Scope: installer.ScopeLocal, Project: "/work/app", ConfigPath: "/home/user/.claude.json", Existed: true, Hash: "before", After: &installer.FileState{Exists: true, Hash: "after"}},
+	}}
+	policies := &MockPolicies{policies: []*installer.Policy{
+		{Path: "/etc/policy.json", AllowedURLs: []string{"https://mcp.kirha.com/"}},
+	}}
+	app := New(&MockFactory{installer: scoped}, &MockLocker{}, mockStore, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, policies, nil)
+
+	_, err := app.RestoreBackup(context.Background(), "backup-1", &installer.Config{Operation: installer.OperationRestore})
+	if !errors.Is(err, domainErrors.ErrPolicyViolation) {
+		t.Fatalf("RestoreBackup() error = %v, want %v", err, domainErrors.ErrPolicyViolation)
+	}
+	if len(scoped.projects) == 0 || scoped.projects[0] != "/work/app" {
+		t.Errorf("WithScope() projects = %v, want /work/app", scoped.projects)
+	}
+
+	_, err = app.Undo(context.Background(), "op-1", &installer.Config{Operation: installer.OperationUndo})
+	if !errors.Is(err, domainErrors.ErrPolicyViolation) {
+		t.Errorf("Undo() error = %v, want %v", err, domainErrors.ErrPolicyViolation)
+	}
+	if mockStore.restoreCalls != 0 {
+		t.Errorf("Restore() called %d times, want 0", mockStore.restoreCalls)
+	}
+}
+
+func TestApplication_ManagedStatus(t *testing.T) {
+	mockInstaller := &MockInstaller{
+		configPath:   "/test/config.json",
+		configExists: true,
+		hasServer:    true,
+	}
+	server, _ := mockInstaller.GetMcpServerConfig(context.Background(), nil)
+
+	tests := []struct {
+		name    string
+		managed *installer.ManagedServer
+		want    installer.DriftStatus
+	}{
+		{
+			name: "unmanaged",
+			want: installer.DriftUnmanaged,
+		},
+		{
+			name:    "in sync",
+			managed: &installer.ManagedServer{ServerHash: server.Hash()},
+			want:    installer.DriftInSync,
+		},
+		{
+			name:    "modified",
+			managed: &installer.ManagedServer{ServerHash: "edited"},
+			want:    installer.DriftModified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &MockStateStore{}
+			if tt.managed != nil {
+				tt.managed.Client = installer.ClientTypeClaudecode
+				tt.managed.Scope = installer.ScopeUser
+				tt.managed.ConfigPath = "/test/config.json"
+				_ = state.Put(context.Background(), tt.managed)
+			}
+
+			app := New(&MockFactory{installer: mockInstaller}, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, &MockAuditLog{}, state, nil, &MockPolicies{}, nil)
+			statuses, err := app.ManagedStatus(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("ManagedStatus() error = %v", err)
+			}
+			if len(statuses) != 1 {
+				t.Fatalf("ManagedStatus() returned %d statuses, want 1", len(statuses))
+			}
+			if statuses[0].Drift != tt.want {
+				t.Errorf("ManagedStatus().Drift = %s, want %s", statuses[0].Drift, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplication_Execute_Remove_DeletesCreatedFile(t *testing.T) {
+	mockInstaller := &MockInstaller{
+		configPath:   "/test/config.json",
+		configExists: true,
+		hasServer:    true,
+	}
+
+	state := &MockStateStore{}
+	_ = state.Put(context.Background(), &installer.ManagedServer{
+		Client:      installer.ClientTypeClaudecode,
+		Scope:       installer.ScopeUser,
+		ConfigPath:  "/test/config.json",
+		CreatedFile: true,
+		ContentHash: "after",
+	})
+
+	app := New(&MockFactory{installer: mockInstaller}, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, &MockAuditLog{}, state, nil, &MockPolicies{}, nil)
+	_, err := app.Execute(context.Background(), &installer.Config{
+		Client:    installer.ClientTypeClaudecode,
+		Operation: installer.OperationRemove,
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if mockInstaller.deleteCalls != 1 {
+		t.Errorf("DeleteConfig() called %d times, want 1", mockInstaller.deleteCalls)
+	}
+	if len(state.servers) != 0 {
+		t.Errorf("state still holds %d servers after remove", len(state.servers))
+	}
+}
+
+func TestApplication_Purge(t *testing.T) {
+	mockInstaller := &MockInstaller{
+		configPath:   "/test/config.json",
+		configExists: true,
+		hasServer:    true,
+	}
+
+	state := &MockStateStore{}
+	_ = state.Put(context.Background(), &installer.ManagedServer{
+		Client:     installer.ClientTypeClaudecode,
+		Scope:      installer.ScopeUser,
+		ConfigPath: "/test/config.json",
+	})
+
+	auditLog := &MockAuditLog{}
+	app := New(&MockFactory{installer: mockInstaller}, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, auditLog, state, nil, &MockPolicies{}, nil)
+	result, err := app.Purge(context.Background(), &installer.Config{Operation: installer.OperationPurge}, installer.PurgeOptions{})
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if len(result.Clients) != 1 || result.Clients[0].Err != nil {
+		t.Fatalf("Purge().Clients = %+v, want one successful removal", result.Clients)
+	}
+	if result.ForgottenState != 1 || len(state.servers) != 0 {
+		t.Errorf("Purge() forgot %d state entries, %d left", result.ForgottenState, len(state.servers))
+	}
+	if len(auditLog.records) != 1 || auditLog.records[0].Operation != installer.OperationPurge {
+		t.Errorf("Purge() audit records = %+v, want one purge record", auditLog.records)
+	}
+}
+
+func TestApplication_Purge_ScrubsOnlyKirhaKeys(t *testing.T) {
+	mockInstaller := &MockInstaller{
+		configPath:   "/test/config.json",
+		configExists: true,
+		hasServer:    true,
+		servers: []*installer.McpServer{
+			{Name: installer.ServerName, URL: installer.ServerURL, Headers: map[string]string{"Authorization": "Bearer old-kirha-key"}},
+		},
+	}
+	backup := func(id string) *installer.Backup {
+		return &installer.Backup{ID: id, Client: installer.ClientTypeClaudecode, ConfigPath: "/test/config.json", Existed: true}
+	}
+	mockStore := &MockBackupStore{
+		backups: []*installer.Backup{backup("current"), backup("rotated"), backup("other")},
+		contents: map[string]string{
+			"current": `{"kirha":{"Authorization":"Bearer test-key"}}`,
+			"rotated": `{"kirha":{"Authorization":"Bearer old-kirha-key"}}`,
+			"other":   `{"github":{"Authorization":"Bearer ghp_token"}}`,
+		},
+	}
+
+	app := New(&MockFactory{installer: mockInstaller}, &MockLocker{}, mockStore, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, &MockPolicies{}, nil)
+	result, err := app.Purge(context.Background(), &installer.Config{Operation: installer.OperationPurge}, installer.PurgeOptions{})
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+
+	if len(result.ScrubbedBackups) != 2 {
+		t.Errorf("Purge() scrubbed %d backups, want 2", len(result.ScrubbedBackups))
+	}
+	for id, content := range mockStore.contents {
+		if strings.Contains(content, "test-key") || strings.Contains(content, "old-kirha-key") {
+			t.Errorf("backup %s still holds a Kirha key: %s", id, content)
+		}
+	}
+	if !strings.Contains(mockStore.contents["other"], "ghp_token") {
+		t.Errorf("backup of another server was scrubbed: %s", mockStore.contents["other"])
+	}
+
+	_, err = app.Purge(context.Background(), &installer.Config{Operation: installer.OperationPurge}, installer.PurgeOptions{DeleteBackups: true})
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	for _, id := range mockStore.deleted {
+		if id == "other" {
+			t.Errorf("Purge() deleted the backup of another server")
+		}
+	}
+}
+
+func TestApplication_Purge_Scopes(t *testing.T) {
+	local := &MockInstaller{configPath: "/home/user/.claude.json", configExists: true, hasServer: true}
+	scoped := &MockScopedInstaller{
+		MockInstaller: &MockInstaller{configPath: "/home/user/.claude.json", configExists: true, hasServer: true},
+		scopes:        map[installer.Scope]*MockInstaller{installer.ScopeLocal: local},
+	}
+
+	state := &MockStateStore{}
+	_ = state.Put(context.Background(), &installer.ManagedServer{
+		Client:     installer.ClientTypeClaudecode,
+		Scope:      installer.ScopeLocal,
+		ConfigPath: "/home/user/.claude.json",
+		Project:    "/work/app",
+	})
+
+	app := New(&MockFactory{installer: scoped}, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, &MockAuditLog{}, state, nil, &MockPolicies{}, nil)
+
+	statuses, err := app.ManagedStatus(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ManagedStatus() error = %v", err)
+	}
+	if len(statuses) != 2 || statuses[1].Scope != installer.ScopeLocal || statuses[1].Project != "/work/app" {
+		t.Fatalf("ManagedStatus() = %+v, want the user and the local scope", statuses)
+	}
+
+	result, err := app.Purge(context.Background(), &installer.Config{Operation: installer.OperationPurge}, installer.PurgeOptions{})
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if len(result.Clients) != 2 {
+		t.Fatalf("Purge().Clients = %+v, want the user and the local entry removed", result.Clients)
+	}
+	if result.Clients[1].Scope != installer.ScopeLocal || result.Clients[1].Err != nil {
+		t.Errorf("Purge().Clients[1] = %+v, want a successful local removal", result.Clients[1])
+	}
+	if len(state.servers) != 0 {
+		t.Errorf("Purge() left %d state entries", len(state.servers))
+	}
+}
+
+func TestApplication_RotateKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		oldKey string
+		want   installer.KeyRotationStatus
+	}{
+		{name: "any key", want: installer.KeyRotated},
+		{name: "matching old key", oldKey: "test-key", want: installer.KeyRotated},
+		{name: "other old key", oldKey: "revoked-key", want: installer.KeyOtherKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockInstaller := &MockInstaller{
+				configPath:   "/test/config.json",
+				configExists: true,
+				hasServer:    true,
+			}
+			app := New(&MockFactory{installer: mockInstaller}, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, &MockPolicies{}, nil)
+
+			result, err := app.RotateKey(context.Background(), &installer.Config{
+				ApiKey:    "new-api-key-123",
+				Operation: installer.OperationUpdate,
+			}, tt.oldKey, installer.BatchOptions{})
+			if err != nil {
+				t.Fatalf("RotateKey() error = %v", err)
+			}
+			if len(result.Clients) != 1 || result.Clients[0].Status != tt.want {
+				t.Errorf("RotateKey().Clients = %+v, want status %s", result.Clients, tt.want)
+			}
+		})
+	}
+
+	t.Run("keeps the profile of the entry", func(t *testing.T) {
+		mockInstaller := &MockInstaller{
+			configPath:   "/test/config.json",
+			configExists: true,
+			hasServer:    true,
+			kirha: &installer.McpServer{
+				Name:    installer.ServerName,
+				Type:    "http",
+				URL:     "https://team.example.com/mcp",
+				Headers: map[string]string{"Authorization": "Bearer test-key", "X-Team": "core"},
+			},
+		}
+		app := New(&MockFactory{installer: mockInstaller}, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, &MockPolicies{}, nil)
+
+		if _, err := app.RotateKey(context.Background(), &installer.Config{
+			ApiKey:    "new-api-key-123",
+			Operation: installer.OperationUpdate,
+		}, "", installer.BatchOptions{}); err != nil {
+			t.Fatalf("RotateKey() error = %v", err)
+		}
+
+		if len(mockInstaller.added) != 1 {
+			t.Fatalf("added %d servers, want 1", len(mockInstaller.added))
+		}
+		added := mockInstaller.added[0]
+		if added.URL != "https://team.example.com/mcp" || added.Headers["X-Team"] != "core" {
+			t.Errorf("rotated entry = %+v, want the team URL and headers", added)
+		}
+		if added.ApiKey() != "new-api-key-123" {
+			t.Errorf("rotated entry key = %s, want new-api-key-123", added.ApiKey())
+		}
+	})
+
+	t.Run("unreadable client aborts", func(t *testing.T) {
+		mockInstaller := &MockInstaller{
+			configPath:     "/test/config.json",
+			configExists:   true,
+			hasServer:      true,
+			shouldFailLoad: true,
+		}
+		app := New(&MockFactory{installer: mockInstaller}, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, &MockPolicies{}, nil)
+
+		result, err := app.RotateKey(context.Background(), &installer.Config{
+			ApiKey:    "new-api-key-123",
+			Operation: installer.OperationUpdate,
+		}, "", installer.BatchOptions{})
+		if !errors.Is(err, domainErrors.ErrTransactionRolledBack) {
+			t.Fatalf("RotateKey() error = %v, want %v", err, domainErrors.ErrTransactionRolledBack)
+		}
+		if len(result.Clients) != 1 || result.Clients[0].Status != installer.KeyFailed {
+			t.Errorf("RotateKey().Clients = %+v, want status %s", result.Clients, installer.KeyFailed)
+		}
+	})
+}
+
+func TestApplication_Update_KeepsProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		profiles map[string]installer.ServerProfile
+		wantURL  string
+		want     string
+	}{
+		{
+			name:     "recorded profile",
+			profiles: map[string]installer.ServerProfile{"team": {URL: "https://team.example.com/mcp"}},
+			wantURL:  "https://team.example.com/mcp",
+			want:     "team",
+		},
+		{
+			name:    "deleted profile",
+			wantURL: installer.ServerURL,
+			want:    installer.DefaultProfile,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockInstaller := &MockInstaller{
+				configPath:   "/test/config.json",
+				configExists: true,
+				hasServer:    true,
+			}
+			state := &MockStateStore{}
+			_ = state.Put(context.Background(), &installer.ManagedServer{
+				Client:     installer.ClientTypeClaudecode,
+				Scope:      installer.ScopeUser,
+				ConfigPath: "/test/config.json",
+				Profile:    "team",
+			})
+			app := New(&MockFactory{installer: mockInstaller}, &MockLocker{}, &MockBackupStore{}, &MockSettings{profiles: tt.profiles}, &MockAuditLog{}, state, nil, &MockPolicies{}, nil)
+
+			if _, err := app.Execute(context.Background(), &installer.Config{
+				Client:    installer.ClientTypeClaudecode,
+				ApiKey:    "new-api-key-123",
+				Operation: installer.OperationUpdate,
+			}); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if len(mockInstaller.added) != 1 || mockInstaller.added[0].URL != tt.wantURL {
+				t.Fatalf("updated entry = %+v, want URL %s", mockInstaller.added, tt.wantURL)
+			}
+			if len(state.servers) != 1 || state.servers[0].Profile != tt.want {
+				t.Errorf("recorded profile = %+v, want %s", state.servers, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplication_Sync_FailedClientAborts(t *testing.T) {
+	mockInstaller := &MockInstaller{
+		configPath:     "/test/config.json",
+		binaryPath:     "/usr/bin/client",
+		configExists:   true,
+		shouldFailLoad: true,
+	}
+	app := New(&MockFactory{installer: mockInstaller}, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, &MockPolicies{}, nil)
+
+	result, err := app.Sync(context.Background(), &installer.Config{
+		ApiKey:    "new-api-key-123",
+		Operation: installer.OperationUpdate,
+	}, "", installer.DefaultProfile, installer.BatchOptions{})
+	if !errors.Is(err, domainErrors.ErrTransactionRolledBack) {
+		t.Fatalf("Sync() error = %v, want %v", err, domainErrors.ErrTransactionRolledBack)
+	}
+	if result == nil || len(result.Clients) == 0 || result.Clients[0].Status != installer.SyncFailed {
+		t.Errorf("Sync() = %+v, want a failed client", result)
+	}
+	if len(mockInstaller.added) != 0 {
+		t.Errorf("Sync() changed %d entries, want none", len(mockInstaller.added))
+	}
+}
+
+func TestApplication_Sync_RecordsSourceProfile(t *testing.T) {
+	source := &MockInstaller{
+		configPath:   "/test/claude.json",
+		binaryPath:   "/usr/bin/claude",
+		configExists: true,
+		hasServer:    true,
+		kirha: &installer.McpServer{
+			Name:    installer.ServerName,
+			Type:    installer.TransportHTTP,
+			URL:     "https://eu.mcp.kirha.com/mcp",
+			Headers: map[string]string{"Authorization": "Bearer source-key-123"},
+		},
+	}
+	target := &MockInstaller{
+		configPath:   "/test/codex.toml",
+		binaryPath:   "/usr/bin/codex",
+		configExists: true,
+		hasServer:    true,
+	}
+	mockFactory := &MockFactory{
+		installer: source,
+		clients:   map[installer.ClientType]ports.Installer{installer.ClientTypeCodex: target},
+		supported: []installer.ClientType{installer.ClientTypeClaudecode, installer.ClientTypeCodex},
+	}
+
+	state := &MockStateStore{}
+	_ = state.Put(context.Background(), &installer.ManagedServer{
+		Client:     installer.ClientTypeCodex,
+		Scope:      installer.ScopeUser,
+		ConfigPath: "/test/codex.toml",
+		Profile:    "team",
+	})
+
+	app := New(mockFactory, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, &MockAuditLog{}, state, nil, &MockPolicies{}, nil)
+	if _, err := app.Sync(context.Background(), &installer.Config{Operation: installer.OperationUpdate}, installer.ClientTypeClaudecode, "", installer.BatchOptions{}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	managed, _ := state.Get(context.Background(), installer.ManagedKey{Client: installer.ClientTypeCodex, Scope: installer.ScopeUser, ConfigPath: "/test/codex.toml"})
+	if managed == nil || managed.Profile != installer.DefaultProfile {
+		t.Errorf("recorded profile = %+v, want %s as recorded for the source", managed, installer.DefaultProfile)
+	}
+}
+
+func TestApplication_Copy(t *testing.T) {
+	source := &MockInstaller{
+		servers: []*installer.McpServer{
+			installer.NewKirhaRemoteMcpServer("test-key"),
+			{Name: "docs", Type: installer.TransportSSE, URL: "https://docs.example.com/sse"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		names   []string
+		present []*installer.McpServer
+		wantErr error
+		want    map[string]installer.CopyStatus
+	}{
+		{
+			name: "all servers",
+			want: map[string]installer.CopyStatus{"kirha": installer.CopyCopied, "docs": installer.CopyCopied},
+		},
+		{
+			name:    "existing server is kept",
+			present: []*installer.McpServer{{Name: "docs"}},
+			want:    map[string]installer.CopyStatus{"kirha": installer.CopyCopied, "docs": installer.CopyExists},
+		},
+		{
+			name:  "selected server",
+			names: []string{"docs"},
+			want:  map[string]installer.CopyStatus{"docs": installer.CopyCopied},
+		},
+		{
+			name:    "unknown server",
+			names:   []string{"missing"},
+			wantErr: domainErrors.ErrServerNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := &MockInstaller{
+				configPath:  "/test/opencode.json",
+				servers:     tt.present,
+				unsupported: []string{"transport sse"},
+			}
+			factory := &MockFactory{clients: map[installer.ClientType]ports.Installer{
+				installer.ClientTypeClaudecode: source,
+				installer.ClientTypeOpencode:   target,
+			}}
+			app := New(factory, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, &MockPolicies{}, nil)
+
+			result, err := app.Copy(context.Background(), &installer.Config{
+				Client:    installer.ClientTypeOpencode,
+				Operation: installer.OperationCopy,
+			}, installer.ClientTypeClaudecode, tt.names)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Copy() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Copy() error = %v", err)
+			}
+
+			if len(result.Servers) != len(tt.want) {
+				t.Fatalf("Copy().Servers = %+v, want %d servers", result.Servers, len(tt.want))
+			}
+			copied := 0
+			for _, server := range result.Servers {
+				if server.Status != tt.want[server.Name] {
+					t.Errorf("server %s status = %s, want %s", server.Name, server.Status, tt.want[server.Name])
+				}
+				if server.Status == installer.CopyCopied {
+					copied++
+					if len(server.Warnings) == 0 {
+						t.Errorf("server %s has no warnings", server.Name)
+					}
+				}
+			}
+			if len(target.added) != copied {
+				t.Errorf("AddMcpServer() called %d times, want %d", len(target.added), copied)
+			}
+		})
+	}
+}
+
 func TestApplication_validateApiKey(t *testing.T) {
 	app := &Application{}
 
@@ -235,3 +1538,146 @@ func TestApplication_validateApiKey(t *testing.T) {
 		})
 	}
 }
+
+type MockProber struct {
+	status int
+	probed []string
+}
+
+func (p *MockProber) Probe(ctx context.Context, server *installer.McpServer) (int, error) {
+	p.probed = append(p.probed, server.URL)
+	return p.status, nil
+}
+
+func TestApplication_Doctor(t *testing.T) {
+	configPath := t.TempDir() + "/config.json"
+	if err := os.WriteFile(configPath, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	mockInstaller := &MockInstaller{
+		configPath:   configPath,
+		configExists: true,
+		servers: []*installer.McpServer{
+			{Name: installer.ServerName, Type: "http", URL: installer.ServerURL, Headers: map[string]string{"Authorization": "Token valid-api-key-123"}},
+			{Name: "kirha-backup", Type: "http", URL: installer.ServerURL + "/mcp"},
+		},
+	}
+	app := New(&MockFactory{installer: mockInstaller}, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, &MockPolicies{}, &MockProber{status: 401})
+
+	diagnoses, err := app.Doctor(context.Background(), installer.DoctorOptions{Clients: []installer.ClientType{installer.ClientTypeCodex}})
+	if err != nil {
+		t.Fatalf("Doctor() error = %v", err)
+	}
+	if len(diagnoses) != 1 {
+		t.Fatalf("Doctor() returned %d diagnoses, want 1", len(diagnoses))
+	}
+
+	severities := make(map[installer.DiagnosisCheck]installer.Severity)
+	for _, finding := range diagnoses[0].Findings {
+		severities[finding.Check] = finding.Severity
+		if finding.Severity != installer.SeverityOK && finding.Hint == "" {
+			t.Errorf("finding %s has no remediation hint", finding.Check)
+		}
+	}
+
+	want := map[installer.DiagnosisCheck]installer.Severity{
+		installer.CheckConfig:      installer.SeverityOK,
+		installer.CheckPermissions: installer.SeverityOK,
+		installer.CheckKirhaEntry:  installer.SeverityError,
+		installer.CheckDuplicates:  installer.SeverityWarning,
+		installer.CheckEndpoint:    installer.SeverityError,
+	}
+	for check, severity := range want {
+		if severities[check] != severity {
+			t.Errorf("%s severity = %q, want %q", check, severities[check], severity)
+		}
+	}
+}
+
+func TestApplication_Doctor_SkipsUntrustedEndpoints(t *testing.T) {
+	configPath := t.TempDir() + "/config.json"
+	if err := os.WriteFile(configPath, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, endpoint := range []string{"http://mcp.kirha.com/", "https://collector.example.net/mcp"} {
+		t.Run(endpoint, func(t *testing.T) {
+			mockInstaller := &MockInstaller{
+				configPath:   configPath,
+				configExists: true,
+				servers: []*installer.McpServer{
+					{Name: installer.ServerName, Type: "http", URL: endpoint, Headers: map[string]string{"Authorization": "Bearer valid-api-key-123"}},
+				},
+			}
+			prober := &MockProber{status: 200}
+			app := New(&MockFactory{installer: mockInstaller}, &MockLocker{}, &MockBackupStore{}, &MockSettings{}, &MockAuditLog{}, &MockStateStore{}, nil, &MockPolicies{}, prober)
+
+			diagnoses, err := app.Doctor(context.Background(), installer.DoctorOptions{Clients: []installer.ClientType{installer.ClientTypeCodex}})
+			if err != nil {
+				t.Fatalf("Doctor() error = %v", err)
+			}
+			if len(prober.probed) != 0 {
+				t.Errorf("Doctor() probed %v, want no probe", prober.probed)
+			}
+			for _, finding := range diagnoses[0].Findings {
+				if finding.Check == installer.CheckEndpoint && finding.Severity == installer.SeverityOK {
+					t.Errorf("endpoint finding = %+v, want it reported as not probed", finding)
+				}
+			}
+		})
+	}
+}
+
+func TestApplication_Repair(t *testing.T) {
+	configPath := t.TempDir() + "/config.json"
+	if err := os.WriteFile(configPath, []byte("{\"mcpServers\": {},}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	mockInstaller := &MockInstaller{
+		configPath: configPath,
+		parseErr:   &installer.ParseError{Path: configPath, Line: 1, Column: 19, Message: "invalid character '}'"},
+		fixes:      []string{"line 1, column 18: removed trailing comma"},
+		fixed:      []byte("{\"mcpServers\": {}}"),
+	}
+	backups := &MockBackupStore{currentHash: "broken"}
+	auditLog := &MockAuditLog{}
+	app := New(&MockFactory{installer: mockInstaller}, &MockLocker{}, backups, &MockSettings{}, auditLog, &MockStateStore{}, nil, &MockPolicies{}, nil)
+
+	plan, err := app.PlanRepair(context.Background(), installer.ClientTypeCodex)
+	if err != nil {
+		t.Fatalf("PlanRepair() error = %v", err)
+	}
+	if !errors.Is(plan.ParseErr, domainErrors.ErrConfigInvalid) || !plan.CanFix() {
+		t.Fatalf("PlanRepair() = %+v, want a fixable parse error", plan)
+	}
+	if plan.Available(installer.RepairRestore) {
+		t.Error("restore is available without a parseable backup")
+	}
+
+	backups.currentHash = "changed"
+	if _, err := app.Repair(context.Background(), &installer.Config{Operation: installer.OperationRepair}, plan, installer.RepairFix); !errors.Is(err, domainErrors.ErrConfigModified) {
+		t.Fatalf("Repair() of a modified file error = %v, want ErrConfigModified", err)
+	}
+
+	backups.currentHash = "broken"
+	result, err := app.Repair(context.Background(), &installer.Config{Operation: installer.OperationRepair}, plan, installer.RepairFix)
+	if err != nil {
+		t.Fatalf("Repair() error = %v", err)
+	}
+	if result.OperationID == "" || result.BackupID == "" {
+		t.Errorf("Repair() result = %+v, want an operation and a backup", result)
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != string(mockInstaller.fixed) {
+		t.Errorf("config content = %s, want %s", content, mockInstaller.fixed)
+	}
+	if len(auditLog.records) != 1 || auditLog.records[0].Operation != installer.OperationRepair {
+		t.Errorf("audit records = %+v, want one repair", auditLog.records)
+	}
+}
@@ -0,0 +1,102 @@
+package installer
+
+import (
+	"context"
+	"log/slog"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/pkg/security"
+)
+
+func (a *Application) ReadAuditLog(ctx context.Context, filter installer.AuditFilter) ([]*installer.AuditRecord, error) {
+	return a.auditLog.Read(ctx, filter)
+}
+
+// auditOperation records the outcome of an install, update or remove that
+// wrote or removed key. The before state comes from the backup taken by the
+// operation, and is left empty when it failed before taking one.
+func (a *Application) auditOperation(ctx context.Context, config *installer.Config, key string, result *installer.InstallResult, err error) {
+	record := &installer.AuditRecord{
+		OperationID:    config.ID,
+		Operation:      config.Operation,
+		Client:         config.Client,
+		Server:         installer.ServerName,
+		KeyFingerprint: security.KeyFingerprint(key),
+		Result:         installer.AuditResultSuccess,
+	}
+	if err != nil {
+		record.Result = installer.AuditResultFailed
+		record.Error = err.Error()
+	}
+
+	if clientInstaller, getErr := a.configInstaller(ctx, config); getErr == nil {
+		record.ConfigPath, _ = clientInstaller.GetConfigPath()
+	}
+
+	if backup := a.operationBackup(ctx, config, result, record.ConfigPath); backup != nil {
+		record.ConfigPath = backup.ConfigPath
+		record.BeforeHash = backup.Hash
+	}
+
+	if record.ConfigPath != "" {
+		if state, stateErr := a.backups.FileState(ctx, record.ConfigPath); stateErr == nil {
+			record.AfterHash = state.Hash
+		}
+	}
+
+	a.appendAudit(ctx, record)
+}
+
+// operationBackup returns the backup an operation took of configPath: the one
+// its result names, or for a failed operation the first one it took of that
+// file.
+func (a *Application) operationBackup(ctx context.Context, config *installer.Config, result *installer.InstallResult, configPath string) *installer.Backup {
+	if result != nil && result.BackupID != "" {
+		backup, err := a.backups.Get(ctx, result.BackupID)
+		if err == nil {
+			return backup
+		}
+	}
+
+	backups, err := a.backups.List(ctx, installer.BackupFilter{OperationID: config.ID, Client: config.Client})
+	if err != nil {
+		return nil
+	}
+
+	// The list is newest first.
+	for idx := len(backups) - 1; idx >= 0; idx-- {
+		if backups[idx].ConfigPath == configPath {
+			return backups[idx]
+		}
+	}
+	return nil
+}
+
+func (a *Application) auditBackupChange(ctx context.Context, config *installer.Config, backup *installer.Backup, before installer.FileState, err error) {
+	record := &installer.AuditRecord{
+		OperationID: config.ID,
+		Operation:   config.Operation,
+		Client:      backup.Client,
+		ConfigPath:  backup.ConfigPath,
+		BeforeHash:  before.Hash,
+		AfterHash:   backup.Hash,
+		Result:      installer.AuditResultSuccess,
+	}
+	if err != nil {
+		record.AfterHash = before.Hash
+		record.Result = installer.AuditResultFailed
+		record.Error = err.Error()
+	}
+
+	a.appendAudit(ctx, record)
+}
+
+// appendAudit writes a record to the audit log. A failure to write it is
+// logged but does not fail the operation, which has already happened.
+func (a *Application) appendAudit(ctx context.Context, record *installer.AuditRecord) {
+	if err := a.auditLog.Append(ctx, record); err != nil {
+		slog.WarnContext(ctx, "failed to write audit record",
+			slog.String("operation_id", record.OperationID),
+			slog.String("error", err.Error()))
+	}
+}
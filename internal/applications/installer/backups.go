@@ -0,0 +1,173 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+func (a *Application) ListBackups(ctx context.Context, filter installer.BackupFilter) ([]*installer.Backup, error) {
+	return a.backups.List(ctx, filter)
+}
+
+// GetBackup returns a backup along with the configuration content it holds.
+func (a *Application) GetBackup(ctx context.Context, id string) (*installer.Backup, []byte, error) {
+	backup, err := a.backups.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := a.backups.Content(ctx, backup)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return backup, content, nil
+}
+
+// RestoreBackup writes a backup back over its configuration file. The current
+// content is backed up first so that the restore itself can be reverted.
+func (a *Application) RestoreBackup(ctx context.Context, id string, config *installer.Config) (*installer.InstallResult, error) {
+	backup, err := a.backups.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := a.backups.Content(ctx, backup)
+	if err != nil {
+		return nil, err
+	}
+
+	clientInstaller, err := a.backupInstaller(ctx, backup)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.enforceContentPolicy(ctx, backup.Client, clientInstaller, content); err != nil {
+		return nil, err
+	}
+
+	running, err := clientInstaller.IsClientRunning(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to check if client is running", slog.String("error", err.Error()))
+	}
+	if running && !config.DryRun && !config.Force {
+		return nil, errors.ErrClientRunning
+	}
+
+	if config.DryRun {
+		return &installer.InstallResult{
+			Success:    true,
+			ConfigPath: backup.ConfigPath,
+			Message:    fmt.Sprintf("Would restore backup %s to %s", backup.ID, backup.ConfigPath),
+		}, nil
+	}
+
+	unlockInstaller, err := a.lockInstaller(ctx, config.LockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer unlockInstaller()
+
+	unlockConfig, err := a.lockConfigFile(ctx, backup.ConfigPath, config.LockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer unlockConfig()
+
+	current, err := a.backups.FileState(ctx, backup.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	if current.Exists == backup.Existed && current.Hash == backup.Hash {
+		return &installer.InstallResult{
+			Success:    true,
+			ConfigPath: backup.ConfigPath,
+			Message:    fmt.Sprintf("%s already matches backup %s, nothing to restore", backup.ConfigPath, backup.ID),
+		}, nil
+	}
+
+	assignOperationID([]*installer.Config{config})
+	config.Client = backup.Client
+	config.Scope = backup.Scope
+	config.Project = backup.Project
+
+	previous, err := a.createBackup(ctx, config, backup.ConfigPath)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to back up current config before restoring", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	err = a.backups.Restore(ctx, backup)
+	a.auditBackupChange(ctx, config, backup, current, err)
+	if err != nil {
+		return nil, err
+	}
+
+	previous = a.recordAfter(ctx, nil, previous)
+	a.reconcileManaged(ctx, backup.Client, backup.ConfigPath)
+	a.applyRetention(ctx)
+
+	message := fmt.Sprintf("Restored backup %s to %s", backup.ID, backup.ConfigPath)
+	if backup.Scrubbed {
+		message += ". Its API keys had been redacted, set a key again with 'mcp-installer update --key'"
+	}
+	if running {
+		message += ". Please restart the application to apply changes."
+	}
+
+	return &installer.InstallResult{
+		Success:     true,
+		ConfigPath:  backup.ConfigPath,
+		BackupID:    backupID(previous),
+		OperationID: config.ID,
+		Message:     message,
+	}, nil
+}
+
+// PruneBackups deletes the backups outside policy. A nil policy uses the
+// retention configured in the installer settings.
+func (a *Application) PruneBackups(ctx context.Context, policy *installer.RetentionPolicy, dryRun bool) ([]*installer.Backup, error) {
+	if policy == nil {
+		settings, err := a.settings.Load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		policy = &settings.Backups
+	}
+
+	if !dryRun {
+		unlock, err := a.lockInstaller(ctx, defaultLockTimeout)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
+	return a.backups.Prune(ctx, *policy, time.Now(), dryRun)
+}
+
+// applyRetention prunes old backups after new ones were taken. Failures are
+// only logged since they never affect the operation itself.
+func (a *Application) applyRetention(ctx context.Context) {
+	settings, err := a.settings.Load(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load settings, skipping backup retention", slog.String("error", err.Error()))
+		return
+	}
+
+	if _, err := a.backups.Prune(ctx, settings.Backups, time.Now(), false); err != nil {
+		slog.WarnContext(ctx, "failed to prune backups", slog.String("error", err.Error()))
+	}
+}
+
+func backupID(backup *installer.Backup) string {
+	if backup == nil {
+		return ""
+	}
+	return backup.ID
+}
@@ -0,0 +1,153 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+const (
+	defaultBatchConcurrency = 4
+	defaultBatchTimeout     = 30 * time.Second
+)
+
+// ExecuteBatch runs one operation per config using a bounded pool of workers.
+// Each config is executed under its own timeout and the results are returned
+// in the same order as the configs, regardless of completion order.
+func (a *Application) ExecuteBatch(ctx context.Context, configs []*installer.Config, opts installer.BatchOptions) []*installer.BatchResult {
+	return a.executeBatch(ctx, configs, opts, nil)
+}
+
+// executeBatch runs the configs of ExecuteBatch, as part of tx when it is not
+// nil.
+func (a *Application) executeBatch(ctx context.Context, configs []*installer.Config, opts installer.BatchOptions, tx *transaction) []*installer.BatchResult {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	if concurrency > len(configs) {
+		concurrency = len(configs)
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultBatchTimeout
+	}
+
+	slog.InfoContext(ctx, "starting batch operation",
+		slog.Int("clients", len(configs)),
+		slog.Int("concurrency", concurrency),
+		slog.Duration("timeout", timeout))
+
+	results := make([]*installer.BatchResult, len(configs))
+
+	assignOperationID(configs)
+
+	if anyMutates(configs) {
+		unlock, err := a.lockInstaller(ctx, opts.LockTimeout)
+		if err != nil {
+			for idx, config := range configs {
+				results[idx] = &installer.BatchResult{Client: config.Client, Err: err}
+			}
+			return results
+		}
+		defer unlock()
+	}
+
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				results[idx] = a.executeWithTimeout(ctx, configs[idx], timeout, tx)
+			}
+		}()
+	}
+
+	for idx := range configs {
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// executeWithTimeout gives up on a client once its timeout expires, even when
+// a call into the client installer is stuck and never checks the context. The
+// abandoned operation keeps its config lock until it returns, and aborts
+// before its next step because its context is done. A transaction rollback
+// takes that lock too, so it waits for the operation to return.
+func (a *Application) executeWithTimeout(ctx context.Context, config *installer.Config, timeout time.Duration, tx *transaction) *installer.BatchResult {
+	opCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		result *installer.InstallResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+
+	start := time.Now()
+	go func() {
+		result, err := a.executeIn(opCtx, config, tx)
+		done <- outcome{result: result, err: err}
+	}()
+
+	var result *installer.InstallResult
+	var err error
+	select {
+	case out := <-done:
+		result, err = out.result, out.err
+	case <-opCtx.Done():
+		err = fmt.Errorf("%s did not finish within %s: %w", config.Client, timeout, opCtx.Err())
+	}
+
+	if err != nil {
+		slog.WarnContext(ctx, "batch operation failed for client",
+			slog.String("client", string(config.Client)),
+			slog.String("error", err.Error()))
+	}
+
+	return &installer.BatchResult{
+		Client:   config.Client,
+		Scope:    configScope(config),
+		Project:  configProject(config),
+		Result:   result,
+		Err:      err,
+		Duration: time.Since(start),
+	}
+}
+
+func anyMutates(configs []*installer.Config) bool {
+	for _, config := range configs {
+		if config.Mutates() {
+			return true
+		}
+	}
+	return false
+}
+
+// assignOperationID gives every mutating config without an ID one shared
+// operation ID, so that a multi-client run can be undone as a whole.
+func assignOperationID(configs []*installer.Config) {
+	var id string
+	now := time.Now()
+	for _, config := range configs {
+		if config.ID != "" || !config.Mutates() {
+			continue
+		}
+		if id == "" {
+			id = installer.NewOperationID(now)
+		}
+		config.ID = id
+		config.CreatedAt = now
+	}
+}
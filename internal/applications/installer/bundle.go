@@ -0,0 +1,109 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/pkg/signing"
+)
+
+// ExportBundle encodes the MCP servers of a client as a bundle, with secrets
+// replaced by placeholders. With no names, every server is exported.
+func (a *Application) ExportBundle(ctx context.Context, client installer.ClientType, names []string, format installer.BundleFormat) ([]byte, *installer.Bundle, error) {
+	servers, err := a.sourceServers(ctx, client, names)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bundle := installer.NewBundle(client, servers, time.Now())
+	data, err := a.bundles.Encode(bundle, format)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, bundle, nil
+}
+
+// ReadBundle checks the detached signature of a bundle against the trust
+// store, then decodes it. A nil signature means the bundle is unsigned. The
+// trusted keys of policy are added to those of the settings, and a signature
+// is required when either requires it. A signature that does not match the
+// bundle is always refused.
+func (a *Application) ReadBundle(ctx context.Context, data, signature []byte, policy installer.SignaturePolicy) (*installer.Bundle, *installer.BundleVerification, error) {
+	settings, err := a.settings.Load(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	policy.TrustedKeys = append(policy.TrustedKeys, settings.Signatures.TrustedKeys...)
+	policy.Require = policy.Require || settings.Signatures.Require
+
+	verification, err := verifyBundle(data, signature, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bundle, err := a.bundles.Decode(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := bundle.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	return bundle, verification, nil
+}
+
+func verifyBundle(data, signature []byte, policy installer.SignaturePolicy) (*installer.BundleVerification, error) {
+	verification := &installer.BundleVerification{Status: installer.SignatureUnsigned}
+
+	if signature != nil {
+		parsed, err := signing.ParseSignature(signature)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrSignatureInvalid, err)
+		}
+		if !parsed.Verify(data) {
+			return nil, fmt.Errorf("%w, it was changed after signing by key %s", errors.ErrSignatureInvalid, signing.KeyID(parsed.PublicKey))
+		}
+
+		verification.KeyID = signing.KeyID(parsed.PublicKey)
+		verification.Status = installer.SignatureUntrusted
+		for _, encoded := range policy.TrustedKeys {
+			trusted, err := signing.ParsePublicKey(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("%w: trusted key: %v", errors.ErrSettingsInvalid, err)
+			}
+			if trusted.Equal(parsed.PublicKey) {
+				verification.Status = installer.SignatureVerified
+				break
+			}
+		}
+	}
+
+	if policy.Require && verification.Status != installer.SignatureVerified {
+		if verification.Status == installer.SignatureUntrusted {
+			return nil, fmt.Errorf("%w: signed by untrusted key %s", errors.ErrSignatureRequired, verification.KeyID)
+		}
+		return nil, errors.ErrSignatureRequired
+	}
+
+	return verification, nil
+}
+
+// ImportBundle installs the servers of a bundle into the client of config,
+// once every placeholder has a value.
+func (a *Application) ImportBundle(ctx context.Context, config *installer.Config, bundle *installer.Bundle, values map[string]string) (*installer.CopyResult, error) {
+	if err := bundle.Validate(); err != nil {
+		return nil, err
+	}
+
+	servers, missing := bundle.Resolve(values)
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", errors.ErrPlaceholderUnresolved, strings.Join(missing, ", "))
+	}
+
+	return a.addServers(ctx, config, bundle.Source, servers)
+}
@@ -0,0 +1,229 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/internal/core/ports"
+	"go.kirha.ai/mcp-installer/pkg/security"
+)
+
+// Copy writes MCP servers configured in one client into another, translating
+// them through the client-neutral server definition. With no names, every
+// server of the source is copied. Servers the target already has are left
+// untouched, and settings the target cannot represent are reported as
+// warnings.
+func (a *Application) Copy(ctx context.Context, config *installer.Config, from installer.ClientType, names []string) (*installer.CopyResult, error) {
+	servers, err := a.sourceServers(ctx, from, names)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.addServers(ctx, config, from, servers)
+}
+
+// addServers writes servers into the client of config, through its adapter.
+// Servers the client already has are reported and left untouched.
+func (a *Application) addServers(ctx context.Context, config *installer.Config, from installer.ClientType, servers []*installer.McpServer) (*installer.CopyResult, error) {
+	result := &installer.CopyResult{From: from, To: config.Client, DryRun: config.DryRun}
+
+	if config.Mutates() {
+		assignOperationID([]*installer.Config{config})
+
+		unlock, err := a.lockInstaller(ctx, config.LockTimeout)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
+	clientInstaller, err := a.configInstaller(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	running, err := clientInstaller.IsClientRunning(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to check if client is running", slog.String("error", err.Error()))
+	}
+	if running && !config.DryRun && !config.Force {
+		return nil, errors.ErrClientRunning
+	}
+
+	configPath, err := clientInstaller.GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	result.ConfigPath = configPath
+
+	unlock, err := a.lockConfig(ctx, config, configPath)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	currentConfig, err := clientInstaller.LoadConfig(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load config", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	existing, err := clientInstaller.ListMcpServers(ctx, currentConfig)
+	if err != nil {
+		return nil, err
+	}
+	present := make(map[string]bool, len(existing))
+	for _, server := range existing {
+		present[server.Name] = true
+	}
+
+	var toCopy []*installer.McpServer
+	for _, server := range servers {
+		copied := installer.CopiedServer{
+			Name:     server.Name,
+			Type:     server.Type,
+			Status:   installer.CopyWouldCopy,
+			Warnings: clientInstaller.UnsupportedFields(server),
+		}
+		if present[server.Name] {
+			copied.Status = installer.CopyExists
+			copied.Warnings = nil
+		} else {
+			toCopy = append(toCopy, server)
+		}
+		result.Servers = append(result.Servers, copied)
+	}
+
+	if err := a.enforcePolicy(ctx, config.Client, toCopy...); err != nil {
+		return nil, err
+	}
+
+	if config.DryRun || len(toCopy) == 0 {
+		return result, nil
+	}
+
+	backupID, err := a.writeServers(ctx, config, clientInstaller, currentConfig, configPath, toCopy)
+	if err != nil {
+		return nil, err
+	}
+
+	result.BackupID = backupID
+	result.OperationID = config.ID
+	for i := range result.Servers {
+		if result.Servers[i].Status == installer.CopyWouldCopy {
+			result.Servers[i].Status = installer.CopyCopied
+		}
+	}
+
+	slog.InfoContext(ctx, "servers added",
+		slog.String("operation", string(config.Operation)),
+		slog.String("from", string(from)),
+		slog.String("to", string(config.Client)),
+		slog.Int("servers", len(toCopy)),
+		slog.String("operation_id", config.ID))
+
+	return result, nil
+}
+
+// sourceServers reads the servers to copy from the source client, in the
+// order they were asked for.
+func (a *Application) sourceServers(ctx context.Context, from installer.ClientType, names []string) ([]*installer.McpServer, error) {
+	_, servers, err := a.listServers(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return servers, nil
+	}
+
+	byName := make(map[string]*installer.McpServer, len(servers))
+	for _, server := range servers {
+		byName[server.Name] = server
+	}
+
+	selected := make([]*installer.McpServer, 0, len(names))
+	for _, name := range names {
+		server, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s has no server named %q", errors.ErrServerNotFound, from, name)
+		}
+		selected = append(selected, server)
+	}
+
+	return selected, nil
+}
+
+func (a *Application) writeServers(ctx context.Context, config *installer.Config, clientInstaller ports.Installer, currentConfig interface{}, configPath string, servers []*installer.McpServer) (string, error) {
+	created := !clientInstaller.FileExists(configPath)
+
+	backup, err := a.backupConfig(ctx, nil, config, configPath)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to create backup", slog.String("error", err.Error()))
+	}
+
+	var writeErr error
+	wroteKirha := false
+	updatedConfig := currentConfig
+	for _, server := range servers {
+		updatedConfig, writeErr = clientInstaller.AddMcpServer(ctx, updatedConfig, server)
+		if writeErr != nil {
+			break
+		}
+		if server.Name == installer.ServerName {
+			wroteKirha = true
+			config.ApiKey = server.ApiKey()
+		}
+	}
+	if writeErr == nil {
+		writeErr = clientInstaller.SaveConfig(ctx, updatedConfig)
+	}
+
+	if writeErr != nil {
+		slog.ErrorContext(ctx, "failed to add MCP servers", slog.String("error", writeErr.Error()))
+		a.restoreConfig(ctx, clientInstaller, backup, created)
+	} else {
+		backup = a.recordAfter(ctx, nil, backup)
+		if wroteKirha {
+			a.recordManaged(ctx, config, clientInstaller, configPath, created)
+		}
+	}
+
+	a.auditAddedServers(ctx, config, configPath, backup, servers, writeErr)
+
+	return backupID(backup), writeErr
+}
+
+func (a *Application) auditAddedServers(ctx context.Context, config *installer.Config, configPath string, backup *installer.Backup, servers []*installer.McpServer, err error) {
+	names := make([]string, 0, len(servers))
+	for _, server := range servers {
+		names = append(names, server.Name)
+	}
+
+	record := &installer.AuditRecord{
+		OperationID:    config.ID,
+		Operation:      config.Operation,
+		Client:         config.Client,
+		ConfigPath:     configPath,
+		Server:         strings.Join(names, ","),
+		KeyFingerprint: security.KeyFingerprint(config.ApiKey),
+		Result:         installer.AuditResultSuccess,
+	}
+	if err != nil {
+		record.Result = installer.AuditResultFailed
+		record.Error = err.Error()
+	}
+
+	if state, stateErr := a.backups.FileState(ctx, configPath); stateErr == nil {
+		record.BeforeHash = state.Hash
+		record.AfterHash = state.Hash
+	}
+	if backup != nil {
+		record.BeforeHash = backup.Hash
+	}
+
+	a.appendAudit(ctx, record)
+}
@@ -0,0 +1,80 @@
+package installer
+
+import (
+	"context"
+	"log/slog"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+func (a *Application) Detect(ctx context.Context) ([]*installer.DetectResult, error) {
+	slog.InfoContext(ctx, "detecting installed clients")
+
+	clients := a.installerFactory.GetSupportedClients()
+	results := make([]*installer.DetectResult, 0, len(clients))
+
+	for _, client := range clients {
+		result, err := a.detectClient(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (a *Application) detectClient(ctx context.Context, client installer.ClientType) (*installer.DetectResult, error) {
+	clientInstaller, err := a.installerFactory.GetInstaller(ctx, client)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get installer for client",
+			slog.String("error", err.Error()),
+			slog.String("client", string(client)))
+		return nil, err
+	}
+
+	result := &installer.DetectResult{Client: client}
+
+	if binaryPath, err := clientInstaller.GetBinaryPath(); err == nil {
+		result.BinaryPath = binaryPath
+	}
+
+	configPath, err := clientInstaller.GetConfigPath()
+	if err != nil {
+		slog.WarnContext(ctx, "failed to get config path",
+			slog.String("error", err.Error()),
+			slog.String("client", string(client)))
+		result.Error = err.Error()
+		return result, nil
+	}
+	result.ConfigPath = configPath
+	result.ConfigExists = clientInstaller.FileExists(configPath)
+
+	running, err := clientInstaller.IsClientRunning(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to check if client is running", slog.String("error", err.Error()))
+	}
+	result.Running = running
+
+	if !result.ConfigExists {
+		return result, nil
+	}
+
+	currentConfig, err := clientInstaller.LoadConfig(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load config",
+			slog.String("error", err.Error()),
+			slog.String("client", string(client)))
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	hasServer, err := clientInstaller.HasMcpServer(ctx, currentConfig)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	result.HasServer = hasServer
+
+	return result, nil
+}
@@ -0,0 +1,53 @@
+package installer
+
+import (
+	"context"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+// Diff compares the MCP servers configured in two clients, once both are
+// translated to the client-neutral server definition.
+func (a *Application) Diff(ctx context.Context, left, right installer.ClientType) (*installer.ClientComparison, error) {
+	leftPath, leftServers, err := a.listServers(ctx, left)
+	if err != nil {
+		return nil, err
+	}
+
+	rightPath, rightServers, err := a.listServers(ctx, right)
+	if err != nil {
+		return nil, err
+	}
+
+	return &installer.ClientComparison{
+		Left:      left,
+		Right:     right,
+		LeftPath:  leftPath,
+		RightPath: rightPath,
+		Servers:   installer.CompareServers(leftServers, rightServers),
+	}, nil
+}
+
+func (a *Application) listServers(ctx context.Context, client installer.ClientType) (string, []*installer.McpServer, error) {
+	clientInstaller, err := a.installerFactory.GetInstaller(ctx, client)
+	if err != nil {
+		return "", nil, err
+	}
+
+	configPath, err := clientInstaller.GetConfigPath()
+	if err != nil {
+		return "", nil, err
+	}
+
+	config, err := clientInstaller.LoadConfig(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	servers, err := clientInstaller.ListMcpServers(ctx, config)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return configPath, servers, nil
+}
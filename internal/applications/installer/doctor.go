@@ -0,0 +1,517 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/internal/core/ports"
+)
+
+// Doctor diagnoses the MCP setup of clients and, with opts.Fix, applies the
+// remediations that need no decision from the user.
+func (a *Application) Doctor(ctx context.Context, opts installer.DoctorOptions) ([]*installer.ClientDiagnosis, error) {
+	settings, err := a.settings.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prunable, err := a.backups.Prune(ctx, settings.Backups, time.Now(), true)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to list prunable backups", slog.String("error", err.Error()))
+	}
+
+	clients := opts.Clients
+	explicit := len(clients) > 0
+	if !explicit {
+		clients = a.installerFactory.GetSupportedClients()
+	}
+
+	var diagnoses []*installer.ClientDiagnosis
+	for _, client := range clients {
+		clientInstaller, err := a.installerFactory.GetInstaller(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		diagnosis := &installer.ClientDiagnosis{Client: client}
+		configPath, err := clientInstaller.GetConfigPath()
+		if err != nil {
+			diagnosis.Findings = append(diagnosis.Findings, &installer.Finding{
+				Check:    installer.CheckConfig,
+				Severity: installer.SeverityError,
+				Message:  fmt.Sprintf("cannot locate the configuration file: %v", err),
+				Hint:     "Check that HOME is set for the current user",
+			})
+			diagnoses = append(diagnoses, diagnosis)
+			continue
+		}
+		diagnosis.ConfigPath = configPath
+
+		if !explicit && !clientInstaller.FileExists(configPath) {
+			if _, err := clientInstaller.GetBinaryPath(); err != nil {
+				continue
+			}
+		}
+
+		a.diagnoseClient(ctx, diagnosis, clientInstaller, settings, prunable, opts)
+		diagnoses = append(diagnoses, diagnosis)
+	}
+
+	if opts.Fix {
+		a.applyFixes(ctx, diagnoses)
+	}
+
+	return diagnoses, nil
+}
+
+func (a *Application) diagnoseClient(ctx context.Context, diagnosis *installer.ClientDiagnosis, clientInstaller ports.Installer, settings *installer.Settings, prunable []*installer.Backup, opts installer.DoctorOptions) {
+	client := diagnosis.Client
+	configPath := diagnosis.ConfigPath
+	add := func(finding *installer.Finding) {
+		diagnosis.Findings = append(diagnosis.Findings, finding)
+	}
+
+	defer func() {
+		add(a.diagnoseProcess(ctx, client, clientInstaller))
+		diagnosis.Findings = append(diagnosis.Findings, diagnoseBackups(configPath, prunable)...)
+	}()
+
+	if !clientInstaller.FileExists(configPath) {
+		add(&installer.Finding{
+			Check:    installer.CheckConfig,
+			Severity: installer.SeverityError,
+			Message:  "configuration file not found",
+			Hint:     fmt.Sprintf("Run 'mcp-installer install --client %s --key <api-key>'", client),
+		})
+		return
+	}
+
+	currentConfig, err := clientInstaller.LoadConfig(ctx)
+	if err != nil {
+		add(&installer.Finding{
+			Check:    installer.CheckConfig,
+			Severity: installer.SeverityError,
+			Message:  fmt.Sprintf("configuration cannot be parsed: %v", err),
+			Hint:     fmt.Sprintf("Run 'mcp-installer repair --client %s' to restore a backup that parses or apply safe fixes", client),
+		})
+		add(diagnosePermissions(configPath, false))
+		return
+	}
+
+	servers, err := clientInstaller.ListMcpServers(ctx, currentConfig)
+	if err != nil {
+		add(&installer.Finding{
+			Check:    installer.CheckConfig,
+			Severity: installer.SeverityError,
+			Message:  fmt.Sprintf("MCP servers cannot be read: %v", err),
+			Hint:     "Check that every server entry has the shape the client expects",
+		})
+		return
+	}
+
+	add(&installer.Finding{
+		Check:    installer.CheckConfig,
+		Severity: installer.SeverityOK,
+		Message:  fmt.Sprintf("configuration parsed, %d MCP servers", len(servers)),
+	})
+
+	var kirha *installer.McpServer
+	for _, server := range servers {
+		if server.Name == installer.ServerName {
+			kirha = server
+		}
+	}
+
+	add(diagnosePermissions(configPath, kirha != nil && kirha.ApiKey() != ""))
+	add(a.diagnoseKirhaEntry(client, kirha, settings))
+	add(diagnoseDuplicates(client, kirha, servers))
+	add(diagnoseTransports(client, clientInstaller, servers))
+
+	if kirha != nil && !opts.Offline && a.prober != nil {
+		if trustedEndpoint(kirha.URL, settings) {
+			add(a.diagnoseEndpoint(ctx, client, kirha))
+		} else {
+			// The probe sends the key, so only to endpoints known to be Kirha's.
+			add(&installer.Finding{
+				Check:    installer.CheckEndpoint,
+				Severity: installer.SeverityWarning,
+				Message:  fmt.Sprintf("endpoint %q was not probed, it is not an https URL of Kirha or of a profile in settings.json", kirha.URL),
+				Hint:     fmt.Sprintf("Run 'mcp-installer update --client %s --key <api-key>' to rewrite the entry", client),
+			})
+		}
+	}
+}
+
+// diagnosePermissions checks that the configuration can be read and written,
+// and that one holding an API key is private to its owner.
+func diagnosePermissions(configPath string, holdsKey bool) *installer.Finding {
+	finding := &installer.Finding{
+		Check:    installer.CheckPermissions,
+		Severity: installer.SeverityOK,
+		Message:  "configuration is readable and writable",
+	}
+
+	info, err := os.Stat(configPath)
+	if err != nil {
+		finding.Severity = installer.SeverityError
+		finding.Message = fmt.Sprintf("cannot inspect the configuration: %v", err)
+		finding.Hint = "Check the permissions of the directory holding it"
+		return finding
+	}
+
+	for _, flag := range []int{os.O_RDONLY, os.O_WRONLY} {
+		file, err := os.OpenFile(configPath, flag, 0)
+		if err != nil {
+			finding.Severity = installer.SeverityError
+			finding.Message = fmt.Sprintf("configuration is not accessible: %v", err)
+			finding.Hint = fmt.Sprintf("Make %s readable and writable by your user, for example with chown", configPath)
+			return finding
+		}
+		file.Close()
+	}
+
+	if holdsKey && runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		finding.Severity = installer.SeverityWarning
+		finding.Message = fmt.Sprintf("configuration holds an API key but has mode %04o, other users can read it", info.Mode().Perm())
+		finding.Hint = fmt.Sprintf("Run 'chmod 600 %s'", configPath)
+		finding.Fix = installer.FixPermissions
+	}
+
+	return finding
+}
+
+// diagnoseKirhaEntry checks the URL, transport and Authorization header of
+// the Kirha server entry.
+func (a *Application) diagnoseKirhaEntry(client installer.ClientType, kirha *installer.McpServer, settings *installer.Settings) *installer.Finding {
+	finding := &installer.Finding{Check: installer.CheckKirhaEntry, Severity: installer.SeverityError}
+	updateHint := fmt.Sprintf("Run 'mcp-installer update --client %s --key <api-key>' to rewrite the entry", client)
+
+	if kirha == nil {
+		finding.Message = "Kirha MCP server is not configured"
+		finding.Hint = fmt.Sprintf("Run 'mcp-installer install --client %s --key <api-key>'", client)
+		return finding
+	}
+
+	if kirha.Disabled {
+		finding.Message = "Kirha MCP server is disabled"
+		finding.Hint = fmt.Sprintf("Enable it in %s, or run 'mcp-installer update --client %s'", client, client)
+		return finding
+	}
+
+	if kirha.Type != installer.TransportHTTP {
+		finding.Message = fmt.Sprintf("Kirha MCP server uses the %s transport, it is served over http", valueOr(kirha.Type, "unknown"))
+		finding.Hint = updateHint
+		return finding
+	}
+
+	u, err := url.Parse(kirha.URL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		finding.Message = fmt.Sprintf("Kirha URL %q is not an https URL", kirha.URL)
+		finding.Hint = updateHint
+		return finding
+	}
+
+	if !trustedEndpoint(kirha.URL, settings) {
+		finding.Severity = installer.SeverityWarning
+		finding.Message = fmt.Sprintf("Kirha URL %s matches no profile in settings.json", kirha.URL)
+		finding.Hint = updateHint
+		return finding
+	}
+
+	authorization, ok := headerValue(kirha.Headers, "Authorization")
+	if !ok {
+		finding.Message = "Kirha MCP server has no Authorization header"
+		finding.Hint = updateHint
+		return finding
+	}
+	key, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok {
+		finding.Message = "Authorization header does not start with \"Bearer \""
+		finding.Hint = updateHint
+		return finding
+	}
+	if err := a.validateApiKey(key); err != nil {
+		finding.Message = fmt.Sprintf("API key in the Authorization header is invalid: %v", err)
+		finding.Hint = updateHint
+		return finding
+	}
+
+	finding.Severity = installer.SeverityOK
+	finding.Message = fmt.Sprintf("Kirha MCP server points at %s with a bearer key", kirha.URL)
+	return finding
+}
+
+// diagnoseDuplicates reports other entries for the Kirha endpoint, and
+// entries whose name only differs from it by case, which clients may load in
+// its place.
+func diagnoseDuplicates(client installer.ClientType, kirha *installer.McpServer, servers []*installer.McpServer) *installer.Finding {
+	kirhaURL := installer.ServerURL
+	if kirha != nil {
+		kirhaURL = kirha.URL
+	}
+
+	var duplicates []string
+	for _, server := range servers {
+		if server.Name == installer.ServerName {
+			continue
+		}
+		if strings.EqualFold(server.Name, installer.ServerName) || sameHost(server.URL, kirhaURL) {
+			duplicates = append(duplicates, server.Name)
+		}
+	}
+
+	if len(duplicates) == 0 {
+		return &installer.Finding{
+			Check:    installer.CheckDuplicates,
+			Severity: installer.SeverityOK,
+			Message:  "no duplicate Kirha entries",
+		}
+	}
+
+	return &installer.Finding{
+		Check:    installer.CheckDuplicates,
+		Severity: installer.SeverityWarning,
+		Message:  fmt.Sprintf("entries %s also point at Kirha and may shadow %q", strings.Join(duplicates, ", "), installer.ServerName),
+		Hint:     fmt.Sprintf("Remove the extra entries from %s so Kirha is loaded once, with one key", client),
+	}
+}
+
+func diagnoseTransports(client installer.ClientType, clientInstaller ports.Installer, servers []*installer.McpServer) *installer.Finding {
+	var unsupported []string
+	for _, server := range servers {
+		if fields := clientInstaller.UnsupportedFields(server); len(fields) > 0 {
+			unsupported = append(unsupported, fmt.Sprintf("%s (%s)", server.Name, strings.Join(fields, ", ")))
+		}
+	}
+
+	if len(unsupported) == 0 {
+		return &installer.Finding{
+			Check:    installer.CheckTransport,
+			Severity: installer.SeverityOK,
+			Message:  fmt.Sprintf("every server uses settings %s supports", client),
+		}
+	}
+
+	return &installer.Finding{
+		Check:    installer.CheckTransport,
+		Severity: installer.SeverityWarning,
+		Message:  fmt.Sprintf("servers use settings %s does not support: %s", client, strings.Join(unsupported, "; ")),
+		Hint:     "Switch them to a supported transport or remove those settings, the client ignores them",
+	}
+}
+
+func (a *Application) diagnoseProcess(ctx context.Context, client installer.ClientType, clientInstaller ports.Installer) *installer.Finding {
+	running, err := clientInstaller.IsClientRunning(ctx)
+	if err != nil {
+		return &installer.Finding{
+			Check:    installer.CheckProcess,
+			Severity: installer.SeverityInfo,
+			Message:  fmt.Sprintf("cannot tell whether %s is running: %v", client, err),
+		}
+	}
+	if running {
+		return &installer.Finding{
+			Check:    installer.CheckProcess,
+			Severity: installer.SeverityInfo,
+			Message:  fmt.Sprintf("%s is running and only reads its MCP servers at startup", client),
+			Hint:     fmt.Sprintf("Restart %s after changing its configuration", client),
+		}
+	}
+	return &installer.Finding{
+		Check:    installer.CheckProcess,
+		Severity: installer.SeverityOK,
+		Message:  fmt.Sprintf("%s is not running", client),
+	}
+}
+
+// diagnoseBackups reports legacy backup files next to the configuration and
+// stored backups of it that the retention policy no longer keeps.
+func diagnoseBackups(configPath string, prunable []*installer.Backup) []*installer.Finding {
+	var findings []*installer.Finding
+
+	legacy, _ := filepath.Glob(configPath + legacyBackupSuffix)
+	if len(legacy) > 0 {
+		findings = append(findings, &installer.Finding{
+			Check:    installer.CheckBackups,
+			Severity: installer.SeverityWarning,
+			Message:  fmt.Sprintf("%d legacy backup files next to the configuration may hold API keys", len(legacy)),
+			Hint:     fmt.Sprintf("Delete %s%s", configPath, legacyBackupSuffix),
+			Fix:      installer.FixLegacyBackups,
+		})
+	}
+
+	stale := 0
+	for _, backup := range prunable {
+		if backup.ConfigPath == configPath {
+			stale++
+		}
+	}
+	if stale > 0 {
+		findings = append(findings, &installer.Finding{
+			Check:    installer.CheckBackups,
+			Severity: installer.SeverityInfo,
+			Message:  fmt.Sprintf("%d backups are outside the retention policy", stale),
+			Hint:     "Run 'mcp-installer backups prune'",
+			Fix:      installer.FixPruneBackups,
+		})
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, &installer.Finding{
+			Check:    installer.CheckBackups,
+			Severity: installer.SeverityOK,
+			Message:  "no backup clutter",
+		})
+	}
+
+	return findings
+}
+
+// diagnoseEndpoint sends an initialize request to the Kirha endpoint with the
+// configured headers. References to environment variables are expanded from
+// the current environment, as the client would.
+func (a *Application) diagnoseEndpoint(ctx context.Context, client installer.ClientType, kirha *installer.McpServer) *installer.Finding {
+	finding := &installer.Finding{Check: installer.CheckEndpoint}
+
+	probed := *kirha
+	probed.Headers = make(map[string]string, len(kirha.Headers))
+	for name, value := range kirha.Headers {
+		probed.Headers[name] = os.ExpandEnv(value)
+	}
+
+	status, err := a.prober.Probe(ctx, &probed)
+	switch {
+	case err != nil:
+		finding.Severity = installer.SeverityError
+		finding.Message = fmt.Sprintf("endpoint is unreachable: %v", err)
+		finding.Hint = "Check the network connection, proxy and firewall settings, or run with --offline"
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		finding.Severity = installer.SeverityError
+		finding.Message = fmt.Sprintf("endpoint rejected the API key (HTTP %d)", status)
+		finding.Hint = fmt.Sprintf("Run 'mcp-installer update --client %s --key <api-key>' with a valid key", client)
+	case status >= 200 && status < 300:
+		finding.Severity = installer.SeverityOK
+		finding.Message = fmt.Sprintf("endpoint answered HTTP %d", status)
+	default:
+		finding.Severity = installer.SeverityWarning
+		finding.Message = fmt.Sprintf("endpoint answered HTTP %d", status)
+		finding.Hint = "Retry later; if it persists, contact Kirha support"
+	}
+
+	return finding
+}
+
+// applyFixes remediates the fixable findings and records the outcome on
+// each of them.
+func (a *Application) applyFixes(ctx context.Context, diagnoses []*installer.ClientDiagnosis) {
+	var prune []*installer.Finding
+
+	unlock, err := a.lockInstaller(ctx, defaultLockTimeout)
+	if err != nil {
+		for _, diagnosis := range diagnoses {
+			for _, finding := range diagnosis.Findings {
+				if finding.Fix != "" {
+					finding.FixErr = err
+				}
+			}
+		}
+		return
+	}
+
+	for _, diagnosis := range diagnoses {
+		for _, finding := range diagnosis.Findings {
+			switch finding.Fix {
+			case installer.FixPermissions:
+				finding.FixErr = os.Chmod(diagnosis.ConfigPath, 0o600)
+			case installer.FixLegacyBackups:
+				finding.FixErr = removeLegacyBackups(ctx, diagnosis.ConfigPath)
+			case installer.FixPruneBackups:
+				prune = append(prune, finding)
+				continue
+			default:
+				continue
+			}
+			finding.Fixed = finding.FixErr == nil
+		}
+	}
+	unlock()
+
+	// Pruning takes the installer lock itself.
+	if len(prune) > 0 {
+		_, err := a.PruneBackups(ctx, nil, false)
+		for _, finding := range prune {
+			finding.FixErr = err
+			finding.Fixed = err == nil
+		}
+	}
+}
+
+func removeLegacyBackups(ctx context.Context, configPath string) error {
+	paths, err := filepath.Glob(configPath + legacyBackupSuffix)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		slog.InfoContext(ctx, "deleted legacy backup", slog.String("path", path))
+	}
+	return nil
+}
+
+// trustedEndpoint reports whether rawURL is an https URL equal to the Kirha
+// URL or to the URL of a profile in the settings.
+func trustedEndpoint(rawURL string, settings *installer.Settings) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return false
+	}
+
+	if rawURL == installer.ServerURL {
+		return true
+	}
+	for _, profile := range settings.Profiles {
+		if rawURL == profile.URL {
+			return true
+		}
+	}
+	return false
+}
+
+func sameHost(rawURL, other string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	o, err := url.Parse(other)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Hostname(), o.Hostname())
+}
+
+func headerValue(headers map[string]string, name string) (string, bool) {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
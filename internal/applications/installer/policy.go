@@ -0,0 +1,110 @@
+package installer
+
+import (
+	"context"
+	"log/slog"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/internal/core/ports"
+)
+
+// enforcePolicy refuses servers that break an organization policy. It runs
+// before a configuration is saved, and in dry runs so that previews show the
+// refusal as well.
+func (a *Application) enforcePolicy(ctx context.Context, client installer.ClientType, servers ...*installer.McpServer) error {
+	if a.policies == nil {
+		return nil
+	}
+
+	policies, err := a.policies.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	violations := installer.CheckPolicies(policies, client, servers)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	for _, violation := range violations {
+		slog.WarnContext(ctx, "server blocked by policy",
+			slog.String("client", string(client)),
+			slog.String("server", violation.Server),
+			slog.String("rule", string(violation.Rule)),
+			slog.String("policy", violation.Policy))
+	}
+
+	return &installer.PolicyError{Violations: violations}
+}
+
+// enforceContentPolicy refuses configuration content, such as a backup about
+// to be restored, that holds servers breaking an organization policy.
+func (a *Application) enforceContentPolicy(ctx context.Context, client installer.ClientType, clientInstaller ports.Installer, content []byte) error {
+	if a.policies == nil || len(content) == 0 {
+		return nil
+	}
+
+	servers, err := clientInstaller.ParseServers(ctx, content)
+	if err != nil {
+		return err
+	}
+
+	return a.enforcePolicy(ctx, client, servers...)
+}
+
+// CheckPolicy audits the servers already configured in clients against the
+// organization policies. With no clients, every supported client with a
+// configuration file is audited.
+func (a *Application) CheckPolicy(ctx context.Context, clients []installer.ClientType) (*installer.PolicyReport, error) {
+	report := &installer.PolicyReport{}
+
+	var policies []*installer.Policy
+	if a.policies != nil {
+		loaded, err := a.policies.Load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		policies = loaded
+	}
+	for _, policy := range policies {
+		report.Policies = append(report.Policies, policy.Path)
+	}
+
+	explicit := len(clients) > 0
+	if !explicit {
+		clients = a.installerFactory.GetSupportedClients()
+	}
+
+	for _, client := range clients {
+		clientInstaller, err := a.installerFactory.GetInstaller(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		clientReport := &installer.ClientPolicyReport{Client: client}
+		configPath, err := clientInstaller.GetConfigPath()
+		if err != nil {
+			clientReport.Err = err
+			report.Clients = append(report.Clients, clientReport)
+			continue
+		}
+		clientReport.ConfigPath = configPath
+
+		if !explicit && !clientInstaller.FileExists(configPath) {
+			continue
+		}
+
+		_, servers, err := a.listServers(ctx, client)
+		if err != nil {
+			clientReport.Err = err
+			report.Clients = append(report.Clients, clientReport)
+			continue
+		}
+
+		clientReport.Servers = len(servers)
+		clientReport.Violations = installer.CheckPolicies(policies, client, servers)
+		report.Clients = append(report.Clients, clientReport)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,238 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/pkg/security"
+)
+
+// Purge removes the Kirha MCP server from every client configuration that has
+// it, in one transaction, then forgets the installer state and scrubs the
+// Kirha API keys kept in backups, or deletes those backups, and deletes the
+// legacy backup files.
+// Configurations that cannot be read are reported and left untouched.
+func (a *Application) Purge(ctx context.Context, config *installer.Config, opts installer.PurgeOptions) (*installer.PurgeResult, error) {
+	result := &installer.PurgeResult{DryRun: config.DryRun}
+
+	if !config.DryRun {
+		assignOperationID([]*installer.Config{config})
+
+		unlock, err := a.lockInstaller(ctx, opts.LockTimeout)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
+	managed, err := a.state.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	servers, err := a.clientServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var configs []*installer.Config
+	var keys []string
+	for _, found := range servers {
+		if found.err != nil {
+			result.Skipped = append(result.Skipped, installer.PurgeSkip{
+				Client:     found.client,
+				Scope:      found.scope,
+				Project:    found.project,
+				ConfigPath: found.configPath,
+				Error:      found.err.Error(),
+			})
+			continue
+		}
+		if found.server == nil {
+			continue
+		}
+
+		clientConfig := *config
+		found.apply(&clientConfig)
+		configs = append(configs, &clientConfig)
+		keys = append(keys, found.server.ApiKey())
+	}
+
+	if len(configs) > 0 {
+		results, err := a.ExecuteTransaction(ctx, configs, opts.BatchOptions)
+		result.Clients = results
+		result.OperationID = config.ID
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if err := a.purgeState(ctx, result, managed); err != nil {
+		return result, err
+	}
+
+	if err := a.purgeBackups(ctx, result, keys, opts.DeleteBackups); err != nil {
+		return result, err
+	}
+
+	if err := a.purgeLegacyBackups(ctx, result); err != nil {
+		return result, err
+	}
+
+	slog.InfoContext(ctx, "purge completed",
+		slog.String("operation_id", result.OperationID),
+		slog.Int("clients", len(result.Clients)),
+		slog.Int("scrubbed_backups", len(result.ScrubbedBackups)),
+		slog.Int("deleted_backups", len(result.DeletedBackups)),
+		slog.Int("legacy_backups", len(result.LegacyBackups)),
+		slog.Bool("dry_run", result.DryRun))
+
+	return result, nil
+}
+
+// purgeState forgets the managed servers recorded before the purge started,
+// except those of configurations that could not be read and still hold their
+// entry. Entries of the clients just purged are already gone.
+func (a *Application) purgeState(ctx context.Context, result *installer.PurgeResult, managed []*installer.ManagedServer) error {
+	skipped := make(map[clientScope]bool, len(result.Skipped))
+	for _, skip := range result.Skipped {
+		skipped[clientScope{client: skip.Client, scope: skip.Scope, project: skip.Project}] = true
+	}
+
+	for _, server := range managed {
+		if skipped[clientScope{client: server.Client, scope: server.Scope, project: server.Project}] {
+			continue
+		}
+
+		result.ForgottenState++
+		if result.DryRun {
+			continue
+		}
+		if err := a.state.Delete(ctx, server.Key()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// purgeBackups redacts the Kirha API keys of every stored backup holding one,
+// or deletes those backups entirely. keys are the keys of the entries just
+// removed; the keys of the Kirha entries found in the backups are added to
+// them. Other bearer tokens, such as those of other servers, are left alone.
+func (a *Application) purgeBackups(ctx context.Context, result *installer.PurgeResult, keys []string, deleteBackups bool) error {
+	backupKeys, err := a.backupKeys(ctx)
+	if err != nil {
+		return err
+	}
+	keys = append(keys, backupKeys...)
+
+	contains := func(text string) bool {
+		for _, key := range keys {
+			if key != "" && strings.Contains(text, key) {
+				return true
+			}
+		}
+		return false
+	}
+	redact := func(text string) string {
+		for _, key := range keys {
+			if key != "" {
+				text = security.RedactKey(text, key)
+			}
+		}
+		return text
+	}
+
+	if !deleteBackups {
+		scrubbed, err := a.scrubBackups(ctx, contains, redact, result.DryRun)
+		result.ScrubbedBackups = scrubbed
+		return err
+	}
+
+	backups, _, err := a.backupsContaining(ctx, contains)
+	if err != nil {
+		return err
+	}
+
+	for _, backup := range backups {
+		if !result.DryRun {
+			if err := a.backups.Delete(ctx, backup); err != nil {
+				return err
+			}
+		}
+		result.DeletedBackups = append(result.DeletedBackups, backup)
+	}
+
+	return nil
+}
+
+// backupKeys lists the API keys of the Kirha entries held by the stored
+// backups, read at the scope each backup was taken at. Backups that cannot be
+// parsed are skipped; they are still scrubbed of the other keys.
+func (a *Application) backupKeys(ctx context.Context) ([]string, error) {
+	backups, err := a.backups.List(ctx, installer.BackupFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, backup := range backups {
+		if !backup.Existed {
+			continue
+		}
+
+		content, err := a.backups.Content(ctx, backup)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup %s: %w", backup.ID, err)
+		}
+
+		clientInstaller, err := a.backupInstaller(ctx, backup)
+		if err != nil {
+			continue
+		}
+		servers, err := clientInstaller.ParseServers(ctx, content)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to parse backup, looking for known keys only",
+				slog.String("backup_id", backup.ID),
+				slog.String("error", err.Error()))
+			continue
+		}
+
+		for _, server := range servers {
+			if server.Name == installer.ServerName {
+				keys = append(keys, server.ApiKey())
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+// purgeLegacyBackups deletes the legacy backup files, whatever they hold.
+func (a *Application) purgeLegacyBackups(ctx context.Context, result *installer.PurgeResult) error {
+	paths, err := a.legacyBackups(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if !result.DryRun {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				if os.IsPermission(err) {
+					return fmt.Errorf("%w: %s", errors.ErrPermissionDenied, path)
+				}
+				return fmt.Errorf("failed to delete legacy backup %s: %w", path, err)
+			}
+			slog.InfoContext(ctx, "deleted legacy backup", slog.String("path", path))
+		}
+		result.LegacyBackups = append(result.LegacyBackups, path)
+	}
+
+	return nil
+}
@@ -0,0 +1,232 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/internal/core/ports"
+	"go.kirha.ai/mcp-installer/pkg/atomicfile"
+)
+
+// PlanRepair inspects the configuration file of client and works out how it
+// can be repaired, without changing anything.
+func (a *Application) PlanRepair(ctx context.Context, client installer.ClientType) (*installer.RepairPlan, error) {
+	clientInstaller, err := a.installerFactory.GetInstaller(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	configPath, err := clientInstaller.GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.ErrConfigNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrConfigReadFailed, err)
+	}
+
+	state, err := a.backups.FileState(ctx, configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &installer.RepairPlan{
+		Client:     client,
+		ConfigPath: configPath,
+		Content:    content,
+		Hash:       state.Hash,
+		ParseErr:   clientInstaller.ParseConfig(content),
+	}
+
+	fixed, fixes, fixedErr := clientInstaller.RepairConfig(content)
+	if len(fixes) > 0 {
+		plan.Fixes = fixes
+		plan.Fixed = fixed
+		plan.FixedErr = fixedErr
+	}
+
+	if plan.ParseErr != nil {
+		plan.Backup, plan.BackupContent, err = a.lastParseableBackup(ctx, clientInstaller, configPath, state.Hash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return plan, nil
+}
+
+// lastParseableBackup returns the most recent backup of configPath that
+// parses and differs from the current content.
+func (a *Application) lastParseableBackup(ctx context.Context, clientInstaller ports.Installer, configPath, currentHash string) (*installer.Backup, []byte, error) {
+	backups, err := a.backups.List(ctx, installer.BackupFilter{ConfigPath: configPath})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, backup := range backups {
+		if !backup.Existed || backup.Hash == currentHash {
+			continue
+		}
+
+		content, err := a.backups.Content(ctx, backup)
+		if err != nil {
+			slog.WarnContext(ctx, "skipping unreadable backup",
+				slog.String("backup_id", backup.ID),
+				slog.String("error", err.Error()))
+			continue
+		}
+		if clientInstaller.ParseConfig(content) == nil {
+			return backup, content, nil
+		}
+	}
+
+	return nil, nil, nil
+}
+
+// Repair applies one of the options of plan. It is refused when the file
+// changed since the plan was made. The current content is backed up first so
+// that the repair can be undone.
+func (a *Application) Repair(ctx context.Context, config *installer.Config, plan *installer.RepairPlan, action installer.RepairAction) (*installer.InstallResult, error) {
+	if !plan.Available(action) {
+		return nil, fmt.Errorf("%w: %s", errors.ErrRepairUnavailable, action)
+	}
+
+	clientInstaller, err := a.installerFactory.GetInstaller(ctx, plan.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	running, err := clientInstaller.IsClientRunning(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to check if client is running", slog.String("error", err.Error()))
+	}
+	if running && !config.DryRun && !config.Force {
+		return nil, errors.ErrClientRunning
+	}
+
+	content := plan.Fixed
+	if action == installer.RepairRestore {
+		content = plan.BackupContent
+	}
+	if err := a.enforceContentPolicy(ctx, plan.Client, clientInstaller, content); err != nil {
+		return nil, err
+	}
+
+	if config.DryRun {
+		return &installer.InstallResult{
+			Success:    true,
+			ConfigPath: plan.ConfigPath,
+			Message:    "Would " + repairDescription(plan, action),
+		}, nil
+	}
+
+	unlockInstaller, err := a.lockInstaller(ctx, config.LockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer unlockInstaller()
+
+	unlockConfig, err := a.lockConfigFile(ctx, plan.ConfigPath, config.LockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer unlockConfig()
+
+	current, err := a.backups.FileState(ctx, plan.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	if !current.Exists || current.Hash != plan.Hash {
+		return nil, errors.ErrConfigModified
+	}
+
+	assignOperationID([]*installer.Config{config})
+	config.Client = plan.Client
+
+	previous, err := a.createBackup(ctx, config, plan.ConfigPath)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to back up current config before repairing", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	err = a.writeRepair(ctx, plan, action)
+	a.auditRepair(ctx, config, plan, current, err)
+	if err != nil {
+		return nil, err
+	}
+
+	previous = a.recordAfter(ctx, nil, previous)
+	a.reconcileManaged(ctx, plan.Client, plan.ConfigPath)
+	a.applyRetention(ctx)
+
+	slog.InfoContext(ctx, "repaired configuration",
+		slog.String("client", string(plan.Client)),
+		slog.String("action", string(action)),
+		slog.String("config_path", plan.ConfigPath))
+
+	message := fmt.Sprintf("Applied %d fix(es) to %s", len(plan.Fixes), plan.ConfigPath)
+	if action == installer.RepairRestore {
+		message = fmt.Sprintf("Restored backup %s to %s", plan.Backup.ID, plan.ConfigPath)
+	}
+	if running {
+		message += ". Please restart the application to apply changes."
+	}
+
+	return &installer.InstallResult{
+		Success:     true,
+		ConfigPath:  plan.ConfigPath,
+		BackupID:    backupID(previous),
+		OperationID: config.ID,
+		Message:     message,
+	}, nil
+}
+
+func (a *Application) writeRepair(ctx context.Context, plan *installer.RepairPlan, action installer.RepairAction) error {
+	if action == installer.RepairRestore {
+		return a.backups.Restore(ctx, plan.Backup)
+	}
+
+	info, err := os.Stat(plan.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrConfigWriteFailed, err)
+	}
+	if err := atomicfile.WriteFile(plan.ConfigPath, plan.Fixed, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrConfigWriteFailed, err)
+	}
+	return nil
+}
+
+func (a *Application) auditRepair(ctx context.Context, config *installer.Config, plan *installer.RepairPlan, before installer.FileState, err error) {
+	record := &installer.AuditRecord{
+		OperationID: config.ID,
+		Operation:   config.Operation,
+		Client:      plan.Client,
+		ConfigPath:  plan.ConfigPath,
+		BeforeHash:  before.Hash,
+		AfterHash:   before.Hash,
+		Result:      installer.AuditResultSuccess,
+	}
+	if err != nil {
+		record.Result = installer.AuditResultFailed
+		record.Error = err.Error()
+	} else if after, stateErr := a.backups.FileState(ctx, plan.ConfigPath); stateErr == nil {
+		record.AfterHash = after.Hash
+	}
+
+	a.appendAudit(ctx, record)
+}
+
+func repairDescription(plan *installer.RepairPlan, action installer.RepairAction) string {
+	if action == installer.RepairRestore {
+		return fmt.Sprintf("restore backup %s to %s", plan.Backup.ID, plan.ConfigPath)
+	}
+	return fmt.Sprintf("apply %d fix(es) to %s", len(plan.Fixes), plan.ConfigPath)
+}
@@ -0,0 +1,108 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+// RotateKey sets config.ApiKey on every client configuration with a Kirha
+// entry, in one transaction. When oldKey is set, only the entries using it are
+// changed. The replaced keys are then scrubbed from the backups.
+func (a *Application) RotateKey(ctx context.Context, config *installer.Config, oldKey string, opts installer.BatchOptions) (*installer.RotateKeyResult, error) {
+	if err := a.validateApiKey(config.ApiKey); err != nil {
+		return nil, err
+	}
+
+	result := &installer.RotateKeyResult{DryRun: config.DryRun}
+
+	if !config.DryRun {
+		assignOperationID([]*installer.Config{config})
+
+		unlock, err := a.lockInstaller(ctx, opts.LockTimeout)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
+	servers, err := a.clientServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var configs []*installer.Config
+	var targets []int
+	var unreadable []string
+	for _, found := range servers {
+		rotation := installer.KeyRotation{Client: found.client, Scope: found.scope, Project: found.project, ConfigPath: found.configPath}
+
+		switch {
+		case found.err != nil:
+			rotation.Status = installer.KeyFailed
+			rotation.Err = found.err
+			unreadable = append(unreadable, string(found.client))
+		case found.server == nil:
+			continue
+		case found.server.ApiKey() == config.ApiKey:
+			rotation.Status = installer.KeyUpToDate
+		case oldKey != "" && found.server.ApiKey() != oldKey:
+			rotation.Status = installer.KeyOtherKey
+		default:
+			clientConfig := *config
+			found.apply(&clientConfig)
+			// Keep the URL and headers of the entry, only its key changes.
+			clientConfig.Server = found.server
+			clientConfig.Operation = installer.OperationUpdate
+			configs = append(configs, &clientConfig)
+			targets = append(targets, len(result.Clients))
+		}
+
+		result.Clients = append(result.Clients, rotation)
+	}
+
+	// A client that cannot be read may still use the old key, so nothing is
+	// rotated rather than revoking a key that is still in use.
+	if len(unreadable) > 0 {
+		for _, idx := range targets {
+			result.Clients[idx].Status = installer.KeyRolledBack
+		}
+		return result, fmt.Errorf("%w: cannot read %s", errors.ErrTransactionRolledBack, strings.Join(unreadable, ", "))
+	}
+
+	if len(configs) == 0 {
+		return result, nil
+	}
+
+	results, txErr := a.ExecuteTransaction(ctx, configs, opts)
+	for idx, batchResult := range results {
+		rotation := &result.Clients[targets[idx]]
+		switch {
+		case batchResult.Err != nil:
+			rotation.Status = installer.KeyFailed
+			rotation.Err = batchResult.Err
+		case batchResult.RolledBack:
+			rotation.Status = installer.KeyRolledBack
+		case config.DryRun:
+			rotation.Status = installer.KeyWouldRotate
+		default:
+			rotation.Status = installer.KeyRotated
+		}
+	}
+	if txErr != nil {
+		return result, txErr
+	}
+
+	result.OperationID = config.ID
+
+	slog.InfoContext(ctx, "API key rotated",
+		slog.String("operation_id", result.OperationID),
+		slog.Int("clients", len(configs)),
+		slog.Bool("dry_run", config.DryRun))
+
+	return result, nil
+}
@@ -0,0 +1,191 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/pkg/atomicfile"
+	"go.kirha.ai/mcp-installer/pkg/security"
+)
+
+// legacyBackupSuffix marks the backups older versions of the installer wrote
+// next to the configuration file.
+const legacyBackupSuffix = ".backup_*"
+
+// revokeKey scrubs an API key that was replaced or removed from the stored
+// backups. Inside a transaction this waits until the transaction commits, as
+// rolling back needs the backups intact.
+func (a *Application) revokeKey(ctx context.Context, tx *transaction, key string) {
+	if key == "" {
+		return
+	}
+
+	if tx != nil {
+		tx.revoke(key)
+		return
+	}
+
+	a.scrubKey(ctx, key)
+}
+
+// scrubKey redacts key from every stored backup and legacy backup file,
+// unless a client configuration still uses it.
+func (a *Application) scrubKey(ctx context.Context, key string) {
+	if client, inUse := a.keyInUse(ctx, key); inUse {
+		slog.InfoContext(ctx, "API key is still configured, keeping it in backups",
+			slog.String("client", string(client)),
+			slog.String("key", security.KeyFingerprint(key)))
+		return
+	}
+
+	redact := func(text string) string { return security.RedactKey(text, key) }
+	contains := func(text string) bool { return strings.Contains(text, key) }
+
+	scrubbed, err := a.scrubBackups(ctx, contains, redact, false)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to scrub API key from backups", slog.String("error", err.Error()))
+	}
+
+	legacy, err := a.scrubLegacyBackups(ctx, contains, redact)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to scrub API key from legacy backups", slog.String("error", err.Error()))
+	}
+
+	if len(scrubbed) > 0 || len(legacy) > 0 {
+		slog.InfoContext(ctx, "scrubbed revoked API key from backups",
+			slog.String("key", security.KeyFingerprint(key)),
+			slog.Int("backups", len(scrubbed)),
+			slog.Int("legacy_backups", len(legacy)))
+	}
+}
+
+func (a *Application) keyInUse(ctx context.Context, key string) (installer.ClientType, bool) {
+	servers, err := a.clientServers(ctx)
+	if err != nil {
+		return "", false
+	}
+
+	for _, found := range servers {
+		if found.server != nil && found.server.ApiKey() == key {
+			return found.client, true
+		}
+	}
+
+	return "", false
+}
+
+// scrubBackups rewrites the stored backups whose content matches contains
+// with redact applied, or only reports them when dryRun is set.
+func (a *Application) scrubBackups(ctx context.Context, contains func(string) bool, redact func(string) string, dryRun bool) ([]*installer.Backup, error) {
+	backups, contents, err := a.backupsContaining(ctx, contains)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return backups, nil
+	}
+
+	scrubbed := make([]*installer.Backup, 0, len(backups))
+	for idx, backup := range backups {
+		redacted := *backup
+		redacted.Scrubbed = true
+
+		rewritten, err := a.backups.Rewrite(ctx, &redacted, []byte(redact(string(contents[idx]))))
+		if err != nil {
+			return scrubbed, err
+		}
+		scrubbed = append(scrubbed, rewritten)
+	}
+
+	return scrubbed, nil
+}
+
+func (a *Application) backupsContaining(ctx context.Context, contains func(string) bool) ([]*installer.Backup, [][]byte, error) {
+	backups, err := a.backups.List(ctx, installer.BackupFilter{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var matched []*installer.Backup
+	var contents [][]byte
+	for _, backup := range backups {
+		if !backup.Existed {
+			continue
+		}
+
+		content, err := a.backups.Content(ctx, backup)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read backup %s: %w", backup.ID, err)
+		}
+		if contains(string(content)) {
+			matched = append(matched, backup)
+			contents = append(contents, content)
+		}
+	}
+
+	return matched, contents, nil
+}
+
+// scrubLegacyBackups rewrites in place the legacy backup files whose content
+// matches contains.
+func (a *Application) scrubLegacyBackups(ctx context.Context, contains func(string) bool, redact func(string) string) ([]string, error) {
+	paths, err := a.legacyBackups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var scrubbed []string
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return scrubbed, err
+		}
+		if !contains(string(data)) {
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return scrubbed, err
+		}
+		if err := atomicfile.WriteFile(path, []byte(redact(string(data))), info.Mode().Perm()); err != nil {
+			return scrubbed, err
+		}
+		scrubbed = append(scrubbed, path)
+	}
+
+	return scrubbed, nil
+}
+
+// legacyBackups lists the <config>.backup_<timestamp> files written by
+// versions of the installer that predate the backup store.
+func (a *Application) legacyBackups(ctx context.Context) ([]string, error) {
+	var paths []string
+	for _, client := range a.installerFactory.GetSupportedClients() {
+		clientInstaller, err := a.installerFactory.GetInstaller(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		configPath, err := clientInstaller.GetConfigPath()
+		if err != nil {
+			continue
+		}
+
+		matches, err := filepath.Glob(configPath + legacyBackupSuffix)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		paths = append(paths, matches...)
+	}
+
+	return paths, nil
+}
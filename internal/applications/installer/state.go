@@ -0,0 +1,396 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/internal/core/ports"
+)
+
+// ManagedStatus compares the installer state with the client configurations
+// on disk, at the user scope and every other scope the state records. Scopes
+// with neither a managed nor a Kirha entry are left out.
+func (a *Application) ManagedStatus(ctx context.Context, clients []installer.ClientType) ([]*installer.ManagedStatus, error) {
+	if len(clients) == 0 {
+		clients = a.installerFactory.GetSupportedClients()
+	}
+
+	scopes, err := a.clientScopes(ctx, clients)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []*installer.ManagedStatus
+	for _, target := range scopes {
+		status, err := a.managedStatus(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		if status != nil {
+			statuses = append(statuses, status)
+		}
+	}
+
+	return statuses, nil
+}
+
+func (a *Application) managedStatus(ctx context.Context, target clientScope) (*installer.ManagedStatus, error) {
+	status := &installer.ManagedStatus{Client: target.client, Scope: target.scope, Project: target.project}
+
+	clientInstaller, err := a.scopedInstaller(ctx, target.client, target.scope, target.project)
+	if err != nil {
+		status.Error = err.Error()
+		return status, nil
+	}
+
+	configPath, err := clientInstaller.GetConfigPath()
+	if err != nil {
+		status.Error = err.Error()
+		return status, nil
+	}
+	status.ConfigPath = configPath
+
+	status.Managed, err = a.state.Get(ctx, target.key(configPath))
+	if err != nil {
+		return nil, err
+	}
+
+	server, err := currentServer(ctx, clientInstaller, configPath)
+	if err != nil {
+		status.Error = err.Error()
+		return status, nil
+	}
+
+	switch {
+	case status.Managed == nil && server == nil:
+		return nil, nil
+	case status.Managed == nil:
+		status.Drift = installer.DriftUnmanaged
+	case server == nil:
+		status.Drift = installer.DriftMissing
+	case server.Hash() != status.Managed.ServerHash:
+		status.Drift = installer.DriftModified
+	default:
+		status.Drift = installer.DriftInSync
+	}
+
+	return status, nil
+}
+
+// clientScope is one place a client keeps MCP servers. project is only set
+// for the scopes tied to a project.
+type clientScope struct {
+	client  installer.ClientType
+	scope   installer.Scope
+	project string
+}
+
+func (s clientScope) key(configPath string) installer.ManagedKey {
+	return installer.ManagedKey{Client: s.client, Scope: s.scope, ConfigPath: configPath, Project: s.project}
+}
+
+// apply points config at the client and scope of s.
+func (s clientScope) apply(config *installer.Config) {
+	config.Client = s.client
+	config.Scope = s.scope
+	config.Project = s.project
+}
+
+// clientScopes lists the user scope of every client, followed by the other
+// scopes the installer state has entries for. Entries elsewhere are only
+// known through the state, as nothing lists every project on the machine.
+func (a *Application) clientScopes(ctx context.Context, clients []installer.ClientType) ([]clientScope, error) {
+	managed, err := a.state.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var scopes []clientScope
+	for _, client := range clients {
+		scopes = append(scopes, clientScope{client: client, scope: installer.ScopeUser})
+
+		seen := make(map[clientScope]bool)
+		for _, entry := range managed {
+			target := clientScope{client: entry.Client, scope: entry.Scope, project: entry.Project}
+			if entry.Client != client || entry.Scope == "" || entry.Scope == installer.ScopeUser || seen[target] {
+				continue
+			}
+			seen[target] = true
+			scopes = append(scopes, target)
+		}
+	}
+
+	return scopes, nil
+}
+
+// clientServer is the Kirha entry found at one scope of a client. server is
+// nil when there is none, and err is set when the file cannot be read.
+type clientServer struct {
+	clientScope
+	configPath string
+	server     *installer.McpServer
+	err        error
+}
+
+// clientServers reads the Kirha entry at every scope of every supported
+// client whose configuration path can be resolved.
+func (a *Application) clientServers(ctx context.Context) ([]clientServer, error) {
+	scopes, err := a.clientScopes(ctx, a.installerFactory.GetSupportedClients())
+	if err != nil {
+		return nil, err
+	}
+
+	var servers []clientServer
+	for _, target := range scopes {
+		clientInstaller, err := a.scopedInstaller(ctx, target.client, target.scope, target.project)
+		if err != nil {
+			servers = append(servers, clientServer{clientScope: target, err: err})
+			continue
+		}
+
+		configPath, err := clientInstaller.GetConfigPath()
+		if err != nil {
+			continue
+		}
+
+		server, err := currentServer(ctx, clientInstaller, configPath)
+		servers = append(servers, clientServer{
+			clientScope: target,
+			configPath:  configPath,
+			server:      server,
+			err:         err,
+		})
+	}
+
+	return servers, nil
+}
+
+// currentServer returns the Kirha entry of a client configuration, or nil
+// when the file or the entry does not exist.
+func currentServer(ctx context.Context, clientInstaller ports.Installer, configPath string) (*installer.McpServer, error) {
+	if !clientInstaller.FileExists(configPath) {
+		return nil, nil
+	}
+
+	currentConfig, err := clientInstaller.LoadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := clientInstaller.HasMcpServer(ctx, currentConfig)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	return clientInstaller.GetMcpServerConfig(ctx, currentConfig)
+}
+
+func (a *Application) managedServer(ctx context.Context, config *installer.Config, configPath string) *installer.ManagedServer {
+	managed, err := a.state.Get(ctx, managedKey(config, configPath))
+	if err != nil {
+		slog.WarnContext(ctx, "failed to read installer state", slog.String("error", err.Error()))
+		return nil
+	}
+	return managed
+}
+
+// recordManaged stores the entry the installer just wrote. created tells
+// whether the configuration file did not exist before; an update keeps the
+// value recorded at install time.
+func (a *Application) recordManaged(ctx context.Context, config *installer.Config, clientInstaller ports.Installer, configPath string, created bool) {
+	server, err := currentServer(ctx, clientInstaller, configPath)
+	if err != nil || server == nil {
+		slog.WarnContext(ctx, "failed to read installed server, it will not be tracked",
+			slog.String("client", string(config.Client)))
+		return
+	}
+
+	fileState, err := a.backups.FileState(ctx, configPath)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to hash config, it will not be tracked", slog.String("error", err.Error()))
+		return
+	}
+
+	now := time.Now().UTC()
+	managed := &installer.ManagedServer{
+		Client:      config.Client,
+		Scope:       configScope(config),
+		ConfigPath:  configPath,
+		Project:     configProject(config),
+		Server:      server.Name,
+		Profile:     config.Profile,
+		CreatedFile: created,
+		ServerHash:  server.Hash(),
+		ContentHash: fileState.Hash,
+		OperationID: config.ID,
+		InstalledAt: now,
+		UpdatedAt:   now,
+	}
+	if managed.Profile == "" {
+		managed.Profile = installer.DefaultProfile
+	}
+
+	if previous := a.managedServer(ctx, config, configPath); previous != nil {
+		managed.CreatedFile = previous.CreatedFile
+		managed.InstalledAt = previous.InstalledAt
+		// An update that names no profile, such as a key rotation, keeps the
+		// entry's URL and headers and so its profile.
+		if config.Profile == "" {
+			managed.Profile = previous.Profile
+		}
+	}
+
+	if err := a.state.Put(ctx, managed); err != nil {
+		slog.WarnContext(ctx, "failed to record managed server", slog.String("error", err.Error()))
+	}
+}
+
+// applyManagedProfile makes an update that does not set the server keep the
+// profile recorded for the entry, so the file and the state agree on its URL
+// and headers. A profile that no longer exists falls back to the default.
+func (a *Application) applyManagedProfile(ctx context.Context, config *installer.Config, configPath string) {
+	if config.Server != nil || config.Profile != "" {
+		return
+	}
+
+	managed := a.managedServer(ctx, config, configPath)
+	if managed == nil || managed.Profile == "" || managed.Profile == installer.DefaultProfile {
+		return
+	}
+
+	config.Profile = installer.DefaultProfile
+
+	settings, err := a.settings.Load(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load settings, using the default profile", slog.String("error", err.Error()))
+		return
+	}
+
+	serverProfile, ok := settings.Profiles[managed.Profile]
+	if !ok {
+		slog.WarnContext(ctx, "recorded profile no longer exists, using the default profile",
+			slog.String("profile", managed.Profile))
+		return
+	}
+
+	config.Profile = managed.Profile
+	config.Server = serverProfile.McpServer()
+}
+
+func (a *Application) forgetManaged(ctx context.Context, config *installer.Config, configPath string) {
+	if err := a.state.Delete(ctx, managedKey(config, configPath)); err != nil {
+		slog.WarnContext(ctx, "failed to update installer state", slog.String("error", err.Error()))
+	}
+}
+
+// restoreManaged puts back a state entry captured before a change that was
+// rolled back.
+func (a *Application) restoreManaged(ctx context.Context, key installer.ManagedKey, managed *installer.ManagedServer) {
+	var err error
+	if managed != nil {
+		err = a.state.Put(ctx, managed)
+	} else {
+		err = a.state.Delete(ctx, key)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "failed to restore installer state", slog.String("error", err.Error()))
+	}
+}
+
+// reconcileManaged drops the state entries of a configuration whose Kirha
+// entry was taken out by a restore or undo. A file can hold entries of several
+// scopes, each checked where its scope keeps it.
+func (a *Application) reconcileManaged(ctx context.Context, client installer.ClientType, configPath string) {
+	managed, err := a.state.List(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to read installer state", slog.String("error", err.Error()))
+		return
+	}
+
+	for _, entry := range managed {
+		if entry.Client != client || entry.ConfigPath != configPath {
+			continue
+		}
+
+		clientInstaller, err := a.scopedInstaller(ctx, client, entry.Scope, entry.Project)
+		if err != nil {
+			continue
+		}
+
+		server, err := currentServer(ctx, clientInstaller, configPath)
+		if err != nil || server != nil {
+			continue
+		}
+
+		if err := a.state.Delete(ctx, entry.Key()); err != nil {
+			slog.WarnContext(ctx, "failed to update installer state", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// createdFileUnchanged reports whether the installer created the
+// configuration file and nothing else touched it since.
+func (a *Application) createdFileUnchanged(ctx context.Context, managed *installer.ManagedServer) bool {
+	if managed == nil || !managed.CreatedFile {
+		return false
+	}
+
+	current, err := a.backups.FileState(ctx, managed.ConfigPath)
+	if err != nil {
+		return false
+	}
+
+	return current.Exists && current.Hash == managed.ContentHash
+}
+
+func configScope(config *installer.Config) installer.Scope {
+	if config.Scope == "" {
+		return installer.ScopeUser
+	}
+	return config.Scope
+}
+
+// configProject is the project of the entries config targets, which only
+// scopes other than user have.
+func configProject(config *installer.Config) string {
+	if configScope(config) == installer.ScopeUser {
+		return ""
+	}
+	return config.Project
+}
+
+func managedKey(config *installer.Config, configPath string) installer.ManagedKey {
+	return installer.ManagedKey{
+		Client:     config.Client,
+		Scope:      configScope(config),
+		ConfigPath: configPath,
+		Project:    configProject(config),
+	}
+}
+
+// configInstaller returns the installer for the client and scope of config.
+func (a *Application) configInstaller(ctx context.Context, config *installer.Config) (ports.Installer, error) {
+	return a.scopedInstaller(ctx, config.Client, configScope(config), configProject(config))
+}
+
+// scopedInstaller returns the installer of client bound to scope. Only
+// clients that keep servers at several scopes accept scopes besides user.
+func (a *Application) scopedInstaller(ctx context.Context, client installer.ClientType, scope installer.Scope, project string) (ports.Installer, error) {
+	clientInstaller, err := a.installerFactory.GetInstaller(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if scope == installer.ScopeUser {
+		return clientInstaller, nil
+	}
+
+	scoped, ok := clientInstaller.(ports.ScopedInstaller)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s only keeps servers at the user scope", errors.ErrScopeNotSupported, client)
+	}
+	return scoped.WithScope(scope, project)
+}
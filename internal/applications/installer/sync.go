@@ -0,0 +1,179 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+// Sync reconciles the Kirha entry of every detected client with a desired
+// definition: the entry of the source client, or a profile from the settings.
+// Missing entries are installed and drifted ones updated, in one transaction.
+// The API key comes from config.ApiKey, then from the source client; with a
+// profile and no key, each client keeps its own.
+func (a *Application) Sync(ctx context.Context, config *installer.Config, source installer.ClientType, profile string, opts installer.BatchOptions) (*installer.SyncResult, error) {
+	desired, desiredProfile, err := a.desiredServer(ctx, source, profile)
+	if err != nil {
+		return nil, err
+	}
+	if config.ApiKey == "" {
+		config.ApiKey = desired.ApiKey()
+	}
+	if config.ApiKey != "" {
+		if err := a.validateApiKey(config.ApiKey); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &installer.SyncResult{Source: source, Profile: profile, DryRun: config.DryRun}
+
+	if !config.DryRun {
+		assignOperationID([]*installer.Config{config})
+
+		unlock, err := a.lockInstaller(ctx, opts.LockTimeout)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
+	detections, err := a.Detect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	detected := make(map[installer.ClientType]bool, len(detections))
+	for _, detection := range detections {
+		detected[detection.Client] = detection.Detected()
+	}
+
+	servers, err := a.clientServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var configs []*installer.Config
+	var targets []*installer.SyncChange
+	var failed []string
+	for _, found := range servers {
+		if (found.client == source && found.scope == installer.ScopeUser) || !detected[found.client] {
+			continue
+		}
+
+		change := &installer.SyncChange{Client: found.client, Scope: found.scope, Project: found.project, ConfigPath: found.configPath}
+		result.Clients = append(result.Clients, change)
+
+		if found.err != nil {
+			change.Status = installer.SyncFailed
+			change.Err = found.err
+			failed = append(failed, string(found.client))
+			continue
+		}
+
+		clientConfig := *config
+		found.apply(&clientConfig)
+		clientConfig.Profile = desiredProfile
+		clientConfig.Server = desired
+
+		if found.server == nil {
+			change.Status = installer.SyncMissing
+			clientConfig.Operation = installer.OperationInstall
+			if clientConfig.ApiKey == "" {
+				change.Status = installer.SyncFailed
+				change.Err = errors.ErrApiKeyRequired
+				failed = append(failed, string(found.client))
+				continue
+			}
+		} else {
+			if clientConfig.ApiKey == "" {
+				clientConfig.ApiKey = found.server.ApiKey()
+			}
+
+			change.Differences = clientConfig.McpServer().Differences(found.server)
+			if len(change.Differences) == 0 {
+				change.Status = installer.SyncInSync
+				continue
+			}
+			change.Status = installer.SyncDrifted
+			clientConfig.Operation = installer.OperationUpdate
+		}
+
+		configs = append(configs, &clientConfig)
+		targets = append(targets, change)
+	}
+
+	// Every client ends up in sync or none is changed.
+	if len(failed) > 0 {
+		return result, fmt.Errorf("%w: cannot sync %s", errors.ErrTransactionRolledBack, strings.Join(failed, ", "))
+	}
+
+	if len(configs) == 0 || config.DryRun {
+		return result, nil
+	}
+
+	results, txErr := a.ExecuteTransaction(ctx, configs, opts)
+	for idx, batchResult := range results {
+		targets[idx].Err = batchResult.Err
+		targets[idx].RolledBack = batchResult.RolledBack
+		targets[idx].Applied = batchResult.Err == nil && !batchResult.RolledBack
+	}
+	if txErr != nil {
+		return result, txErr
+	}
+
+	result.OperationID = config.ID
+
+	slog.InfoContext(ctx, "clients synced",
+		slog.String("source", string(source)),
+		slog.String("profile", profile),
+		slog.String("operation_id", result.OperationID),
+		slog.Int("changed", len(configs)))
+
+	return result, nil
+}
+
+// desiredServer returns the definition to sync to and the profile to record
+// for it: the one recorded for the source client's entry, or profile. The key
+// of a source client is kept in the returned headers; a profile has none.
+func (a *Application) desiredServer(ctx context.Context, source installer.ClientType, profile string) (*installer.McpServer, string, error) {
+	if source != "" {
+		clientInstaller, err := a.installerFactory.GetInstaller(ctx, source)
+		if err != nil {
+			return nil, "", err
+		}
+
+		configPath, err := clientInstaller.GetConfigPath()
+		if err != nil {
+			return nil, "", err
+		}
+
+		server, err := currentServer(ctx, clientInstaller, configPath)
+		if err != nil {
+			return nil, "", err
+		}
+		if server == nil {
+			return nil, "", fmt.Errorf("%w: %s", errors.ErrSyncSourceNotConfigured, source)
+		}
+
+		recorded := installer.DefaultProfile
+		if managed := a.managedServer(ctx, &installer.Config{Client: source}, configPath); managed != nil && managed.Profile != "" {
+			recorded = managed.Profile
+		}
+		return server, recorded, nil
+	}
+
+	settings, err := a.settings.Load(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	serverProfile, ok := settings.Profiles[profile]
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %s", errors.ErrProfileNotFound, profile)
+	}
+
+	return serverProfile.McpServer(), profile, nil
+}
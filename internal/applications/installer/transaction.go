@@ -0,0 +1,241 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+	"go.kirha.ai/mcp-installer/internal/core/ports"
+	"go.kirha.ai/mcp-installer/pkg/security"
+)
+
+// transaction records the pre-change state of every configuration targeted by
+// a multi-client operation so the whole set can be rolled back together. A
+// client can be targeted at several scopes, so entries are keyed like the
+// installer state.
+type transaction struct {
+	entries map[installer.ManagedKey]*transactionEntry
+	order   []installer.ManagedKey
+
+	// revokedKeys are scrubbed from the backups once the transaction commits.
+	// removedKeys are the keys removals took out of each target, recorded by
+	// the audit of a rollback.
+	mu          sync.Mutex
+	revokedKeys []string
+	removedKeys map[installer.ManagedKey]string
+}
+
+type transactionEntry struct {
+	config          *installer.Config
+	clientInstaller ports.Installer
+	configPath      string
+	key             installer.ManagedKey
+	backup          *installer.Backup
+	managed         *installer.ManagedServer
+	existed         bool
+}
+
+func (t *transaction) entry(key installer.ManagedKey) *transactionEntry {
+	return t.entries[key]
+}
+
+func (t *transaction) recordRemovedKey(target installer.ManagedKey, key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.removedKeys == nil {
+		t.removedKeys = make(map[installer.ManagedKey]string)
+	}
+	t.removedKeys[target] = key
+}
+
+// auditKey is the key recorded for the rollback of target: the one a removal
+// took out of it, or the one the operation wrote.
+func (t *transaction) auditKey(target installer.ManagedKey) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if key, ok := t.removedKeys[target]; ok {
+		return key
+	}
+	return t.entries[target].config.ApiKey
+}
+
+func (t *transaction) revoke(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, revoked := range t.revokedKeys {
+		if revoked == key {
+			return
+		}
+	}
+	t.revokedKeys = append(t.revokedKeys, key)
+}
+
+// ExecuteTransaction applies one operation per config with all-or-nothing
+// semantics. Every target is backed up before anything is changed; if any
+// client fails, or the context is cancelled while the batch is running, every
+// target is restored and files created by the run are deleted.
+func (a *Application) ExecuteTransaction(ctx context.Context, configs []*installer.Config, opts installer.BatchOptions) ([]*installer.BatchResult, error) {
+	if !anyMutates(configs) {
+		return a.ExecuteBatch(ctx, configs, opts), nil
+	}
+
+	assignOperationID(configs)
+
+	unlock, err := a.lockInstaller(ctx, opts.LockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	tx, err := a.beginTransaction(ctx, configs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := a.executeBatch(ctx, configs, opts, tx)
+
+	failed := ctx.Err() != nil
+	for _, result := range results {
+		if result.Err != nil {
+			failed = true
+			break
+		}
+	}
+
+	if !failed {
+		for _, key := range tx.order {
+			tx.entries[key].backup = a.recordAfter(ctx, nil, tx.entries[key].backup)
+		}
+		for _, key := range tx.revokedKeys {
+			a.scrubKey(ctx, key)
+		}
+
+		slog.InfoContext(ctx, "transaction committed", slog.Int("clients", len(configs)))
+		return results, nil
+	}
+
+	a.rollbackTransaction(context.WithoutCancel(ctx), tx)
+	a.auditRollback(context.WithoutCancel(ctx), tx)
+
+	for _, result := range results {
+		if result.Err == nil {
+			result.RolledBack = true
+		}
+	}
+
+	if ctx.Err() != nil {
+		return results, fmt.Errorf("%w: %v", errors.ErrTransactionRolledBack, ctx.Err())
+	}
+
+	return results, errors.ErrTransactionRolledBack
+}
+
+func (a *Application) beginTransaction(ctx context.Context, configs []*installer.Config) (*transaction, error) {
+	tx := &transaction{
+		entries: make(map[installer.ManagedKey]*transactionEntry),
+	}
+
+	for _, config := range configs {
+		clientInstaller, err := a.configInstaller(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+
+		configPath, err := clientInstaller.GetConfigPath()
+		if err != nil {
+			return nil, err
+		}
+
+		key := managedKey(config, configPath)
+		if _, exists := tx.entries[key]; exists {
+			continue
+		}
+
+		entry := &transactionEntry{
+			config:          config,
+			clientInstaller: clientInstaller,
+			configPath:      configPath,
+			key:             key,
+			managed:         a.managedServer(ctx, config, configPath),
+			existed:         clientInstaller.FileExists(configPath),
+		}
+
+		entry.backup, err = a.createBackup(ctx, config, configPath)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to back up transaction target",
+				slog.String("client", string(config.Client)),
+				slog.String("error", err.Error()))
+			return nil, fmt.Errorf("%w: %s: %v", errors.ErrTransactionBackup, config.Client, err)
+		}
+
+		tx.entries[key] = entry
+		tx.order = append(tx.order, key)
+	}
+
+	a.applyRetention(ctx)
+
+	slog.InfoContext(ctx, "transaction started", slog.Int("targets", len(tx.order)))
+
+	return tx, nil
+}
+
+// rollbackTransaction restores every target under its config lock. A client
+// abandoned after its timeout may still be running and holds that lock until
+// it returns, so its target is only restored once it can no longer write.
+func (a *Application) rollbackTransaction(ctx context.Context, tx *transaction) {
+	slog.WarnContext(ctx, "rolling back transaction", slog.Int("targets", len(tx.order)))
+
+	for _, key := range tx.order {
+		entry := tx.entries[key]
+
+		unlock, err := a.lockConfigFile(ctx, entry.configPath, entry.config.LockTimeout)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to roll back client configuration",
+				slog.String("client", string(key.Client)),
+				slog.String("scope", string(key.Scope)),
+				slog.String("path", entry.configPath),
+				slog.String("error", err.Error()))
+			continue
+		}
+
+		a.restoreConfig(ctx, entry.clientInstaller, entry.backup, !entry.existed)
+		a.restoreManaged(ctx, entry.key, entry.managed)
+		unlock()
+
+		slog.InfoContext(ctx, "rolled back client configuration",
+			slog.String("client", string(key.Client)),
+			slog.String("scope", string(key.Scope)),
+			slog.String("path", entry.configPath))
+	}
+}
+
+func (a *Application) auditRollback(ctx context.Context, tx *transaction) {
+	for _, key := range tx.order {
+		entry := tx.entries[key]
+		config := entry.config
+
+		record := &installer.AuditRecord{
+			OperationID:    config.ID,
+			Operation:      config.Operation,
+			Client:         config.Client,
+			ConfigPath:     entry.configPath,
+			Server:         installer.ServerName,
+			KeyFingerprint: security.KeyFingerprint(tx.auditKey(key)),
+			Result:         installer.AuditResultRolledBack,
+		}
+		if entry.backup != nil {
+			record.BeforeHash = entry.backup.Hash
+		}
+		if state, err := a.backups.FileState(ctx, entry.configPath); err == nil {
+			record.AfterHash = state.Hash
+		}
+
+		a.appendAudit(ctx, record)
+	}
+}
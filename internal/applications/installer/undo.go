@@ -0,0 +1,229 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+// Undo reverts every configuration file changed by a completed operation,
+// or by the most recent one when operationID is empty. A file that changed
+// again since the operation is reported as a conflict and nothing is undone,
+// unless config.Force is set. Undo is itself an operation and can be undone.
+func (a *Application) Undo(ctx context.Context, operationID string, config *installer.Config) (*installer.UndoResult, error) {
+	if operationID == "" {
+		latest, err := a.latestOperationID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		operationID = latest
+	}
+
+	backups, err := a.operationBackups(ctx, operationID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &installer.UndoResult{
+		OperationID: operationID,
+		Operation:   backups[0].Operation,
+		DryRun:      config.DryRun,
+	}
+
+	if !config.DryRun && !config.Force {
+		for _, backup := range backups {
+			if a.isClientRunning(ctx, backup.Client) {
+				return nil, &installer.ClientRunningError{Client: backup.Client}
+			}
+		}
+	}
+
+	for _, backup := range backups {
+		if err := a.enforceBackupPolicy(ctx, backup); err != nil {
+			return nil, err
+		}
+	}
+
+	if !config.DryRun {
+		unlock, err := a.lockInstaller(ctx, config.LockTimeout)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+
+		for _, backup := range backups {
+			unlockConfig, err := a.lockConfigFile(ctx, backup.ConfigPath, config.LockTimeout)
+			if err != nil {
+				return nil, err
+			}
+			defer unlockConfig()
+		}
+	}
+
+	conflicts := 0
+	states := make(map[string]installer.FileState, len(backups))
+	for _, backup := range backups {
+		current, err := a.backups.FileState(ctx, backup.ConfigPath)
+		if err != nil {
+			return nil, err
+		}
+		states[backup.ConfigPath] = current
+
+		change := installer.UndoChange{
+			Client:     backup.Client,
+			ConfigPath: backup.ConfigPath,
+			Deleted:    !backup.Existed,
+			Conflict:   current != *backup.After,
+			Scrubbed:   backup.Scrubbed,
+		}
+		if change.Conflict {
+			conflicts++
+		}
+		result.Changes = append(result.Changes, change)
+	}
+
+	if conflicts > 0 && !config.Force {
+		return result, errors.ErrUndoConflict
+	}
+
+	if config.DryRun {
+		return result, nil
+	}
+
+	assignOperationID([]*installer.Config{config})
+	result.UndoID = config.ID
+
+	var undone []*installer.Backup
+	for _, backup := range backups {
+		undoConfig := *config
+		undoConfig.Client = backup.Client
+		undoConfig.Scope = backup.Scope
+		undoConfig.Project = backup.Project
+
+		undoBackup, err := a.createBackup(ctx, &undoConfig, backup.ConfigPath)
+		if err == nil {
+			err = a.backups.Restore(ctx, backup)
+		}
+		a.auditBackupChange(ctx, &undoConfig, backup, states[backup.ConfigPath], err)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to undo change, reverting files already undone",
+				slog.String("operation_id", operationID),
+				slog.String("path", backup.ConfigPath),
+				slog.String("error", err.Error()))
+
+			for _, previous := range undone {
+				if restoreErr := a.backups.Restore(ctx, previous); restoreErr != nil {
+					slog.ErrorContext(ctx, "failed to revert undone file", slog.String("error", restoreErr.Error()))
+				}
+			}
+			return nil, err
+		}
+
+		undone = append(undone, undoBackup)
+	}
+
+	for _, undoBackup := range undone {
+		a.recordAfter(ctx, nil, undoBackup)
+		a.reconcileManaged(ctx, undoBackup.Client, undoBackup.ConfigPath)
+	}
+	a.applyRetention(ctx)
+
+	slog.InfoContext(ctx, "operation undone",
+		slog.String("operation_id", operationID),
+		slog.String("undo_id", result.UndoID),
+		slog.Int("files", len(undone)))
+
+	return result, nil
+}
+
+func (a *Application) latestOperationID(ctx context.Context) (string, error) {
+	backups, err := a.backups.List(ctx, installer.BackupFilter{})
+	if err != nil {
+		return "", err
+	}
+
+	for _, backup := range backups {
+		if backup.OperationID != "" && backup.Completed() {
+			return backup.OperationID, nil
+		}
+	}
+
+	return "", errors.ErrNothingToUndo
+}
+
+// operationBackups returns the completed backups of an operation, one per
+// configuration file, in a stable order so that config locks are always taken
+// in the same sequence.
+func (a *Application) operationBackups(ctx context.Context, operationID string) ([]*installer.Backup, error) {
+	backups, err := a.backups.List(ctx, installer.BackupFilter{OperationID: operationID})
+	if err != nil {
+		return nil, err
+	}
+
+	// List returns the newest first. The oldest backup of a file holds its
+	// state from before the operation and the newest one the state it was left in.
+	byPath := make(map[string]*installer.Backup)
+	for _, backup := range backups {
+		if !backup.Completed() {
+			continue
+		}
+		if newest, ok := byPath[backup.ConfigPath]; ok {
+			oldest := *backup
+			oldest.After = newest.After
+			backup = &oldest
+		}
+		byPath[backup.ConfigPath] = backup
+	}
+
+	if len(byPath) == 0 {
+		return nil, fmt.Errorf("%w: %s", errors.ErrOperationNotFound, operationID)
+	}
+
+	result := make([]*installer.Backup, 0, len(byPath))
+	for _, backup := range byPath {
+		result = append(result, backup)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ConfigPath < result[j].ConfigPath
+	})
+
+	return result, nil
+}
+
+// enforceBackupPolicy refuses a backup whose content breaks an organization
+// policy, before it is restored.
+func (a *Application) enforceBackupPolicy(ctx context.Context, backup *installer.Backup) error {
+	if a.policies == nil || !backup.Existed {
+		return nil
+	}
+
+	content, err := a.backups.Content(ctx, backup)
+	if err != nil {
+		return err
+	}
+
+	clientInstaller, err := a.backupInstaller(ctx, backup)
+	if err != nil {
+		return err
+	}
+
+	return a.enforceContentPolicy(ctx, backup.Client, clientInstaller, content)
+}
+
+func (a *Application) isClientRunning(ctx context.Context, client installer.ClientType) bool {
+	clientInstaller, err := a.installerFactory.GetInstaller(ctx, client)
+	if err != nil {
+		return false
+	}
+
+	running, err := clientInstaller.IsClientRunning(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to check if client is running", slog.String("error", err.Error()))
+	}
+
+	return running
+}
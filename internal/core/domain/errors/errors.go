@@ -9,9 +9,13 @@ var (
 	ErrConfigWriteFailed   = errors.New("failed to write configuration")
 	ErrConfigBackupFailed  = errors.New("failed to backup configuration")
 	ErrConfigRestoreFailed = errors.New("failed to restore configuration")
+	ErrConfigModified      = errors.New("configuration file was modified by another process")
+	ErrUnexpectedChange    = errors.New("write changed settings it was not meant to touch")
 
 	ErrClientNotSupported = errors.New("client not supported")
 	ErrClientRunning      = errors.New("client is currently running, please close it before installing")
+	ErrBinaryNotFound     = errors.New("client binary not found in PATH")
+	ErrScopeNotSupported  = errors.New("scope not supported by this client")
 
 	ErrApiKeyRequired = errors.New("API key is required")
 	ErrApiKeyInvalid  = errors.New("invalid API key format")
@@ -28,10 +32,36 @@ var (
 
 	ErrPathNotFound     = errors.New("path not found")
 	ErrPermissionDenied = errors.New("permission denied")
-	ErrBackupExists     = errors.New("backup file already exists")
+
+	ErrBackupNotFound  = errors.New("backup not found")
+	ErrBackupCorrupted = errors.New("backup content does not match its checksum")
+	ErrSettingsInvalid = errors.New("invalid installer settings")
+
+	ErrOperationNotFound = errors.New("operation not found")
+	ErrNothingToUndo     = errors.New("no completed operation to undo")
+	ErrUndoConflict      = errors.New("configuration changed since the operation")
+
+	ErrRepairUnavailable = errors.New("repair option is not available for this configuration")
+
+	ErrSyncSourceNotConfigured = errors.New("sync source has no Kirha MCP server configured")
+	ErrProfileNotFound         = errors.New("profile not found")
+
+	ErrBundleInvalid         = errors.New("invalid bundle")
+	ErrBundleVersion         = errors.New("unsupported bundle version")
+	ErrPlaceholderUnresolved = errors.New("bundle placeholders have no value")
+	ErrSignatureRequired     = errors.New("bundle is not signed by a trusted key")
+	ErrSignatureInvalid      = errors.New("bundle signature does not match its content")
+
+	ErrPolicyInvalid   = errors.New("invalid policy file")
+	ErrPolicyViolation = errors.New("blocked by organization policy")
 
 	ErrPlatformNotSupported = errors.New("platform not supported")
 
+	ErrLocked = errors.New("another installer process holds the lock")
+
+	ErrTransactionRolledBack = errors.New("transaction rolled back")
+	ErrTransactionBackup     = errors.New("failed to back up transaction targets")
+
 	ErrUnknownOperation  = errors.New("unknown operation")
 	ErrUnsupportedClient = errors.New("unsupported client")
 )
@@ -0,0 +1,60 @@
+package installer
+
+import "time"
+
+type AuditResult string
+
+const (
+	AuditResultSuccess    AuditResult = "success"
+	AuditResultFailed     AuditResult = "failed"
+	AuditResultRolledBack AuditResult = "rolled_back"
+)
+
+// AuditRecord describes one change, or attempted change, to a client
+// configuration file. Hashes are empty when the file did not exist, and the
+// before hash also when the operation failed before backing it up. The JSON
+// form is both the audit log line and the output of 'log --json'.
+type AuditRecord struct {
+	Time           time.Time     `json:"time"`
+	OperationID    string        `json:"operation_id,omitempty"`
+	Operation      OperationType `json:"operation"`
+	Client         ClientType    `json:"client"`
+	ConfigPath     string        `json:"config_path,omitempty"`
+	BeforeHash     string        `json:"before_hash,omitempty"`
+	AfterHash      string        `json:"after_hash,omitempty"`
+	Server         string        `json:"server,omitempty"`
+	KeyFingerprint string        `json:"key_fingerprint,omitempty"`
+	ToolVersion    string        `json:"tool_version"`
+	User           string        `json:"user,omitempty"`
+	Result         AuditResult   `json:"result"`
+	Error          string        `json:"error,omitempty"`
+}
+
+type AuditFilter struct {
+	Client      ClientType
+	OperationID string
+	Operation   OperationType
+	Result      AuditResult
+	Since       time.Time
+	// Limit keeps only the most recent matching records when positive.
+	Limit int
+}
+
+func (f AuditFilter) Matches(record *AuditRecord) bool {
+	if f.Client != "" && record.Client != f.Client {
+		return false
+	}
+	if f.OperationID != "" && record.OperationID != f.OperationID {
+		return false
+	}
+	if f.Operation != "" && record.Operation != f.Operation {
+		return false
+	}
+	if f.Result != "" && record.Result != f.Result {
+		return false
+	}
+	if !f.Since.IsZero() && record.Time.Before(f.Since) {
+		return false
+	}
+	return true
+}
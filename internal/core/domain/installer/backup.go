@@ -0,0 +1,130 @@
+package installer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultBackupKeepLast = 20
+	DefaultBackupMaxAge   = 90 * 24 * time.Hour
+)
+
+// FileState identifies the content of a configuration file at one point in time.
+type FileState struct {
+	Exists bool
+	Hash   string
+}
+
+// Backup describes the state of a client configuration file before an
+// operation changed it. Copies with identical content share the same stored
+// blob, identified by Hash. When the file did not exist yet, Existed is false
+// and restoring the backup deletes the file.
+//
+// After is recorded once the operation completed, so that undo can tell
+// whether the file was changed again since. A scrubbed backup had its secrets
+// redacted by purge; restoring it brings back the file without them.
+//
+// Scope and Project name the servers of the file the operation targeted, so
+// that a file holding several scopes is read at the right one. Backups taken
+// before they were recorded are read at the user scope.
+type Backup struct {
+	ID          string
+	OperationID string
+	Client      ClientType
+	Scope       Scope
+	Project     string
+	Operation   OperationType
+	ConfigPath  string
+	Existed     bool
+	Hash        string
+	Size        int64
+	ToolVersion string
+	CreatedAt   time.Time
+	After       *FileState
+	Scrubbed    bool
+}
+
+func (b *Backup) Completed() bool {
+	return b.After != nil
+}
+
+type BackupFilter struct {
+	Client      ClientType
+	ConfigPath  string
+	OperationID string
+}
+
+func (f BackupFilter) Matches(backup *Backup) bool {
+	if f.Client != "" && backup.Client != f.Client {
+		return false
+	}
+	if f.ConfigPath != "" && backup.ConfigPath != f.ConfigPath {
+		return false
+	}
+	if f.OperationID != "" && backup.OperationID != f.OperationID {
+		return false
+	}
+	return true
+}
+
+// RetentionPolicy decides which backups prune keeps. It applies to each
+// configuration file separately, and the newest backup of a file is always kept.
+// Zero values disable the corresponding limit.
+type RetentionPolicy struct {
+	KeepLast int
+	MaxAge   time.Duration
+}
+
+func DefaultRetentionPolicy() RetentionPolicy {
+	return RetentionPolicy{
+		KeepLast: DefaultBackupKeepLast,
+		MaxAge:   DefaultBackupMaxAge,
+	}
+}
+
+type Settings struct {
+	Backups    RetentionPolicy
+	Profiles   map[string]ServerProfile
+	Signatures SignaturePolicy
+}
+
+func DefaultSettings() *Settings {
+	return &Settings{
+		Backups: DefaultRetentionPolicy(),
+		Profiles: map[string]ServerProfile{
+			DefaultProfile: DefaultServerProfile(),
+		},
+	}
+}
+
+// ParseAge parses a backup age such as "720h" or "30d". An empty value or "0"
+// means no limit.
+func ParseAge(value string) (time.Duration, error) {
+	if value == "" || value == "0" {
+		return 0, nil
+	}
+
+	var age time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q", value)
+		}
+		age = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		age, err = time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q", value)
+		}
+	}
+
+	if age < 0 {
+		return 0, fmt.Errorf("negative age %q", value)
+	}
+
+	return age, nil
+}
@@ -0,0 +1,292 @@
+package installer
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+)
+
+// BundleVersion is the version of the bundle format written by export.
+const BundleVersion = 1
+
+type BundleFormat string
+
+const (
+	BundleFormatJSON BundleFormat = "json"
+	BundleFormatYAML BundleFormat = "yaml"
+)
+
+// Bundle is a client-neutral, shareable set of MCP servers. Secrets are
+// replaced by ${NAME} placeholders, listed in Placeholders, that are resolved
+// again on import. Placeholders are only allowed in header and environment
+// values.
+type Bundle struct {
+	Version      int
+	Source       ClientType
+	CreatedAt    time.Time
+	Servers      []*McpServer
+	Placeholders []Placeholder
+	// Warnings describe what export left in clear text or removed. They are
+	// not part of the encoded bundle.
+	Warnings []string
+}
+
+// SignaturePolicy decides which signed bundles may be imported.
+type SignaturePolicy struct {
+	// TrustedKeys are ed25519 public keys formatted as "ed25519:<base64>".
+	TrustedKeys []string
+	// Require refuses bundles that are not signed by a trusted key.
+	Require bool
+}
+
+type SignatureStatus string
+
+const (
+	SignatureUnsigned SignatureStatus = "unsigned"
+	SignatureVerified SignatureStatus = "verified"
+	// SignatureUntrusted means the signature matches the bundle but was made
+	// by a key outside the trust store.
+	SignatureUntrusted SignatureStatus = "untrusted"
+)
+
+type BundleVerification struct {
+	Status SignatureStatus
+	KeyID  string
+}
+
+type Placeholder struct {
+	Name        string
+	Description string
+}
+
+// redactedValue replaces URL credentials and query values on export.
+const redactedValue = "REDACTED"
+
+var (
+	placeholderPattern = regexp.MustCompile(`\$\{([A-Z0-9_]+)\}`)
+	secretNamePattern  = regexp.MustCompile(`(?i)auth|key|token|secret|password|passwd|cookie|credential`)
+	nonIdentifierChars = regexp.MustCompile(`[^A-Z0-9]+`)
+)
+
+// NewBundle builds a bundle from servers, replacing the values of headers and
+// environment variables that look like secrets with placeholders, and the
+// credentials and query values of URLs with REDACTED. Arguments and other
+// environment values are kept and reported in Warnings. The servers are not
+// modified.
+func NewBundle(source ClientType, servers []*McpServer, now time.Time) *Bundle {
+	bundle := &Bundle{
+		Version:   BundleVersion,
+		Source:    source,
+		CreatedAt: now.UTC(),
+	}
+
+	for _, server := range servers {
+		exported := *server
+		exported.URL = bundle.redactURL(server.Name, server.URL)
+		exported.Headers = bundle.redact(server.Name, "header", server.Headers)
+		exported.Env = bundle.redact(server.Name, "environment variable", server.Env)
+		if len(server.Args) > 0 {
+			bundle.Warnings = append(bundle.Warnings, fmt.Sprintf("arguments of server %s are exported in clear text", server.Name))
+		}
+		bundle.Servers = append(bundle.Servers, &exported)
+	}
+
+	return bundle
+}
+
+// redactURL replaces the credentials and query values of a URL, which a
+// placeholder cannot stand for.
+func (b *Bundle) redactURL(server, raw string) string {
+	if raw == "" {
+		return raw
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.User == nil && parsed.RawQuery == "") {
+		return raw
+	}
+
+	if parsed.User != nil {
+		if _, ok := parsed.User.Password(); ok {
+			parsed.User = url.UserPassword(parsed.User.Username(), redactedValue)
+		} else {
+			parsed.User = url.User(redactedValue)
+		}
+	}
+
+	query := parsed.Query()
+	for name, values := range query {
+		for i := range values {
+			values[i] = redactedValue
+		}
+		query[name] = values
+	}
+	parsed.RawQuery = query.Encode()
+
+	b.Warnings = append(b.Warnings, fmt.Sprintf("credentials and query values in the URL of server %s were replaced by %s", server, redactedValue))
+	return parsed.String()
+}
+
+func (b *Bundle) redact(server, kind string, values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	redacted := make(map[string]string, len(values))
+	for _, name := range names {
+		value := values[name]
+		if matches := placeholderPattern.FindAllStringSubmatch(value, -1); len(matches) > 0 {
+			// Already a placeholder in the source configuration.
+			for _, match := range matches {
+				b.declare(Placeholder{Name: match[1], Description: fmt.Sprintf("%s %s of server %s", name, kind, server)})
+			}
+			redacted[name] = value
+			continue
+		}
+		if !secretNamePattern.MatchString(name) || value == "" {
+			if value != "" && kind == "environment variable" {
+				b.Warnings = append(b.Warnings, fmt.Sprintf("%s %s of server %s is exported in clear text", name, kind, server))
+			}
+			redacted[name] = value
+			continue
+		}
+
+		placeholder := Placeholder{
+			Name:        b.uniqueName(placeholderName(server, name)),
+			Description: fmt.Sprintf("%s %s of server %s", name, kind, server),
+		}
+		b.Placeholders = append(b.Placeholders, placeholder)
+
+		reference := "${" + placeholder.Name + "}"
+		if strings.HasPrefix(value, "Bearer ") {
+			redacted[name] = "Bearer " + reference
+		} else {
+			redacted[name] = reference
+		}
+	}
+
+	return redacted
+}
+
+// declare adds a placeholder unless one of the same name is declared.
+func (b *Bundle) declare(placeholder Placeholder) {
+	for _, declared := range b.Placeholders {
+		if declared.Name == placeholder.Name {
+			return
+		}
+	}
+	b.Placeholders = append(b.Placeholders, placeholder)
+}
+
+// uniqueName numbers name when another placeholder already has it, such as
+// for servers named my-server and my_server.
+func (b *Bundle) uniqueName(name string) string {
+	taken := make(map[string]bool, len(b.Placeholders))
+	for _, placeholder := range b.Placeholders {
+		taken[placeholder.Name] = true
+	}
+
+	unique := name
+	for i := 2; taken[unique]; i++ {
+		unique = name + "_" + strconv.Itoa(i)
+	}
+	return unique
+}
+
+// placeholderName derives an upper-case identifier from the server and the
+// setting, such as KIRHA_AUTHORIZATION.
+func placeholderName(server, setting string) string {
+	prefix := strings.Trim(nonIdentifierChars.ReplaceAllString(strings.ToUpper(server), "_"), "_")
+	name := strings.Trim(nonIdentifierChars.ReplaceAllString(strings.ToUpper(setting), "_"), "_")
+	if prefix == "" || strings.HasPrefix(name, prefix+"_") {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+// Validate checks that placeholders only appear in header and environment
+// values and are all declared, so a bundle cannot make import send the value
+// of an arbitrary variable elsewhere.
+func (b *Bundle) Validate() error {
+	declared := make(map[string]bool, len(b.Placeholders))
+	for _, placeholder := range b.Placeholders {
+		declared[placeholder.Name] = true
+	}
+
+	for _, server := range b.Servers {
+		fields := append([]string{server.URL, server.Command}, server.Args...)
+		for _, field := range fields {
+			if match := placeholderPattern.FindString(field); match != "" {
+				return fmt.Errorf("%w: server %s: %s is only allowed in header and environment values", errors.ErrBundleInvalid, server.Name, match)
+			}
+		}
+
+		for _, values := range []map[string]string{server.Headers, server.Env} {
+			for _, value := range values {
+				for _, match := range placeholderPattern.FindAllStringSubmatch(value, -1) {
+					if !declared[match[1]] {
+						return fmt.Errorf("%w: server %s: placeholder %s is not declared", errors.ErrBundleInvalid, server.Name, match[1])
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Resolve returns the bundle's servers with the placeholders of header and
+// environment values replaced by their value, along with the names of
+// placeholders that have no value.
+func (b *Bundle) Resolve(values map[string]string) ([]*McpServer, []string) {
+	missing := make(map[string]bool)
+	expand := func(value string) string {
+		return placeholderPattern.ReplaceAllStringFunc(value, func(match string) string {
+			name := placeholderPattern.FindStringSubmatch(match)[1]
+			resolved, ok := values[name]
+			if !ok {
+				missing[name] = true
+				return match
+			}
+			return resolved
+		})
+	}
+	expandMap := func(in map[string]string) map[string]string {
+		if in == nil {
+			return nil
+		}
+		out := make(map[string]string, len(in))
+		for k, v := range in {
+			out[k] = expand(v)
+		}
+		return out
+	}
+
+	servers := make([]*McpServer, 0, len(b.Servers))
+	for _, server := range b.Servers {
+		resolved := *server
+		resolved.Headers = expandMap(server.Headers)
+		resolved.Env = expandMap(server.Env)
+		servers = append(servers, &resolved)
+	}
+
+	names := make([]string, 0, len(missing))
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return servers, names
+}
@@ -0,0 +1,28 @@
+package installer
+
+type CopyStatus string
+
+const (
+	CopyCopied    CopyStatus = "added"
+	CopyWouldCopy CopyStatus = "would_add"
+	CopyExists    CopyStatus = "exists"
+)
+
+// CopiedServer is one server written by a copy or an import. Warnings name
+// the settings the target client cannot represent, which were left out.
+type CopiedServer struct {
+	Name     string
+	Type     string
+	Status   CopyStatus
+	Warnings []string
+}
+
+type CopyResult struct {
+	From        ClientType
+	To          ClientType
+	ConfigPath  string
+	BackupID    string
+	OperationID string
+	Servers     []CopiedServer
+	DryRun      bool
+}
@@ -0,0 +1,107 @@
+package installer
+
+import (
+	"sort"
+	"strings"
+)
+
+// FieldDifference is one setting that differs between two definitions of a
+// server. An empty value means the setting is not set on that side.
+type FieldDifference struct {
+	Field string
+	Left  string
+	Right string
+}
+
+// Compare lists the settings that differ between two definitions of a
+// server: "transport", "url", "header <name>", "command", "args",
+// "env <name>" and "enabled".
+func (s *McpServer) Compare(other *McpServer) []FieldDifference {
+	var differences []FieldDifference
+	add := func(field, left, right string) {
+		if left != right {
+			differences = append(differences, FieldDifference{Field: field, Left: left, Right: right})
+		}
+	}
+
+	add("transport", s.Type, other.Type)
+	add("url", s.URL, other.URL)
+	for _, name := range unionKeys(s.Headers, other.Headers) {
+		add("header "+name, s.Headers[name], other.Headers[name])
+	}
+	add("command", s.Command, other.Command)
+	add("args", strings.Join(s.Args, " "), strings.Join(other.Args, " "))
+	for _, name := range unionKeys(s.Env, other.Env) {
+		add("env "+name, s.Env[name], other.Env[name])
+	}
+	add("enabled", enabledValue(s.Disabled), enabledValue(other.Disabled))
+
+	return differences
+}
+
+// ServerComparison pairs the definitions of a server in two clients. Left or
+// Right is nil when the server only exists on the other side.
+type ServerComparison struct {
+	Name        string
+	Left        *McpServer
+	Right       *McpServer
+	Differences []FieldDifference
+}
+
+type ClientComparison struct {
+	Left      ClientType
+	Right     ClientType
+	LeftPath  string
+	RightPath string
+	Servers   []ServerComparison
+}
+
+// CompareServers pairs two server lists by name, ordered by name.
+func CompareServers(left, right []*McpServer) []ServerComparison {
+	byName := make(map[string]*ServerComparison)
+	for _, server := range left {
+		byName[server.Name] = &ServerComparison{Name: server.Name, Left: server}
+	}
+	for _, server := range right {
+		comparison, ok := byName[server.Name]
+		if !ok {
+			comparison = &ServerComparison{Name: server.Name}
+			byName[server.Name] = comparison
+		}
+		comparison.Right = server
+	}
+
+	comparisons := make([]ServerComparison, 0, len(byName))
+	for _, comparison := range byName {
+		if comparison.Left != nil && comparison.Right != nil {
+			comparison.Differences = comparison.Left.Compare(comparison.Right)
+		}
+		comparisons = append(comparisons, *comparison)
+	}
+	sort.Slice(comparisons, func(i, j int) bool {
+		return comparisons[i].Name < comparisons[j].Name
+	})
+
+	return comparisons
+}
+
+func unionKeys(left, right map[string]string) []string {
+	keys := make([]string, 0, len(left)+len(right))
+	for key := range left {
+		keys = append(keys, key)
+	}
+	for key := range right {
+		if _, ok := left[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func enabledValue(disabled bool) string {
+	if disabled {
+		return "false"
+	}
+	return "true"
+}
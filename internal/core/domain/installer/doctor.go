@@ -0,0 +1,80 @@
+package installer
+
+type DiagnosisCheck string
+
+const (
+	CheckConfig      DiagnosisCheck = "config"
+	CheckPermissions DiagnosisCheck = "permissions"
+	CheckKirhaEntry  DiagnosisCheck = "kirha_entry"
+	CheckDuplicates  DiagnosisCheck = "duplicates"
+	CheckTransport   DiagnosisCheck = "transport"
+	CheckProcess     DiagnosisCheck = "process"
+	CheckBackups     DiagnosisCheck = "backups"
+	CheckEndpoint    DiagnosisCheck = "endpoint"
+)
+
+type Severity string
+
+const (
+	SeverityOK      Severity = "ok"
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// DiagnosisFix names a remediation doctor can apply by itself.
+type DiagnosisFix string
+
+const (
+	FixPermissions   DiagnosisFix = "restrict_permissions"
+	FixLegacyBackups DiagnosisFix = "delete_legacy_backups"
+	FixPruneBackups  DiagnosisFix = "prune_backups"
+)
+
+// Finding is the outcome of one check. Hint tells how to remediate anything
+// that is not ok; Fix is set when --fix can do it.
+type Finding struct {
+	Check    DiagnosisCheck
+	Severity Severity
+	Message  string
+	Hint     string
+	Fix      DiagnosisFix
+	Fixed    bool
+	FixErr   error
+}
+
+type ClientDiagnosis struct {
+	Client     ClientType
+	ConfigPath string
+	Findings   []*Finding
+}
+
+// Worst returns the most severe finding that was not fixed.
+func (d *ClientDiagnosis) Worst() Severity {
+	worst := SeverityOK
+	for _, finding := range d.Findings {
+		if finding.Fixed {
+			continue
+		}
+		if severityRank[finding.Severity] > severityRank[worst] {
+			worst = finding.Severity
+		}
+	}
+	return worst
+}
+
+var severityRank = map[Severity]int{
+	SeverityOK:      0,
+	SeverityInfo:    1,
+	SeverityWarning: 2,
+	SeverityError:   3,
+}
+
+type DoctorOptions struct {
+	// Clients to diagnose. Empty means every client that is installed or
+	// has a configuration file.
+	Clients []ClientType
+	Fix     bool
+	// Offline skips the endpoint reachability check.
+	Offline bool
+}
@@ -24,6 +24,12 @@ const (
 	OperationUpdate  OperationType = "update"
 	OperationRemove  OperationType = "remove"
 	OperationShow    OperationType = "show"
+	OperationRestore OperationType = "restore"
+	OperationUndo    OperationType = "undo"
+	OperationPurge   OperationType = "purge"
+	OperationCopy    OperationType = "copy"
+	OperationImport  OperationType = "import"
+	OperationRepair  OperationType = "repair"
 )
 
 type Config struct {
@@ -32,25 +38,49 @@ type Config struct {
 	UpdatedAt time.Time
 
 	Client     ClientType
+	Scope      Scope
+	Profile    string
 	ApiKey     string
 	ConfigPath string
-	Operation  OperationType
-	DryRun     bool
-	Verbose    bool
-	Force      bool
+	// Project is the absolute path of the project directory the local and
+	// project scopes apply to.
+	Project string
+	// Server, when set, replaces the URL and extra headers of the default
+	// Kirha server definition. The key always comes from ApiKey.
+	Server    *McpServer
+	Operation OperationType
+	DryRun    bool
+	Verbose   bool
+	Force     bool
+
+	LockTimeout time.Duration
 }
 
+// Transports of an MCP server, as stored in McpServer.Type. Adapters translate
+// them to and from the spelling of their client.
+const (
+	TransportStdio = "stdio"
+	TransportHTTP  = "http"
+	TransportSSE   = "sse"
+)
+
+// McpServer is the client-neutral definition of an MCP server. URL and
+// Headers apply to remote transports, Command, Args and Env to stdio.
 type McpServer struct {
-	Name    string
-	Type    string
-	URL     string
-	Headers map[string]string
+	Name     string
+	Type     string
+	URL      string
+	Headers  map[string]string
+	Command  string
+	Args     []string
+	Env      map[string]string
+	Disabled bool
 }
 
 func NewKirhaRemoteMcpServer(apiKey string) *McpServer {
 	return &McpServer{
 		Name: ServerName,
-		Type: "http",
+		Type: TransportHTTP,
 		URL:  ServerURL,
 		Headers: map[string]string{
 			"Authorization": "Bearer " + apiKey,
@@ -58,11 +88,43 @@ func NewKirhaRemoteMcpServer(apiKey string) *McpServer {
 	}
 }
 
+// McpServer returns the Kirha server definition to write for this config.
+func (c *Config) McpServer() *McpServer {
+	server := NewKirhaRemoteMcpServer(c.ApiKey)
+	if c.Server == nil {
+		return server
+	}
+
+	server.URL = c.Server.URL
+	for name, value := range c.Server.Headers {
+		if name != authorizationHeader {
+			server.Headers[name] = value
+		}
+	}
+
+	return server
+}
+
+// Mutates reports whether the operation may change client configuration files.
+func (c *Config) Mutates() bool {
+	if c.DryRun {
+		return false
+	}
+
+	switch c.Operation {
+	case OperationInstall, OperationUpdate, OperationRemove, OperationRestore, OperationUndo, OperationPurge, OperationCopy, OperationImport, OperationRepair:
+		return true
+	default:
+		return false
+	}
+}
+
 type InstallResult struct {
-	Success    bool
-	ConfigPath string
-	BackupPath string
-	Message    string
+	Success     bool
+	ConfigPath  string
+	BackupID    string
+	OperationID string
+	Message     string
 }
 
 type ShowResult struct {
@@ -73,3 +135,35 @@ type ShowResult struct {
 	FullConfig   string
 	Message      string
 }
+
+type DetectResult struct {
+	Client       ClientType
+	BinaryPath   string
+	ConfigPath   string
+	ConfigExists bool
+	Running      bool
+	HasServer    bool
+	Error        string
+}
+
+// Detected reports whether the client appears to be installed on this machine,
+// either because its binary is on PATH or because its configuration file exists.
+func (d *DetectResult) Detected() bool {
+	return d.BinaryPath != "" || d.ConfigExists
+}
+
+type BatchOptions struct {
+	Concurrency int
+	Timeout     time.Duration
+	LockTimeout time.Duration
+}
+
+type BatchResult struct {
+	Client     ClientType
+	Scope      Scope
+	Project    string
+	Result     *InstallResult
+	Err        error
+	Duration   time.Duration
+	RolledBack bool
+}
@@ -1,6 +1,7 @@
 package installer
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -80,3 +81,108 @@ func TestConfig_Validation(t *testing.T) {
 		t.Errorf("Config.ApiKey = %v, want %v", config.ApiKey, "test-key")
 	}
 }
+
+func TestMcpServer_Differences(t *testing.T) {
+	desired := (&Config{
+		ApiKey: "new-key",
+		Server: &McpServer{
+			URL:     "https://mcp.kirha.com/team",
+			Headers: map[string]string{"X-Team": "core", "Authorization": "Bearer ignored"},
+		},
+	}).McpServer()
+
+	if desired.ApiKey() != "new-key" {
+		t.Fatalf("Config.McpServer().ApiKey() = %v, want new-key", desired.ApiKey())
+	}
+
+	current := NewKirhaRemoteMcpServer("old-key")
+	current.Headers["X-Legacy"] = "1"
+
+	got := desired.Differences(current)
+	want := []string{"url", "key", "header X-Legacy", "header X-Team"}
+	if len(got) != len(want) {
+		t.Fatalf("Differences() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Differences()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	if differences := desired.Differences(desired); len(differences) != 0 {
+		t.Errorf("Differences() with itself = %v, want none", differences)
+	}
+}
+
+func TestCompareServers(t *testing.T) {
+	left := []*McpServer{
+		NewKirhaRemoteMcpServer("left-key"),
+		{Name: "fs", Type: TransportStdio, Command: "npx", Args: []string{"server-filesystem"}},
+	}
+	right := []*McpServer{
+		NewKirhaRemoteMcpServer("right-key"),
+		{Name: "events", Type: TransportSSE, URL: "https://events.example.com/sse", Disabled: true},
+	}
+
+	comparisons := CompareServers(left, right)
+	if len(comparisons) != 3 {
+		t.Fatalf("CompareServers() returned %d servers, want 3", len(comparisons))
+	}
+
+	if comparisons[0].Name != "events" || comparisons[0].Left != nil || comparisons[0].Right == nil {
+		t.Errorf("CompareServers()[0] = %+v, want events only on the right", comparisons[0])
+	}
+	if comparisons[1].Name != "fs" || comparisons[1].Left == nil || comparisons[1].Right != nil {
+		t.Errorf("CompareServers()[1] = %+v, want fs only on the left", comparisons[1])
+	}
+
+	differences := comparisons[2].Differences
+	if len(differences) != 1 || differences[0].Field != "header Authorization" {
+		t.Fatalf("kirha differences = %+v, want the Authorization header", differences)
+	}
+	if differences[0].Left != "Bearer left-key" || differences[0].Right != "Bearer right-key" {
+		t.Errorf("kirha difference = %+v", differences[0])
+	}
+
+	enabled := right[1].Compare(&McpServer{Name: "events", Type: TransportSSE, URL: "https://events.example.com/sse"})
+	if len(enabled) != 1 || enabled[0].Field != "enabled" || enabled[0].Left != "false" {
+		t.Errorf("Compare() = %+v, want only the enabled state", enabled)
+	}
+}
+
+func TestPolicy_Check(t *testing.T) {
+	policy := &Policy{
+		AllowedURLs:         []string{"https://mcp.kirha.com/", "*.example.com"},
+		ForbidPlaintextHTTP: true,
+		RequireEnvKeys:      true,
+		AllowedCommands:     []string{"/opt/mcp/bin", "npx"},
+	}
+
+	tests := []struct {
+		name   string
+		server *McpServer
+		rules  []PolicyRule
+	}{
+		{"allowed URL with env key", &McpServer{URL: "https://mcp.kirha.com/mcp", Headers: map[string]string{"Authorization": "Bearer ${KIRHA_API_KEY}"}}, nil},
+		{"wildcard host", &McpServer{URL: "https://api.example.com/mcp"}, nil},
+		{"lookalike host", &McpServer{URL: "https://mcp.kirha.com.evil.io/mcp"}, []PolicyRule{PolicyRuleAllowedURLs}},
+		{"plaintext http", &McpServer{URL: "http://api.example.com/mcp"}, []PolicyRule{PolicyRuleNoPlaintextHTTP}},
+		{"literal key", &McpServer{URL: "https://mcp.kirha.com", Headers: map[string]string{"Authorization": "Bearer sk-123"}}, []PolicyRule{PolicyRuleEnvKeys}},
+		{"opencode env reference", &McpServer{Command: "npx", Env: map[string]string{"API_TOKEN": "{env:API_TOKEN}"}}, nil},
+		{"approved directory", &McpServer{Command: "/opt/mcp/bin/server"}, nil},
+		{"escaping directory", &McpServer{Command: "/opt/mcp/bin/../../../tmp/server"}, []PolicyRule{PolicyRuleAllowedCommands}},
+		{"unapproved command", &McpServer{Command: "uvx"}, []PolicyRule{PolicyRuleAllowedCommands}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rules []PolicyRule
+			for _, violation := range policy.Check(ClientTypeCodex, tt.server) {
+				rules = append(rules, violation.Rule)
+			}
+			if fmt.Sprint(rules) != fmt.Sprint(tt.rules) {
+				t.Errorf("Check() rules = %v, want %v", rules, tt.rules)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package installer
+
+import (
+	"fmt"
+	"strings"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+)
+
+// IntegrityError reports a write that changed a configuration beyond the
+// server entries it targeted, or did not store them as intended. Changes
+// lists the JSONPath of each unexpected difference. The previous content has
+// been put back unless RestoreErr is set.
+type IntegrityError struct {
+	Path       string
+	Changes    []string
+	RestoreErr error
+}
+
+func (e *IntegrityError) Error() string {
+	outcome := "the previous content was restored"
+	if e.RestoreErr != nil {
+		outcome = fmt.Sprintf("restoring the previous content failed: %v", e.RestoreErr)
+	}
+	return fmt.Sprintf("%s: %s: %s; %s", errors.ErrUnexpectedChange, e.Path, strings.Join(e.Changes, ", "), outcome)
+}
+
+func (e *IntegrityError) Is(target error) bool {
+	return target == errors.ErrUnexpectedChange
+}
@@ -0,0 +1,107 @@
+package installer
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+)
+
+const OperationIDPrefix = "op-"
+
+// NewOperationID returns an identifier for a mutating operation. IDs sort by
+// start time and stay unique when several operations start within one second.
+func NewOperationID(now time.Time) string {
+	suffix := make([]byte, 3)
+	_, _ = rand.Read(suffix)
+	return OperationIDPrefix + now.UTC().Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// ClientRunningError names the running client that stopped an operation
+// spanning several clients.
+type ClientRunningError struct {
+	Client ClientType
+}
+
+func (e *ClientRunningError) Error() string {
+	return fmt.Sprintf("%s: %s", errors.ErrClientRunning, e.Client)
+}
+
+func (e *ClientRunningError) Is(target error) bool {
+	return target == errors.ErrClientRunning
+}
+
+// UndoChange describes what undo did to one configuration file.
+type UndoChange struct {
+	Client     ClientType
+	ConfigPath string
+	Deleted    bool
+	Conflict   bool
+	// Scrubbed means the restored content had its API keys redacted.
+	Scrubbed bool
+}
+
+type UndoResult struct {
+	OperationID string
+	Operation   OperationType
+	UndoID      string
+	Changes     []UndoChange
+	DryRun      bool
+}
+
+type PurgeOptions struct {
+	BatchOptions
+	// DeleteBackups deletes backups holding secrets instead of scrubbing them.
+	DeleteBackups bool
+}
+
+// PurgeSkip is a client configuration that purge could not read.
+type PurgeSkip struct {
+	Client     ClientType
+	Scope      Scope
+	Project    string
+	ConfigPath string
+	Error      string
+}
+
+// PurgeResult summarizes what purge removed or, on a dry run, would remove.
+type PurgeResult struct {
+	OperationID     string
+	Clients         []*BatchResult
+	Skipped         []PurgeSkip
+	ScrubbedBackups []*Backup
+	DeletedBackups  []*Backup
+	LegacyBackups   []string
+	ForgottenState  int
+	DryRun          bool
+}
+
+type KeyRotationStatus string
+
+const (
+	KeyRotated     KeyRotationStatus = "rotated"
+	KeyWouldRotate KeyRotationStatus = "would_rotate"
+	KeyUpToDate    KeyRotationStatus = "up_to_date"
+	// KeyOtherKey means the client uses a key other than the one being replaced.
+	KeyOtherKey   KeyRotationStatus = "other_key"
+	KeyFailed     KeyRotationStatus = "failed"
+	KeyRolledBack KeyRotationStatus = "rolled_back"
+)
+
+// KeyRotation is the outcome of a key rotation for one client configuration.
+type KeyRotation struct {
+	Client     ClientType
+	Scope      Scope
+	Project    string
+	ConfigPath string
+	Status     KeyRotationStatus
+	Err        error
+}
+
+type RotateKeyResult struct {
+	OperationID string
+	Clients     []KeyRotation
+	DryRun      bool
+}
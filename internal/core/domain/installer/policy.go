@@ -0,0 +1,218 @@
+package installer
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+)
+
+type PolicyRule string
+
+const (
+	PolicyRuleAllowedURLs     PolicyRule = "allowed_urls"
+	PolicyRuleNoPlaintextHTTP PolicyRule = "forbid_plaintext_http"
+	PolicyRuleEnvKeys         PolicyRule = "require_env_keys"
+	PolicyRuleAllowedCommands PolicyRule = "allowed_commands"
+)
+
+// Policy holds the organization guardrails of one policy file. A nil list
+// leaves its rule off, while an empty one allows nothing.
+type Policy struct {
+	// Path is the file the policy was read from.
+	Path string
+	// AllowedURLs are URL prefixes such as "https://mcp.kirha.com/" or host
+	// patterns such as "*.example.com".
+	AllowedURLs []string
+	// ForbidPlaintextHTTP refuses http:// URLs, except for loopback hosts.
+	ForbidPlaintextHTTP bool
+	// RequireEnvKeys refuses secret-looking headers and environment variables
+	// whose value is a literal rather than a reference like ${API_KEY}.
+	RequireEnvKeys bool
+	// AllowedCommands are executables or directories stdio servers may run
+	// from. Bare names such as "npx" only match the same bare name.
+	AllowedCommands []string
+}
+
+type PolicyViolation struct {
+	Policy  string
+	Rule    PolicyRule
+	Client  ClientType
+	Server  string
+	Message string
+}
+
+func (v PolicyViolation) String() string {
+	return fmt.Sprintf("%s: server %s: %s", v.Rule, v.Server, v.Message)
+}
+
+// PolicyError is returned when servers about to be written break a policy.
+type PolicyError struct {
+	Violations []PolicyViolation
+}
+
+func (e *PolicyError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.String())
+	}
+	return fmt.Sprintf("%s: %s", errors.ErrPolicyViolation, strings.Join(messages, "; "))
+}
+
+func (e *PolicyError) Is(target error) bool {
+	return target == errors.ErrPolicyViolation
+}
+
+var envReferencePattern = regexp.MustCompile(`^(\$\{[A-Za-z_][A-Za-z0-9_]*\}|\$[A-Za-z_][A-Za-z0-9_]*|\{env:[A-Za-z_][A-Za-z0-9_]*\})$`)
+
+// Check returns the rules of the policy that server breaks in client.
+func (p *Policy) Check(client ClientType, server *McpServer) []PolicyViolation {
+	var violations []PolicyViolation
+	violate := func(rule PolicyRule, format string, args ...any) {
+		violations = append(violations, PolicyViolation{
+			Policy:  p.Path,
+			Rule:    rule,
+			Client:  client,
+			Server:  server.Name,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if server.URL != "" {
+		u, err := url.Parse(server.URL)
+		if err != nil {
+			violate(PolicyRuleAllowedURLs, "URL %q cannot be parsed", server.URL)
+		} else {
+			if p.AllowedURLs != nil && !urlAllowed(u, server.URL, p.AllowedURLs) {
+				violate(PolicyRuleAllowedURLs, "URL %s is not in the allowlist", server.URL)
+			}
+			if p.ForbidPlaintextHTTP && strings.EqualFold(u.Scheme, "http") && !isLoopback(u.Hostname()) {
+				violate(PolicyRuleNoPlaintextHTTP, "URL %s uses plaintext http", server.URL)
+			}
+		}
+	}
+
+	if p.RequireEnvKeys {
+		for _, name := range unionKeys(server.Headers, nil) {
+			if isLiteralSecret(name, server.Headers[name]) {
+				violate(PolicyRuleEnvKeys, "header %s holds a literal secret, reference an environment variable instead", name)
+			}
+		}
+		for _, name := range unionKeys(server.Env, nil) {
+			if isLiteralSecret(name, server.Env[name]) {
+				violate(PolicyRuleEnvKeys, "environment variable %s holds a literal secret, reference an environment variable instead", name)
+			}
+		}
+	}
+
+	if server.Command != "" && p.AllowedCommands != nil && !commandAllowed(server.Command, p.AllowedCommands) {
+		violate(PolicyRuleAllowedCommands, "command %s is outside the approved paths", server.Command)
+	}
+
+	return violations
+}
+
+// CheckPolicies evaluates servers against every policy. Each policy applies
+// on its own, so a server must satisfy all of them.
+func CheckPolicies(policies []*Policy, client ClientType, servers []*McpServer) []PolicyViolation {
+	var violations []PolicyViolation
+	for _, policy := range policies {
+		for _, server := range servers {
+			violations = append(violations, policy.Check(client, server)...)
+		}
+	}
+	return violations
+}
+
+func urlAllowed(u *url.URL, raw string, allowed []string) bool {
+	for _, entry := range allowed {
+		if strings.Contains(entry, "://") {
+			prefix := strings.TrimSuffix(entry, "/")
+			if strings.TrimSuffix(raw, "/") == prefix || strings.HasPrefix(raw, prefix+"/") {
+				return true
+			}
+			continue
+		}
+
+		host := strings.ToLower(u.Hostname())
+		pattern := strings.ToLower(entry)
+		if wildcard, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+wildcard) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func isLiteralSecret(name, value string) bool {
+	if !secretNamePattern.MatchString(name) || value == "" {
+		return false
+	}
+	value = strings.TrimPrefix(value, "Bearer ")
+	return !envReferencePattern.MatchString(value)
+}
+
+func commandAllowed(command string, allowed []string) bool {
+	command = strings.ReplaceAll(command, `\`, "/")
+	bare := !strings.Contains(command, "/")
+	if !bare {
+		command = path.Clean(command)
+	}
+
+	for _, entry := range allowed {
+		entry = strings.ReplaceAll(entry, `\`, "/")
+		if !strings.Contains(entry, "/") {
+			if bare && command == entry {
+				return true
+			}
+			continue
+		}
+
+		entry = path.Clean(entry)
+		if !bare && (command == entry || strings.HasPrefix(command, strings.TrimSuffix(entry, "/")+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// PolicyReport is the result of auditing existing configurations against the
+// organization policies.
+type PolicyReport struct {
+	// Policies are the paths of the policy files that were applied.
+	Policies []string
+	Clients  []*ClientPolicyReport
+}
+
+type ClientPolicyReport struct {
+	Client     ClientType
+	ConfigPath string
+	Servers    int
+	Violations []PolicyViolation
+	// Err is set when the configuration could not be read.
+	Err error
+}
+
+// Violations counts the violations of every client.
+func (r *PolicyReport) Violations() int {
+	count := 0
+	for _, client := range r.Clients {
+		count += len(client.Violations)
+	}
+	return count
+}
@@ -0,0 +1,82 @@
+package installer
+
+import (
+	"fmt"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+)
+
+// ParseError locates a syntax error in a configuration file. Line and Column
+// are 1-based and zero when the parser did not report a position.
+type ParseError struct {
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s: %s", errors.ErrConfigInvalid, e.Path, e.Message)
+	}
+	return fmt.Sprintf("%s: %s:%d:%d: %s", errors.ErrConfigInvalid, e.Path, e.Line, e.Column, e.Message)
+}
+
+func (e *ParseError) Is(target error) bool {
+	return target == errors.ErrConfigInvalid
+}
+
+// RepairPlan lists the ways a configuration file can be repaired: safe syntax
+// fixes, restoring the most recent backup that parses, or both.
+type RepairPlan struct {
+	Client     ClientType
+	ConfigPath string
+	// Content and Hash are the file as it was planned against; the repair is
+	// refused if it changed since.
+	Content []byte
+	Hash    string
+	// ParseErr is nil when the file parses, in which case only fixes such as
+	// duplicate keys are offered.
+	ParseErr error
+
+	// Fixes describe the safe fixes, and Fixed is the content once they are
+	// applied. FixedErr is set when the fixes are not enough to make it parse.
+	Fixes    []string
+	Fixed    []byte
+	FixedErr error
+
+	// Backup is the most recent backup of the file that parses, if any.
+	Backup        *Backup
+	BackupContent []byte
+}
+
+// CanFix reports whether the safe fixes yield a valid configuration.
+func (p *RepairPlan) CanFix() bool {
+	return len(p.Fixes) > 0 && p.FixedErr == nil
+}
+
+// NeedsRepair reports whether the file is broken or has something to fix.
+func (p *RepairPlan) NeedsRepair() bool {
+	return p.ParseErr != nil || len(p.Fixes) > 0
+}
+
+type RepairAction string
+
+const (
+	// RepairFix writes the content with the safe fixes applied.
+	RepairFix RepairAction = "fix"
+	// RepairRestore restores the most recent backup that parses.
+	RepairRestore RepairAction = "restore"
+)
+
+// Available reports whether the plan offers action.
+func (p *RepairPlan) Available(action RepairAction) bool {
+	switch action {
+	case RepairFix:
+		return p.CanFix()
+	case RepairRestore:
+		return p.Backup != nil && p.ParseErr != nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,34 @@
+package installer
+
+import (
+	"fmt"
+	"strings"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/errors"
+)
+
+// SchemaViolation is a value of a configuration that its client would reject.
+// Path is a JSONPath expression such as $.mcpServers.kirha.url.
+type SchemaViolation struct {
+	Path    string
+	Message string
+}
+
+// SchemaError reports a configuration that does not match the schema of its
+// client. It is returned before anything is written.
+type SchemaError struct {
+	Path       string
+	Violations []SchemaViolation
+}
+
+func (e *SchemaError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		parts[i] = violation.Path + ": " + violation.Message
+	}
+	return fmt.Sprintf("%s: %s does not match the client schema: %s", errors.ErrConfigInvalid, e.Path, strings.Join(parts, "; "))
+}
+
+func (e *SchemaError) Is(target error) bool {
+	return target == errors.ErrConfigInvalid
+}
@@ -0,0 +1,135 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Scope string
+
+// Scopes at which a client can keep MCP servers. Only the user scope applies
+// to every client; Claude Code also has a local scope, per project but kept
+// in the user's configuration, and a project scope, in a file shared with the
+// project.
+const (
+	ScopeUser    Scope = "user"
+	ScopeLocal   Scope = "local"
+	ScopeProject Scope = "project"
+)
+
+const DefaultProfile = "default"
+
+// ManagedServer records an MCP server entry written by the installer.
+//
+// ServerHash identifies the entry as the installer left it, so that later
+// edits made by hand can be detected. ContentHash identifies the whole file
+// right after the installer wrote it; when the installer created the file and
+// it is still unchanged, removing the server deletes the file again.
+// Project is the project directory of the local and project scopes.
+type ManagedServer struct {
+	Client      ClientType
+	Scope       Scope
+	ConfigPath  string
+	Project     string
+	Server      string
+	Profile     string
+	CreatedFile bool
+	ServerHash  string
+	ContentHash string
+	OperationID string
+	InstalledAt time.Time
+	UpdatedAt   time.Time
+}
+
+// ManagedKey identifies a managed entry. The project is part of it because
+// the local scopes of all projects share one configuration file.
+type ManagedKey struct {
+	Client     ClientType
+	Scope      Scope
+	ConfigPath string
+	Project    string
+}
+
+func (s *ManagedServer) Key() ManagedKey {
+	return ManagedKey{Client: s.Client, Scope: s.Scope, ConfigPath: s.ConfigPath, Project: s.Project}
+}
+
+type DriftStatus string
+
+const (
+	// DriftInSync means the entry is exactly as the installer wrote it.
+	DriftInSync DriftStatus = "in_sync"
+	// DriftModified means the entry was edited outside the installer.
+	DriftModified DriftStatus = "modified"
+	// DriftMissing means the entry was removed outside the installer.
+	DriftMissing DriftStatus = "missing"
+	// DriftUnmanaged means a Kirha entry exists that the installer did not write.
+	DriftUnmanaged DriftStatus = "unmanaged"
+)
+
+type ManagedStatus struct {
+	Client     ClientType
+	Scope      Scope
+	Project    string
+	ConfigPath string
+	Managed    *ManagedServer
+	Drift      DriftStatus
+	Error      string
+}
+
+// Hash returns a digest of the server's normalized settings. Header order
+// does not matter.
+func (s *McpServer) Hash() string {
+	var b strings.Builder
+	b.WriteString(s.Name)
+	b.WriteByte(0)
+	b.WriteString(s.Type)
+	b.WriteByte(0)
+	b.WriteString(s.URL)
+
+	keys := make([]string, 0, len(s.Headers))
+	for key := range s.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		b.WriteByte(0)
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(s.Headers[key])
+	}
+
+	// Stdio settings are only hashed when present, so that the hash of a
+	// remote server does not depend on them.
+	if s.Command != "" || len(s.Args) > 0 || len(s.Env) > 0 || s.Disabled {
+		b.WriteByte(0)
+		b.WriteString(s.Command)
+		for _, arg := range s.Args {
+			b.WriteByte(0)
+			b.WriteString(arg)
+		}
+
+		envKeys := make([]string, 0, len(s.Env))
+		for key := range s.Env {
+			envKeys = append(envKeys, key)
+		}
+		sort.Strings(envKeys)
+		for _, key := range envKeys {
+			b.WriteByte(0)
+			b.WriteString(key)
+			b.WriteByte('=')
+			b.WriteString(s.Env[key])
+		}
+
+		if s.Disabled {
+			b.WriteString("\x00disabled")
+		}
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,95 @@
+package installer
+
+import (
+	"sort"
+	"strings"
+)
+
+const authorizationHeader = "Authorization"
+
+// ServerProfile is a named Kirha server definition from the installer
+// settings. API keys are never part of a profile.
+type ServerProfile struct {
+	URL     string
+	Headers map[string]string
+}
+
+func DefaultServerProfile() ServerProfile {
+	return ServerProfile{URL: ServerURL}
+}
+
+// McpServer returns the Kirha entry the profile describes, without a key.
+func (p ServerProfile) McpServer() *McpServer {
+	return &McpServer{
+		Name:    ServerName,
+		URL:     p.URL,
+		Headers: p.Headers,
+	}
+}
+
+// ApiKey returns the bearer token of the server's Authorization header.
+func (s *McpServer) ApiKey() string {
+	return strings.TrimPrefix(s.Headers[authorizationHeader], "Bearer ")
+}
+
+// Differences lists what differs between two definitions of the server:
+// "url", "key" and the names of other differing headers. The type is not
+// compared, as every client spells it its own way.
+func (s *McpServer) Differences(other *McpServer) []string {
+	var differences []string
+	if s.URL != other.URL {
+		differences = append(differences, "url")
+	}
+	if s.ApiKey() != other.ApiKey() {
+		differences = append(differences, "key")
+	}
+
+	var headers []string
+	for name, value := range s.Headers {
+		if name != authorizationHeader && other.Headers[name] != value {
+			headers = append(headers, name)
+		}
+	}
+	for name := range other.Headers {
+		if _, ok := s.Headers[name]; !ok && name != authorizationHeader {
+			headers = append(headers, name)
+		}
+	}
+	sort.Strings(headers)
+
+	for _, name := range headers {
+		differences = append(differences, "header "+name)
+	}
+
+	return differences
+}
+
+type SyncStatus string
+
+const (
+	SyncInSync  SyncStatus = "in_sync"
+	SyncMissing SyncStatus = "missing"
+	SyncDrifted SyncStatus = "drifted"
+	SyncFailed  SyncStatus = "failed"
+)
+
+// SyncChange is the drift found in one client and what sync did about it.
+type SyncChange struct {
+	Client      ClientType
+	Scope       Scope
+	Project     string
+	ConfigPath  string
+	Status      SyncStatus
+	Differences []string
+	Applied     bool
+	RolledBack  bool
+	Err         error
+}
+
+type SyncResult struct {
+	Source      ClientType
+	Profile     string
+	OperationID string
+	Clients     []*SyncChange
+	DryRun      bool
+}
@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+type AuditLog interface {
+	// Append adds a record to the log. Time, user and tool version are filled
+	// in when left empty.
+	Append(ctx context.Context, record *installer.AuditRecord) error
+	// Read returns the matching records, oldest first.
+	Read(ctx context.Context, filter installer.AuditFilter) ([]*installer.AuditRecord, error)
+}
@@ -0,0 +1,34 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+type BackupStore interface {
+	// Create saves the current content of backup.ConfigPath and fills in the
+	// remaining metadata. A missing file is recorded as not existing.
+	Create(ctx context.Context, backup *installer.Backup) (*installer.Backup, error)
+	// RecordAfter stores the state the file was left in once the operation
+	// that took the backup completed.
+	RecordAfter(ctx context.Context, backup *installer.Backup) (*installer.Backup, error)
+	FileState(ctx context.Context, path string) (installer.FileState, error)
+	List(ctx context.Context, filter installer.BackupFilter) ([]*installer.Backup, error)
+	Get(ctx context.Context, id string) (*installer.Backup, error)
+	Content(ctx context.Context, backup *installer.Backup) ([]byte, error)
+	// Restore writes the backed up content over backup.ConfigPath, or deletes
+	// the file if it did not exist when the backup was taken.
+	Restore(ctx context.Context, backup *installer.Backup) error
+	// Rewrite replaces the stored content of an existing backup and saves its
+	// metadata as given, such as the Scrubbed flag.
+	Rewrite(ctx context.Context, backup *installer.Backup, content []byte) (*installer.Backup, error)
+	Delete(ctx context.Context, backup *installer.Backup) error
+	// Prune deletes the backups outside policy, or only reports them when dryRun is set.
+	Prune(ctx context.Context, policy installer.RetentionPolicy, now time.Time, dryRun bool) ([]*installer.Backup, error)
+}
+
+type SettingsProvider interface {
+	Load(ctx context.Context) (*installer.Settings, error)
+}
@@ -0,0 +1,10 @@
+package ports
+
+import "go.kirha.ai/mcp-installer/internal/core/domain/installer"
+
+// BundleCodec converts server bundles to and from their file formats.
+type BundleCodec interface {
+	Encode(bundle *installer.Bundle, format installer.BundleFormat) ([]byte, error)
+	// Decode reads a bundle in any supported format.
+	Decode(data []byte) (*installer.Bundle, error)
+}
@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+// EndpointProber checks that a remote MCP server answers.
+type EndpointProber interface {
+	// Probe sends an MCP initialize request with the server's headers and
+	// returns the HTTP status code of the response.
+	Probe(ctx context.Context, server *installer.McpServer) (int, error)
+}
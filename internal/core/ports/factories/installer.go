@@ -9,4 +9,5 @@ import (
 
 type InstallerFactory interface {
 	GetInstaller(ctx context.Context, clientType installer.ClientType) (ports.Installer, error)
+	GetSupportedClients() []installer.ClientType
 }
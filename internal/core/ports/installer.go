@@ -8,24 +8,45 @@ import (
 
 type Installer interface {
 	GetConfigPath() (string, error)
+	GetBinaryPath() (string, error)
+	FileExists(path string) bool
 	LoadConfig(ctx context.Context) (interface{}, error)
+	// ParseConfig checks that data is a valid configuration file for the
+	// client, reporting syntax errors as *installer.ParseError.
+	ParseConfig(data []byte) error
+	// RepairConfig applies safe syntax fixes to data and describes them. The
+	// error tells whether the result still fails to parse.
+	RepairConfig(data []byte) ([]byte, []string, error)
 	AddMcpServer(ctx context.Context, config interface{}, server *installer.McpServer) (interface{}, error)
 	RemoveMcpServer(ctx context.Context, config interface{}) (interface{}, error)
 	SaveConfig(ctx context.Context, config interface{}) error
-	BackupConfig(ctx context.Context) (string, error)
-	RestoreConfig(ctx context.Context, backupPath string) error
+	DeleteConfig(ctx context.Context) error
 	ValidateConfig(ctx context.Context, config interface{}) error
 	IsClientRunning(ctx context.Context) (bool, error)
 	HasMcpServer(ctx context.Context, config interface{}) (bool, error)
 	GetMcpServerConfig(ctx context.Context, config interface{}) (*installer.McpServer, error)
+	ListMcpServers(ctx context.Context, config interface{}) ([]*installer.McpServer, error)
+	// ParseServers returns the MCP servers of configuration content data,
+	// such as a backup about to be restored.
+	ParseServers(ctx context.Context, data []byte) ([]*installer.McpServer, error)
+	// UnsupportedFields lists the settings of server the client cannot store.
+	UnsupportedFields(server *installer.McpServer) []string
 	FormatConfig(ctx context.Context, config interface{}) (string, error)
 	FormatSpecificServer(ctx context.Context, config interface{}) (string, error)
 }
 
+// ScopedInstaller is implemented by installers of clients that keep MCP
+// servers at more than the user scope.
+type ScopedInstaller interface {
+	Installer
+	// WithScope returns an installer for the servers kept at scope. project is
+	// the absolute path of the project directory the local and project scopes
+	// apply to.
+	WithScope(scope installer.Scope, project string) (Installer, error)
+}
+
 type ConfigManager interface {
 	ReadFile(path string) ([]byte, error)
 	WriteFile(path string, content []byte) error
 	FileExists(path string) bool
-	CreateBackup(path string) (string, error)
-	RestoreBackup(backupPath, targetPath string) error
 }
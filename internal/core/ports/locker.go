@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+type Unlocker interface {
+	Unlock() error
+}
+
+type Locker interface {
+	LockInstaller(ctx context.Context, timeout time.Duration) (Unlocker, error)
+	LockConfig(ctx context.Context, configPath string, timeout time.Duration) (Unlocker, error)
+}
@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+// PolicyProvider reads the organization policies every written server must
+// satisfy.
+type PolicyProvider interface {
+	// Load returns the system-wide policy first, then the user's. Missing
+	// policy files are skipped.
+	Load(ctx context.Context) ([]*installer.Policy, error)
+}
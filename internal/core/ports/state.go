@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+
+	"go.kirha.ai/mcp-installer/internal/core/domain/installer"
+)
+
+// StateStore remembers which MCP server entries the installer manages.
+// Entries are keyed by client, scope, configuration path and project.
+type StateStore interface {
+	// Get returns nil when the entry is not managed.
+	Get(ctx context.Context, key installer.ManagedKey) (*installer.ManagedServer, error)
+	List(ctx context.Context) ([]*installer.ManagedServer, error)
+	Put(ctx context.Context, server *installer.ManagedServer) error
+	Delete(ctx context.Context, key installer.ManagedKey) error
+}
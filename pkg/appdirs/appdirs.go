@@ -0,0 +1,122 @@
+// Package appdirs resolves where the installer keeps its own files.
+package appdirs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const (
+	AppName = "kirha-mcp-installer"
+
+	// EnvStateDir overrides the state directory, mainly for tests and CI images
+	EnvStateDir = "KIRHA_MCP_STATE_DIR"
+
+	// EnvConfigDir overrides the directory holding the installer's own settings
+	EnvConfigDir = "KIRHA_MCP_CONFIG_DIR"
+
+	envXDGStateHome  = "XDG_STATE_HOME"
+	envXDGConfigHome = "XDG_CONFIG_HOME"
+	envLocalAppData  = "LOCALAPPDATA"
+	envAppData       = "APPDATA"
+	envProgramData   = "ProgramData"
+)
+
+// StateDir returns the directory holding locks, backups and other installer
+// state. It is not created.
+func StateDir() (string, error) {
+	if dir := os.Getenv(EnvStateDir); dir != "" {
+		return dir, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(home, "Library", "Application Support", AppName), nil
+	case "windows":
+		localAppData := os.Getenv(envLocalAppData)
+		if localAppData == "" {
+			localAppData = filepath.Join(home, "AppData", "Local")
+		}
+		return filepath.Join(localAppData, AppName), nil
+	default:
+		stateHome := os.Getenv(envXDGStateHome)
+		if stateHome == "" {
+			stateHome = filepath.Join(home, ".local", "state")
+		}
+		return filepath.Join(stateHome, AppName), nil
+	}
+}
+
+// StatePath joins elem onto the state directory.
+func StatePath(elem ...string) (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{dir}, elem...)...), nil
+}
+
+// ConfigDir returns the directory holding the installer's settings. It is not
+// created.
+func ConfigDir() (string, error) {
+	if dir := os.Getenv(EnvConfigDir); dir != "" {
+		return dir, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(home, "Library", "Application Support", AppName), nil
+	case "windows":
+		appData := os.Getenv(envAppData)
+		if appData == "" {
+			appData = filepath.Join(home, "AppData", "Roaming")
+		}
+		return filepath.Join(appData, AppName), nil
+	default:
+		configHome := os.Getenv(envXDGConfigHome)
+		if configHome == "" {
+			configHome = filepath.Join(home, ".config")
+		}
+		return filepath.Join(configHome, AppName), nil
+	}
+}
+
+// ConfigPath joins elem onto the config directory.
+func ConfigPath(elem ...string) (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{dir}, elem...)...), nil
+}
+
+// SystemConfigDir returns the machine-wide directory holding settings managed
+// by administrators, such as the organization policy. It is not created, and
+// unlike the other directories it cannot be overridden, so a user cannot point
+// it elsewhere to turn the policy off.
+func SystemConfigDir() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join("/Library", "Application Support", AppName)
+	case "windows":
+		programData := os.Getenv(envProgramData)
+		if programData == "" {
+			programData = `C:\ProgramData`
+		}
+		return filepath.Join(programData, AppName)
+	default:
+		return filepath.Join("/etc", AppName)
+	}
+}
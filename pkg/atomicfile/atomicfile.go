@@ -0,0 +1,149 @@
+// Package atomicfile replaces files so that readers observe either the previous
+// content or the new content, never a partially written file.
+package atomicfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const maxSymlinkDepth = 40
+
+type Options struct {
+	// Perm is the mode used when the destination does not exist yet.
+	Perm os.FileMode
+	// BeforeRename, when set, runs once the new content is on disk and
+	// immediately before it replaces the destination. Returning an error
+	// aborts the write and leaves the destination untouched.
+	BeforeRename func() error
+}
+
+// WriteFile atomically replaces the file at path with data, creating it with
+// perm if it does not exist.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	return Write(path, data, Options{Perm: perm})
+}
+
+// Write atomically replaces the file at path with data.
+//
+// The data is written to a temporary file in the same directory, flushed to
+// disk and renamed over the destination. When path is a symlink the link target
+// is replaced and the link itself is left untouched. An existing file keeps its
+// permission bits and, where allowed, its owner; a new file is created with
+// opts.Perm.
+func Write(path string, data []byte, opts Options) error {
+	perm := opts.Perm
+
+	target, err := resolveSymlinks(path)
+	if err != nil {
+		return err
+	}
+
+	existing, err := os.Stat(target)
+	switch {
+	case err == nil:
+		perm = existing.Mode().Perm()
+	case os.IsNotExist(err):
+		existing = nil
+	default:
+		return err
+	}
+
+	dir := filepath.Dir(target)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(target)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tmp.Close()
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+
+	if err := tmp.Chmod(perm); err != nil {
+		return err
+	}
+
+	if existing != nil {
+		preserveOwner(tmp, existing)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if opts.BeforeRename != nil {
+		if err := opts.BeforeRename(); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Rename(tmpPath, target); err != nil {
+		return err
+	}
+	committed = true
+
+	return syncDir(dir)
+}
+
+// resolveSymlinks follows path through any chain of symlinks and returns the
+// file that should actually be replaced. Dangling links resolve to the path
+// they point at so the target gets created.
+func resolveSymlinks(path string) (string, error) {
+	current := path
+	for depth := 0; depth < maxSymlinkDepth; depth++ {
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return current, nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if info.Mode()&os.ModeSymlink == 0 {
+			return current, nil
+		}
+
+		link, err := os.Readlink(current)
+		if err != nil {
+			return "", err
+		}
+
+		if !filepath.IsAbs(link) {
+			link = filepath.Join(filepath.Dir(current), link)
+		}
+		current = link
+	}
+
+	return "", fmt.Errorf("too many levels of symbolic links: %s", path)
+}
+
+// syncDir flushes the directory entry so the rename survives a crash.
+// Directories cannot be synced on Windows, where the rename is already durable.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
@@ -0,0 +1,93 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWriteFile_CreatesNewFileWithPerm(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	if err := WriteFile(path, []byte(`{"a":1}`), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v, want nil", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(data) != `{"a":1}` {
+		t.Errorf("WriteFile() content = %s, want {\"a\":1}", data)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, _ := os.Stat(path)
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("WriteFile() mode = %v, want 0600", info.Mode().Perm())
+		}
+	}
+}
+
+func TestWriteFile_PreservesExistingMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not preserved on Windows")
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("old"), 0640); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.Chmod(path, 0640); err != nil {
+		t.Fatalf("Chmod() error = %v", err)
+	}
+
+	if err := WriteFile(path, []byte("new"), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v, want nil", err)
+	}
+
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("WriteFile() mode = %v, want 0640", info.Mode().Perm())
+	}
+}
+
+func TestWriteFile_ReplacesSymlinkTarget(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require elevated privileges on Windows")
+	}
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "config.json")
+	link := filepath.Join(dir, "config.json")
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(target, []byte("old"), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.Symlink(filepath.Join("dotfiles", "config.json"), link); err != nil {
+		t.Fatalf("Symlink() error = %v", err)
+	}
+
+	if err := WriteFile(link, []byte("new"), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v, want nil", err)
+	}
+
+	info, err := os.Lstat(link)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("WriteFile() replaced the symlink itself")
+	}
+
+	data, _ := os.ReadFile(target)
+	if string(data) != "new" {
+		t.Errorf("WriteFile() target content = %s, want new", data)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(target))
+	if len(entries) != 1 {
+		t.Errorf("WriteFile() left %d files in target dir, want 1", len(entries))
+	}
+}
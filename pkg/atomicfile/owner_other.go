@@ -0,0 +1,7 @@
+//go:build !unix
+
+package atomicfile
+
+import "os"
+
+func preserveOwner(tmp *os.File, existing os.FileInfo) {}
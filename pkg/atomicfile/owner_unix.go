@@ -0,0 +1,39 @@
+//go:build unix
+
+package atomicfile
+
+import (
+	"log/slog"
+	"os"
+	"syscall"
+)
+
+// preserveOwner gives the temporary file the same owner and group as the file
+// it replaces, so that running as another user (e.g. via sudo) does not change
+// who owns the configuration. Only root can give a file away, so a user
+// rewriting a file they can write but do not own ends up owning the new one.
+func preserveOwner(tmp *os.File, existing os.FileInfo) {
+	want, ok := existing.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+
+	info, err := tmp.Stat()
+	if err != nil {
+		slog.Warn("failed to preserve file owner", slog.String("path", tmp.Name()), slog.String("error", err.Error()))
+		return
+	}
+
+	have, ok := info.Sys().(*syscall.Stat_t)
+	if ok && have.Uid == want.Uid && have.Gid == want.Gid {
+		return
+	}
+
+	if err := tmp.Chown(int(want.Uid), int(want.Gid)); err != nil {
+		slog.Warn("failed to preserve file owner, the file will be owned by the current user",
+			slog.String("path", tmp.Name()),
+			slog.Int("uid", int(want.Uid)),
+			slog.Int("gid", int(want.Gid)),
+			slog.String("error", err.Error()))
+	}
+}
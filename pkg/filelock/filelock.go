@@ -0,0 +1,128 @@
+// Package filelock provides advisory, cross-process exclusive locks backed by
+// lock files. The holder's PID is written into the lock file so that waiting
+// processes can report who holds it.
+package filelock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const pollInterval = 50 * time.Millisecond
+
+var (
+	ErrLocked = errors.New("lock is held by another process")
+
+	errWouldBlock = errors.New("lock would block")
+)
+
+// HeldError reports a lock that could not be acquired before the timeout.
+// PID is zero when the holder could not be determined.
+type HeldError struct {
+	Path string
+	PID  int
+}
+
+func (e *HeldError) Error() string {
+	if e.PID == 0 {
+		return fmt.Sprintf("%s is locked by another process", e.Path)
+	}
+	return fmt.Sprintf("%s is locked by process %d", e.Path, e.PID)
+}
+
+func (e *HeldError) Is(target error) bool {
+	return target == ErrLocked
+}
+
+type Lock struct {
+	file *os.File
+}
+
+// Acquire takes an exclusive lock on path, creating the file if needed. It
+// polls until the lock is free, the timeout elapses or ctx is cancelled.
+func Acquire(ctx context.Context, path string, timeout time.Duration) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := tryLock(file)
+		if err == nil {
+			break
+		}
+
+		if !errors.Is(err, errWouldBlock) {
+			file.Close()
+			return nil, err
+		}
+
+		if !time.Now().Before(deadline) {
+			file.Close()
+			return nil, &HeldError{Path: path, PID: readPID(path)}
+		}
+
+		select {
+		case <-ctx.Done():
+			file.Close()
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+
+	if err := writePID(file); err != nil {
+		_ = unlock(file)
+		file.Close()
+		return nil, err
+	}
+
+	return &Lock{file: file}, nil
+}
+
+func (l *Lock) Unlock() error {
+	_ = l.file.Truncate(0)
+
+	if err := unlock(l.file); err != nil {
+		l.file.Close()
+		return err
+	}
+
+	return l.file.Close()
+}
+
+func writePID(file *os.File) error {
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+
+	if _, err := file.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0); err != nil {
+		return err
+	}
+
+	return file.Sync()
+}
+
+func readPID(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0
+	}
+
+	return pid
+}
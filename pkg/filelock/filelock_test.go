@@ -0,0 +1,40 @@
+package filelock
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquire_ReportsHolderPID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.lock")
+	ctx := context.Background()
+
+	lock, err := Acquire(ctx, path, time.Second)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v, want nil", err)
+	}
+
+	_, err = Acquire(ctx, path, 100*time.Millisecond)
+	if !errors.Is(err, ErrLocked) {
+		t.Fatalf("Acquire() error = %v, want %v", err, ErrLocked)
+	}
+
+	var held *HeldError
+	if !errors.As(err, &held) || held.PID != os.Getpid() {
+		t.Errorf("Acquire() holder = %+v, want PID %d", held, os.Getpid())
+	}
+
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Unlock() error = %v, want nil", err)
+	}
+
+	lock, err = Acquire(ctx, path, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Acquire() after unlock error = %v, want nil", err)
+	}
+	_ = lock.Unlock()
+}
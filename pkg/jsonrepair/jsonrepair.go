@@ -0,0 +1,321 @@
+// Package jsonrepair fixes the JSON mistakes that are safe to fix without
+// guessing what the author meant: a leading byte order mark, trailing commas
+// and duplicate object keys. The rest of the text is left as it is.
+package jsonrepair
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+type Kind string
+
+const (
+	KindBOM           Kind = "bom"
+	KindTrailingComma Kind = "trailing_comma"
+	KindDuplicateKey  Kind = "duplicate_key"
+)
+
+// Fix is one repair, located in the text it was applied to.
+type Fix struct {
+	Kind   Kind
+	Line   int
+	Column int
+	Detail string
+}
+
+func (f Fix) String() string {
+	return fmt.Sprintf("line %d, column %d: %s", f.Line, f.Column, f.Detail)
+}
+
+var bom = []byte{0xEF, 0xBB, 0xBF}
+
+// Repair returns data with the safe fixes applied, and the fixes. Duplicate
+// keys keep their last value, as most JSON parsers do. Duplicates are only
+// removed when the rest of the document is well-formed.
+func Repair(data []byte) ([]byte, []Fix) {
+	var fixes []Fix
+
+	if bytes.HasPrefix(data, bom) {
+		data = data[len(bom):]
+		fixes = append(fixes, Fix{Kind: KindBOM, Line: 1, Column: 1, Detail: "removed byte order mark"})
+	}
+
+	data, commaFixes := removeTrailingCommas(data)
+	fixes = append(fixes, commaFixes...)
+
+	data, keyFixes := removeDuplicateKeys(data)
+	fixes = append(fixes, keyFixes...)
+
+	return data, fixes
+}
+
+// Position converts a byte offset of data to a 1-based line and column.
+func Position(data []byte, offset int) (int, int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	line := 1 + bytes.Count(data[:offset], []byte{'\n'})
+	column := offset - bytes.LastIndexByte(data[:offset], '\n')
+	return line, column
+}
+
+func removeTrailingCommas(data []byte) ([]byte, []Fix) {
+	var fixes []Fix
+	out := make([]byte, 0, len(data))
+
+	inString, escaped := false, false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			out = append(out, c)
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case ',':
+			next := skipSpace(data, i+1)
+			if next < len(data) && (data[next] == '}' || data[next] == ']') {
+				line, column := Position(data, i)
+				fixes = append(fixes, Fix{Kind: KindTrailingComma, Line: line, Column: column, Detail: "removed trailing comma"})
+				continue
+			}
+		}
+		out = append(out, c)
+	}
+
+	return out, fixes
+}
+
+type span struct {
+	start, end int
+}
+
+func removeDuplicateKeys(data []byte) ([]byte, []Fix) {
+	p := &parser{data: data}
+	if err := p.document(); err != nil || len(p.removals) == 0 {
+		return data, nil
+	}
+
+	// A removed member may contain removed duplicates of its own; removing
+	// the outer one is enough.
+	sort.Slice(p.removals, func(i, j int) bool { return p.removals[i].start < p.removals[j].start })
+	var kept []span
+	for _, removal := range p.removals {
+		if len(kept) > 0 && removal.start < kept[len(kept)-1].end {
+			continue
+		}
+		kept = append(kept, removal)
+	}
+
+	var fixes []Fix
+	out := make([]byte, 0, len(data))
+	last := 0
+	for _, removal := range kept {
+		out = append(out, data[last:removal.start]...)
+		last = removal.end
+		fixes = append(fixes, p.fixes[removal.start])
+	}
+	out = append(out, data[last:]...)
+
+	return out, fixes
+}
+
+// parser walks a JSON document recording the members that repeat a key of
+// their object.
+type parser struct {
+	data     []byte
+	pos      int
+	removals []span
+	fixes    map[int]Fix
+}
+
+func (p *parser) document() error {
+	if err := p.value(); err != nil {
+		return err
+	}
+	if p.pos = skipSpace(p.data, p.pos); p.pos != len(p.data) {
+		return p.errorf("unexpected data after the document")
+	}
+	return nil
+}
+
+func (p *parser) value() error {
+	p.pos = skipSpace(p.data, p.pos)
+	if p.pos >= len(p.data) {
+		return p.errorf("unexpected end of input")
+	}
+
+	switch p.data[p.pos] {
+	case '{':
+		return p.object()
+	case '[':
+		return p.array()
+	case '"':
+		_, err := p.string()
+		return err
+	default:
+		return p.literal()
+	}
+}
+
+func (p *parser) object() error {
+	type member struct {
+		key   string
+		start int
+		next  int
+	}
+
+	p.pos++
+	p.pos = skipSpace(p.data, p.pos)
+	if p.pos < len(p.data) && p.data[p.pos] == '}' {
+		p.pos++
+		return nil
+	}
+
+	var members []member
+	for {
+		p.pos = skipSpace(p.data, p.pos)
+		start := p.pos
+		key, err := p.string()
+		if err != nil {
+			return err
+		}
+		if p.pos = skipSpace(p.data, p.pos); p.pos >= len(p.data) || p.data[p.pos] != ':' {
+			return p.errorf("expected ':' after object key")
+		}
+		p.pos++
+		if err := p.value(); err != nil {
+			return err
+		}
+		members = append(members, member{key: key, start: start})
+
+		p.pos = skipSpace(p.data, p.pos)
+		if p.pos >= len(p.data) {
+			return p.errorf("unexpected end of input in object")
+		}
+		if p.data[p.pos] == ',' {
+			p.pos = skipSpace(p.data, p.pos+1)
+			members[len(members)-1].next = p.pos
+			continue
+		}
+		if p.data[p.pos] == '}' {
+			p.pos++
+			break
+		}
+		return p.errorf("expected ',' or '}' in object")
+	}
+
+	lastIndex := make(map[string]int, len(members))
+	for i, m := range members {
+		lastIndex[m.key] = i
+	}
+	for i, m := range members {
+		if lastIndex[m.key] == i {
+			continue
+		}
+		line, column := Position(p.data, m.start)
+		if p.fixes == nil {
+			p.fixes = make(map[int]Fix)
+		}
+		p.fixes[m.start] = Fix{Kind: KindDuplicateKey, Line: line, Column: column, Detail: fmt.Sprintf("removed duplicate key %q, a later one is kept", m.key)}
+		p.removals = append(p.removals, span{start: m.start, end: m.next})
+	}
+
+	return nil
+}
+
+func (p *parser) array() error {
+	p.pos++
+	p.pos = skipSpace(p.data, p.pos)
+	if p.pos < len(p.data) && p.data[p.pos] == ']' {
+		p.pos++
+		return nil
+	}
+
+	for {
+		if err := p.value(); err != nil {
+			return err
+		}
+		p.pos = skipSpace(p.data, p.pos)
+		if p.pos >= len(p.data) {
+			return p.errorf("unexpected end of input in array")
+		}
+		switch p.data[p.pos] {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return nil
+		default:
+			return p.errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+// string reads a string token and returns its decoded value.
+func (p *parser) string() (string, error) {
+	if p.pos >= len(p.data) || p.data[p.pos] != '"' {
+		return "", p.errorf("expected string")
+	}
+
+	start := p.pos
+	for i := p.pos + 1; i < len(p.data); i++ {
+		switch p.data[i] {
+		case '\\':
+			i++
+		case '"':
+			p.pos = i + 1
+			var value string
+			if err := json.Unmarshal(p.data[start:p.pos], &value); err != nil {
+				return "", p.errorf("invalid string")
+			}
+			return value, nil
+		}
+	}
+
+	return "", p.errorf("unterminated string")
+}
+
+func (p *parser) literal() error {
+	start := p.pos
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if c == ',' || c == '}' || c == ']' || isSpace(c) {
+			break
+		}
+		p.pos++
+	}
+	if !json.Valid(p.data[start:p.pos]) {
+		return p.errorf("invalid value")
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	line, column := Position(p.data, p.pos)
+	return fmt.Errorf("line %d, column %d: %s", line, column, fmt.Sprintf(format, args...))
+}
+
+func skipSpace(data []byte, pos int) int {
+	for pos < len(data) && isSpace(data[pos]) {
+		pos++
+	}
+	return pos
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
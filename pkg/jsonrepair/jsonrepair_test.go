@@ -0,0 +1,84 @@
+package jsonrepair
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRepair(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		kinds []Kind
+	}{
+		{
+			name:  "valid document is untouched",
+			input: "{\n  \"a\": [1, 2],\n  \"b\": \"x,}\"\n}\n",
+			want:  "{\n  \"a\": [1, 2],\n  \"b\": \"x,}\"\n}\n",
+		},
+		{
+			name:  "byte order mark",
+			input: "\xEF\xBB\xBF{\"a\": 1}",
+			want:  "{\"a\": 1}",
+			kinds: []Kind{KindBOM},
+		},
+		{
+			name:  "trailing commas",
+			input: "{\n  \"a\": [1, 2,],\n  \"b\": {\"c\": true,\n  },\n}",
+			want:  "{\n  \"a\": [1, 2],\n  \"b\": {\"c\": true\n  }\n}",
+			kinds: []Kind{KindTrailingComma, KindTrailingComma, KindTrailingComma},
+		},
+		{
+			name:  "duplicate keys keep the last value",
+			input: "{\n  \"mcpServers\": {\n    \"kirha\": {\"url\": \"old\"},\n    \"kirha\": {\"url\": \"new\"}\n  }\n}",
+			want:  "{\n  \"mcpServers\": {\n    \"kirha\": {\"url\": \"new\"}\n  }\n}",
+			kinds: []Kind{KindDuplicateKey},
+		},
+		{
+			name:  "nested duplicates inside a removed member",
+			input: `{"a": {"x": 1, "x": 2}, "a": 3}`,
+			want:  `{"a": 3}`,
+			kinds: []Kind{KindDuplicateKey},
+		},
+		{
+			name:  "escaped keys are compared decoded",
+			input: `{"\u006b": 1, "k": 2}`,
+			want:  `{"k": 2}`,
+			kinds: []Kind{KindDuplicateKey},
+		},
+		{
+			name:  "truncated document is left alone",
+			input: `{"a": 1, "a": 2`,
+			want:  `{"a": 1, "a": 2`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, fixes := Repair([]byte(tt.input))
+			if string(got) != tt.want {
+				t.Errorf("Repair() = %q, want %q", got, tt.want)
+			}
+			if len(fixes) != len(tt.kinds) {
+				t.Fatalf("Repair() fixes = %v, want kinds %v", fixes, tt.kinds)
+			}
+			for i, fix := range fixes {
+				if fix.Kind != tt.kinds[i] {
+					t.Errorf("fix %d kind = %s, want %s", i, fix.Kind, tt.kinds[i])
+				}
+			}
+			if len(fixes) > 0 && !json.Valid(got) {
+				t.Errorf("Repair() result is not valid JSON: %s", got)
+			}
+		})
+	}
+}
+
+func TestPosition(t *testing.T) {
+	data := []byte("{\n  \"a\": 1,\n}")
+	line, column := Position(data, 10)
+	if line != 2 || column != 9 {
+		t.Errorf("Position() = %d:%d, want 2:9", line, column)
+	}
+}
@@ -0,0 +1,363 @@
+// Package jsonschema validates decoded documents against the subset of JSON
+// Schema needed to describe client configurations: type, properties,
+// required, additionalProperties, items, enum, pattern, minLength, minItems
+// and anyOf. Other keywords are ignored.
+//
+// Documents are the generic values produced by encoding/json (with or without
+// UseNumber) or by a TOML decoder.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Schema struct {
+	Type                 types              `json:"type"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *Schema            `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	Enum                 []interface{}      `json:"enum"`
+	Pattern              string             `json:"pattern"`
+	MinLength            *int               `json:"minLength"`
+	MinItems             *int               `json:"minItems"`
+	AnyOf                []*Schema          `json:"anyOf"`
+
+	// never is set for the boolean schema false, which rejects any value.
+	never   bool
+	pattern *regexp.Regexp
+}
+
+// Violation is a value that does not satisfy its schema. Path is a JSONPath
+// expression such as $.mcpServers.kirha.url.
+type Violation struct {
+	Path    string
+	Message string
+}
+
+func (v Violation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+// Compile parses a schema and its patterns.
+func Compile(data []byte) (*Schema, error) {
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	if err := schema.compile(); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// MustCompile is Compile for schemas embedded in the binary.
+func MustCompile(data []byte) *Schema {
+	schema, err := Compile(data)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	switch string(bytes.TrimSpace(data)) {
+	case "true":
+		*s = Schema{}
+		return nil
+	case "false":
+		*s = Schema{never: true}
+		return nil
+	}
+
+	type plain Schema
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+func (s *Schema) compile() error {
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid schema pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = pattern
+	}
+
+	children := append([]*Schema{s.AdditionalProperties, s.Items}, s.AnyOf...)
+	for _, property := range s.Properties {
+		children = append(children, property)
+	}
+	for _, child := range children {
+		if child == nil {
+			continue
+		}
+		if err := child.compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate returns the violations found in value, sorted by path.
+func (s *Schema) Validate(value interface{}) []Violation {
+	var violations []Violation
+	s.validate("$", value, &violations)
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].Path < violations[j].Path })
+	return violations
+}
+
+func (s *Schema) validate(path string, value interface{}, violations *[]Violation) {
+	report := func(format string, args ...interface{}) {
+		*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.never {
+		report("is not allowed")
+		return
+	}
+
+	if len(s.Type) > 0 && !s.Type.matches(value) {
+		report("must be %s, got %s", s.Type, typeOf(value))
+		return
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		report("must be one of %s", formatEnum(s.Enum))
+	}
+
+	switch v := value.(type) {
+	case string:
+		if s.MinLength != nil && len([]rune(v)) < *s.MinLength {
+			if *s.MinLength == 1 {
+				report("must not be empty")
+			} else {
+				report("must be at least %d characters long", *s.MinLength)
+			}
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			report("must match %s", s.Pattern)
+		}
+	case map[string]interface{}:
+		s.validateObject(path, v, violations)
+	default:
+		if items, ok := asArray(value); ok {
+			if s.MinItems != nil && len(items) < *s.MinItems {
+				report("must have at least %d item(s)", *s.MinItems)
+			}
+			if s.Items != nil {
+				for idx, item := range items {
+					s.Items.validate(fmt.Sprintf("%s[%d]", path, idx), item, violations)
+				}
+			}
+		}
+	}
+
+	if len(s.AnyOf) > 0 {
+		var alternatives []string
+		for _, alternative := range s.AnyOf {
+			var found []Violation
+			alternative.validate(path, value, &found)
+			if len(found) == 0 {
+				alternatives = nil
+				break
+			}
+			alternatives = append(alternatives, describe(path, found))
+		}
+		if len(alternatives) > 0 {
+			report("%s", strings.Join(alternatives, ", or "))
+		}
+	}
+}
+
+func (s *Schema) validateObject(path string, object map[string]interface{}, violations *[]Violation) {
+	for _, name := range s.Required {
+		if _, ok := object[name]; !ok {
+			*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf("missing required property %q", name)})
+		}
+	}
+
+	for name, value := range object {
+		childPath := path + pathKey(name)
+		if property, ok := s.Properties[name]; ok {
+			property.validate(childPath, value, violations)
+		} else if s.AdditionalProperties != nil {
+			s.AdditionalProperties.validate(childPath, value, violations)
+		}
+	}
+}
+
+// describe summarizes the violations of an anyOf alternative, relative to the
+// value it applied to.
+func describe(path string, violations []Violation) string {
+	parts := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		if violation.Path == path {
+			parts = append(parts, violation.Message)
+		} else {
+			parts = append(parts, strings.TrimPrefix(violation.Path, path)+" "+violation.Message)
+		}
+	}
+	return strings.Join(parts, " and ")
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func pathKey(name string) string {
+	if identifier.MatchString(name) {
+		return "." + name
+	}
+	return "[" + strconv.Quote(name) + "]"
+}
+
+type types []string
+
+func (t *types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = types{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("type must be a string or a list of strings")
+	}
+	*t = list
+	return nil
+}
+
+func (t types) String() string {
+	if len(t) == 1 {
+		return article(t[0])
+	}
+	names := make([]string, len(t))
+	for i, name := range t {
+		names[i] = article(name)
+	}
+	return strings.Join(names, " or ")
+}
+
+func (t types) matches(value interface{}) bool {
+	actual := typeOf(value)
+	for _, name := range t {
+		if name == actual || (name == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func article(name string) string {
+	switch name {
+	case "object", "array", "integer":
+		return "an " + name
+	case "null":
+		return name
+	default:
+		return "a " + name
+	}
+}
+
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return "integer"
+	case float32, float64:
+		f, _ := toFloat(v)
+		if f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+	if _, ok := asArray(value); ok {
+		return "array"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// asArray accepts the array types decoders produce, including the slices of
+// tables a TOML decoder returns for arrays of tables.
+func asArray(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, true
+	case []map[string]interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return items, true
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return items, true
+	}
+	return nil, false
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if equal(allowed, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func equal(a, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	return a == b
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func formatEnum(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, value := range enum {
+		encoded, _ := json.Marshal(value)
+		values[i] = string(encoded)
+	}
+	return strings.Join(values, ", ")
+}
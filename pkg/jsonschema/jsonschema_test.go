@@ -0,0 +1,130 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const serversSchema = `{
+  "type": "object",
+  "properties": {
+    "mcpServers": {
+      "type": "object",
+      "additionalProperties": {
+        "type": "object",
+        "properties": {
+          "type": {"enum": ["stdio", "http", "sse"]},
+          "url": {"type": "string", "pattern": "^https?://"},
+          "headers": {"type": "object", "additionalProperties": {"type": "string"}},
+          "command": {"type": "string", "minLength": 1},
+          "args": {"type": "array", "items": {"type": "string"}}
+        },
+        "anyOf": [{"required": ["url"]}, {"required": ["command"]}]
+      }
+    },
+    "version": {"type": "integer"}
+  },
+  "additionalProperties": true
+}`
+
+func decode(t *testing.T, text string) interface{} {
+	t.Helper()
+	decoder := json.NewDecoder(bytes.NewReader([]byte(text)))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestSchema_Validate(t *testing.T) {
+	schema := MustCompile([]byte(serversSchema))
+
+	tests := []struct {
+		name string
+		doc  string
+		want []Violation
+	}{
+		{
+			name: "valid",
+			doc:  `{"mcpServers": {"kirha": {"type": "http", "url": "https://mcp.kirha.com", "headers": {"Authorization": "Bearer x"}}}, "version": 2, "other": [1]}`,
+		},
+		{
+			name: "wrong types and values",
+			doc:  `{"mcpServers": {"kirha": {"type": "websocket", "url": "mcp.kirha.com", "headers": {"X-Retries": 3}}}, "version": 1.5}`,
+			want: []Violation{
+				{Path: `$.mcpServers.kirha.headers["X-Retries"]`, Message: "must be a string, got integer"},
+				{Path: "$.mcpServers.kirha.type", Message: `must be one of "stdio", "http", "sse"`},
+				{Path: "$.mcpServers.kirha.url", Message: "must match ^https?://"},
+				{Path: "$.version", Message: "must be an integer, got number"},
+			},
+		},
+		{
+			name: "no alternative matches",
+			doc:  `{"mcpServers": {"my server": {"args": ["x"]}}}`,
+			want: []Violation{
+				{Path: `$.mcpServers["my server"]`, Message: `missing required property "url", or missing required property "command"`},
+			},
+		},
+		{
+			name: "array items",
+			doc:  `{"mcpServers": {"s": {"command": "", "args": ["a", 1]}}}`,
+			want: []Violation{
+				{Path: "$.mcpServers.s.args[1]", Message: "must be a string, got integer"},
+				{Path: "$.mcpServers.s.command", Message: "must not be empty"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := schema.Validate(decode(t, tt.doc))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchema_ValidateTOMLValues(t *testing.T) {
+	schema := MustCompile([]byte(`{
+      "type": "object",
+      "properties": {
+        "servers": {"type": "array", "items": {"type": "object", "required": ["name"]}},
+        "timeout": {"type": "integer"},
+        "enabled": {"type": "boolean"}
+      }
+    }`))
+
+	doc := map[string]interface{}{
+		"servers": []map[string]interface{}{{"name": "a"}, {}},
+		"timeout": int64(30),
+		"enabled": "yes",
+	}
+
+	want := []Violation{
+		{Path: "$.enabled", Message: "must be a boolean, got string"},
+		{Path: "$.servers[1]", Message: `missing required property "name"`},
+	}
+	if got := schema.Validate(doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() = %v, want %v", got, want)
+	}
+}
+
+func TestCompile_FalseSchema(t *testing.T) {
+	schema := MustCompile([]byte(`{"type": "object", "additionalProperties": false, "properties": {"a": true}}`))
+
+	want := []Violation{{Path: "$.b", Message: "is not allowed"}}
+	if got := schema.Validate(decode(t, `{"a": 1, "b": 2}`)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() = %v, want %v", got, want)
+	}
+}
+
+func TestCompile_InvalidPattern(t *testing.T) {
+	if _, err := Compile([]byte(`{"pattern": "("}`)); err == nil {
+		t.Error("Compile() accepted an invalid pattern")
+	}
+}
@@ -0,0 +1,450 @@
+// Package jsonspan locates and replaces values in a JSON document without
+// decoding the rest of it. Values are skipped by scanning for their closing
+// quote or bracket, so editing one member of a large document costs about as
+// much as a memory scan, and every other byte stays as it was.
+//
+// The scanner checks the structure it walks through, strings and brackets,
+// but not the literals in between; decode the document when full validation
+// is needed.
+package jsonspan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Span is the value data[Start:End].
+type Span struct {
+	Start, End int
+}
+
+// Member is a member of an object. KeyStart is the offset of the opening
+// quote of its key.
+type Member struct {
+	Key      string
+	KeyStart int
+	Value    Span
+}
+
+// SyntaxError reports where the scanner found the document malformed.
+type SyntaxError struct {
+	Offset int
+	msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at offset %d", e.msg, e.Offset)
+}
+
+const defaultIndent = "  "
+
+// Find returns the span of the value at path in the object document data.
+// When a key appears more than once the last one wins, as when decoding.
+func Find(data []byte, path ...string) (Span, bool, error) {
+	start, err := rootObject(data)
+	if err != nil {
+		return Span{}, false, err
+	}
+
+	span := Span{Start: start}
+	for _, key := range path {
+		if data[span.Start] != '{' {
+			return Span{}, false, nil
+		}
+		members, _, err := Object(data, span.Start)
+		if err != nil {
+			return Span{}, false, err
+		}
+		member, ok := lastMember(members, key)
+		if !ok {
+			return Span{}, false, nil
+		}
+		span = member.Value
+	}
+
+	if len(path) == 0 {
+		_, end, err := Object(data, start)
+		if err != nil {
+			return Span{}, false, err
+		}
+		span.End = end
+	}
+	return span, true, nil
+}
+
+// Object lists the members of the object at data[start] and returns the
+// offset just past its closing brace.
+func Object(data []byte, start int) ([]Member, int, error) {
+	if start >= len(data) || data[start] != '{' {
+		return nil, 0, syntaxError(start, "expected object")
+	}
+
+	var members []Member
+	i := skipSpace(data, start+1)
+	if i < len(data) && data[i] == '}' {
+		return members, i + 1, nil
+	}
+
+	for {
+		if i >= len(data) || data[i] != '"' {
+			return nil, 0, syntaxError(i, "expected object key")
+		}
+		keyEnd, err := skipString(data, i)
+		if err != nil {
+			return nil, 0, err
+		}
+		key, err := decodeKey(data[i:keyEnd])
+		if err != nil {
+			return nil, 0, syntaxError(i, "invalid object key")
+		}
+
+		colon := skipSpace(data, keyEnd)
+		if colon >= len(data) || data[colon] != ':' {
+			return nil, 0, syntaxError(colon, "expected ':' after object key")
+		}
+		valueStart := skipSpace(data, colon+1)
+		valueEnd, err := skipValue(data, valueStart)
+		if err != nil {
+			return nil, 0, err
+		}
+		members = append(members, Member{Key: key, KeyStart: i, Value: Span{Start: valueStart, End: valueEnd}})
+
+		i = skipSpace(data, valueEnd)
+		if i >= len(data) {
+			return nil, 0, syntaxError(i, "unexpected end of object")
+		}
+		switch data[i] {
+		case ',':
+			i = skipSpace(data, i+1)
+		case '}':
+			return members, i + 1, nil
+		default:
+			return nil, 0, syntaxError(i, "expected ',' or '}' in object")
+		}
+	}
+}
+
+// Replace returns data with the value at path set to value, which must be
+// valid JSON. Missing members, and the objects leading to them, are added.
+// The value is indented to match the surrounding document.
+func Replace(data []byte, path []string, value []byte) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	value = bytes.TrimSpace(value)
+
+	start, err := rootObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var style style
+	object := start
+	for depth, key := range path {
+		members, end, err := Object(data, object)
+		if err != nil {
+			return nil, err
+		}
+		if depth == 0 {
+			style = detectStyle(data, start, members)
+		}
+
+		member, ok := lastMember(members, key)
+		if ok && (depth == len(path)-1 || data[member.Value.Start] == '{') {
+			if depth < len(path)-1 {
+				object = member.Value.Start
+				continue
+			}
+			formatted, err := style.format(value, lineIndent(data, member.KeyStart))
+			if err != nil {
+				return nil, err
+			}
+			return splice(data, member.Value, formatted), nil
+		}
+
+		// Build what is missing from here down, replacing a member that is
+		// in the way because it is not an object.
+		nested, err := nest(path[depth+1:], value)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			formatted, err := style.format(nested, lineIndent(data, member.KeyStart))
+			if err != nil {
+				return nil, err
+			}
+			return splice(data, member.Value, formatted), nil
+		}
+		return insert(data, object, end, members, key, nested, style)
+	}
+
+	return nil, fmt.Errorf("unreachable")
+}
+
+func insert(data []byte, object, end int, members []Member, key string, value []byte, style style) ([]byte, error) {
+	encodedKey, err := marshal(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(members) > 0 {
+		last := members[len(members)-1]
+		separator := data[lastSeparator(data, last.KeyStart):last.KeyStart]
+		formatted, err := style.format(value, lineIndent(data, last.KeyStart))
+		if err != nil {
+			return nil, err
+		}
+
+		var member bytes.Buffer
+		member.WriteByte(',')
+		member.Write(separator)
+		member.Write(encodedKey)
+		member.WriteString(style.colon())
+		member.Write(formatted)
+		return splice(data, Span{Start: last.Value.End, End: last.Value.End}, member.Bytes()), nil
+	}
+
+	var member bytes.Buffer
+	if style.compact {
+		member.WriteByte('{')
+		member.Write(encodedKey)
+		member.WriteString(style.colon())
+		member.Write(value)
+		member.WriteByte('}')
+	} else {
+		outer := lineIndent(data, object)
+		inner := outer + style.indent
+		formatted, err := style.format(value, inner)
+		if err != nil {
+			return nil, err
+		}
+		member.WriteString("{\n" + inner)
+		member.Write(encodedKey)
+		member.WriteString(style.colon())
+		member.Write(formatted)
+		member.WriteString("\n" + outer + "}")
+	}
+	return splice(data, Span{Start: object, End: end}, member.Bytes()), nil
+}
+
+// style is the formatting of the document, taken from its root object: either
+// everything on one line, or one member per line indented by indent.
+type style struct {
+	compact bool
+	indent  string
+	space   bool
+}
+
+func detectStyle(data []byte, root int, members []Member) style {
+	if len(members) == 0 {
+		return style{indent: defaultIndent, space: true}
+	}
+
+	first := members[0]
+	between := data[root+1 : first.KeyStart]
+	newline := bytes.LastIndexByte(between, '\n')
+	colon := bytes.IndexByte(data[first.KeyStart:first.Value.Start], ':') + first.KeyStart
+	space := colon+1 < first.Value.Start
+
+	if newline < 0 {
+		return style{compact: true, space: space}
+	}
+	indent := string(between[newline+1:])
+	if indent == "" {
+		indent = defaultIndent
+	}
+	return style{indent: indent, space: space}
+}
+
+func (s style) colon() string {
+	if s.space {
+		return ": "
+	}
+	return ":"
+}
+
+// format lays out value for a member whose line is indented by prefix.
+func (s style) format(value []byte, prefix string) ([]byte, error) {
+	var buf bytes.Buffer
+	if s.compact {
+		if err := json.Compact(&buf, value); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	if err := json.Indent(&buf, value, prefix, s.indent); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func nest(path []string, value []byte) ([]byte, error) {
+	for i := len(path) - 1; i >= 0; i-- {
+		key, err := marshal(path[i])
+		if err != nil {
+			return nil, err
+		}
+		value = append(append(append(append([]byte{'{'}, key...), ':'), value...), '}')
+	}
+	return value, nil
+}
+
+func marshal(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+func splice(data []byte, span Span, value []byte) []byte {
+	out := make([]byte, 0, len(data)-(span.End-span.Start)+len(value))
+	out = append(out, data[:span.Start]...)
+	out = append(out, value...)
+	return append(out, data[span.End:]...)
+}
+
+// lineIndent returns the whitespace that starts the line holding offset, or
+// "" when something else precedes offset on that line.
+func lineIndent(data []byte, offset int) string {
+	lineStart := bytes.LastIndexByte(data[:offset], '\n') + 1
+	for i := lineStart; i < offset; i++ {
+		if data[i] != ' ' && data[i] != '\t' {
+			return ""
+		}
+	}
+	return string(data[lineStart:offset])
+}
+
+// lastSeparator returns where the whitespace before the key at keyStart
+// begins, just past the preceding ',' or '{'.
+func lastSeparator(data []byte, keyStart int) int {
+	i := keyStart
+	for i > 0 && isSpace(data[i-1]) {
+		i--
+	}
+	return i
+}
+
+func lastMember(members []Member, key string) (Member, bool) {
+	for i := len(members) - 1; i >= 0; i-- {
+		if members[i].Key == key {
+			return members[i], true
+		}
+	}
+	return Member{}, false
+}
+
+func rootObject(data []byte) (int, error) {
+	start := skipSpace(data, 0)
+	if start >= len(data) || data[start] != '{' {
+		return 0, syntaxError(start, "document is not an object")
+	}
+	return start, nil
+}
+
+func decodeKey(raw []byte) (string, error) {
+	if bytes.IndexByte(raw, '\\') < 0 {
+		return string(raw[1 : len(raw)-1]), nil
+	}
+	var key string
+	err := json.Unmarshal(raw, &key)
+	return key, err
+}
+
+func skipValue(data []byte, i int) (int, error) {
+	if i >= len(data) {
+		return 0, syntaxError(i, "unexpected end of input")
+	}
+
+	switch data[i] {
+	case '"':
+		return skipString(data, i)
+	case '{', '[':
+		return skipNested(data, i)
+	}
+
+	start := i
+	for i < len(data) && !isDelimiter[data[i]] {
+		i++
+	}
+	if i == start {
+		return 0, syntaxError(i, "expected value")
+	}
+	return i, nil
+}
+
+// skipString returns the offset just past the string starting at data[i].
+func skipString(data []byte, i int) (int, error) {
+	j := i + 1
+	for {
+		k := bytes.IndexByte(data[j:], '"')
+		if k < 0 {
+			return 0, syntaxError(i, "unterminated string")
+		}
+		j += k
+
+		backslashes := 0
+		for p := j - 1; p > i && data[p] == '\\'; p-- {
+			backslashes++
+		}
+		if backslashes%2 == 0 {
+			return j + 1, nil
+		}
+		j++
+	}
+}
+
+// skipNested returns the offset just past the object or array starting at
+// data[i], checking that brackets match.
+func skipNested(data []byte, i int) (int, error) {
+	stack := []byte{data[i]}
+	for j := i + 1; j < len(data); j++ {
+		switch c := data[j]; c {
+		case '"':
+			end, err := skipString(data, j)
+			if err != nil {
+				return 0, err
+			}
+			j = end - 1
+		case '{', '[':
+			stack = append(stack, c)
+		case '}', ']':
+			open := stack[len(stack)-1]
+			if (c == '}') != (open == '{') {
+				return 0, syntaxError(j, fmt.Sprintf("unexpected '%c'", c))
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return j + 1, nil
+			}
+		}
+	}
+	return 0, syntaxError(i, "unterminated object or array")
+}
+
+func skipSpace(data []byte, i int) int {
+	for i < len(data) && isSpace(data[i]) {
+		i++
+	}
+	return i
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+var isDelimiter = func() [256]bool {
+	var table [256]bool
+	for _, c := range []byte(",:{}[]\" \t\r\n") {
+		table[c] = true
+	}
+	return table
+}()
+
+func syntaxError(offset int, msg string) error {
+	return &SyntaxError{Offset: offset, msg: msg}
+}
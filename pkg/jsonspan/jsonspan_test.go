@@ -0,0 +1,162 @@
+package jsonspan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	doc := `{
+  "projects": {"/a": {"history": ["{not a key}", "say \"mcpServers\": {}\\"], "n": 1}},
+  "mcpServers": {"old": {}},
+  "nested": {"inner": [1, {"x": true}], "tail": null},
+  "mcpServers": {"kirha": {"url": "https://mcp.kirha.com"}}
+}`
+
+	tests := []struct {
+		path  []string
+		want  string
+		found bool
+	}{
+		{path: []string{"mcpServers"}, want: `{"kirha": {"url": "https://mcp.kirha.com"}}`, found: true},
+		{path: []string{"nested", "inner"}, want: `[1, {"x": true}]`, found: true},
+		{path: []string{"nested", "tail"}, want: `null`, found: true},
+		{path: []string{"projects", "/a", "n"}, want: `1`, found: true},
+		{path: []string{"missing"}},
+		{path: []string{"nested", "inner", "x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.path, "."), func(t *testing.T) {
+			span, found, err := Find([]byte(doc), tt.path...)
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+			if found != tt.found {
+				t.Fatalf("Find() found = %v, want %v", found, tt.found)
+			}
+			if found && doc[span.Start:span.End] != tt.want {
+				t.Errorf("Find() = %s, want %s", doc[span.Start:span.End], tt.want)
+			}
+		})
+	}
+}
+
+func TestFind_Malformed(t *testing.T) {
+	docs := []string{
+		``,
+		`[]`,
+		`{"a": "unterminated}`,
+		`{"a": [1, 2}, "mcpServers": {}}`,
+		`{"a": 1 "b": 2}`,
+		`{"a": {"b": 1}`,
+	}
+
+	for _, doc := range docs {
+		_, _, err := Find([]byte(doc), "mcpServers")
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Find(%q) error = %v, want *SyntaxError", doc, err)
+		}
+	}
+}
+
+func TestReplace(t *testing.T) {
+	value := `{"kirha":{"url":"https://mcp.kirha.com"}}`
+
+	tests := []struct {
+		name string
+		doc  string
+		path []string
+		want string
+	}{
+		{
+			name: "replaces value in place",
+			doc:  "{\n    \"theme\":\"dark\",\n    \"mcpServers\": {},\n    \"tips\": [1,2]\n}\n",
+			path: []string{"mcpServers"},
+			want: "{\n    \"theme\":\"dark\",\n    \"mcpServers\": {\n        \"kirha\": {\n            \"url\": \"https://mcp.kirha.com\"\n        }\n    },\n    \"tips\": [1,2]\n}\n",
+		},
+		{
+			name: "appends missing member",
+			doc:  "{\n  \"theme\": \"dark\"\n}",
+			path: []string{"mcpServers"},
+			want: "{\n  \"theme\": \"dark\",\n  \"mcpServers\": {\n    \"kirha\": {\n      \"url\": \"https://mcp.kirha.com\"\n    }\n  }\n}",
+		},
+		{
+			name: "fills empty object",
+			doc:  `{}`,
+			path: []string{"mcpServers"},
+			want: "{\n  \"mcpServers\": {\n    \"kirha\": {\n      \"url\": \"https://mcp.kirha.com\"\n    }\n  }\n}",
+		},
+		{
+			name: "keeps compact documents compact",
+			doc:  `{"theme":"dark","mcpServers":null}`,
+			path: []string{"mcpServers"},
+			want: `{"theme":"dark","mcpServers":{"kirha":{"url":"https://mcp.kirha.com"}}}`,
+		},
+		{
+			name: "creates missing parents",
+			doc:  "{\n  \"theme\": \"dark\",\n  \"projects\": {\n    \"/a\": 1\n  }\n}",
+			path: []string{"projects", "/b", "mcpServers"},
+			want: "{\n  \"theme\": \"dark\",\n  \"projects\": {\n    \"/a\": 1,\n    \"/b\": {\n      \"mcpServers\": {\n        \"kirha\": {\n          \"url\": \"https://mcp.kirha.com\"\n        }\n      }\n    }\n  }\n}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Replace([]byte(tt.doc), tt.path, []byte(value))
+			if err != nil {
+				t.Fatalf("Replace() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Replace() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if !json.Valid(got) {
+				t.Errorf("Replace() produced invalid JSON")
+			}
+		})
+	}
+}
+
+// largeDocument builds a document shaped like a ~/.claude.json with a long
+// project history, about size bytes long.
+func largeDocument(size int) []byte {
+	var b strings.Builder
+	b.WriteString("{\n  \"numStartups\": 412,\n  \"projects\": {\n")
+	for i := 0; b.Len() < size; i++ {
+		if i > 0 {
+			b.WriteString(",\n")
+		}
+		fmt.Fprintf(&b, `    "/home/user/project-%d": {"history": [{"display": "fix the \"parser\" {edge} case %d", "pastedContents": {}}], "allowedTools": []}`, i, i)
+	}
+	b.WriteString("\n  },\n  \"mcpServers\": {\n    \"other\": {\n      \"command\": \"node\"\n    }\n  }\n}\n")
+	return []byte(b.String())
+}
+
+func BenchmarkFind(b *testing.B) {
+	for _, size := range []int{1 << 20, 8 << 20, 32 << 20} {
+		doc := largeDocument(size)
+
+		b.Run(fmt.Sprintf("%dMB/span", size>>20), func(b *testing.B) {
+			b.SetBytes(int64(len(doc)))
+			for i := 0; i < b.N; i++ {
+				if _, _, err := Find(doc, "mcpServers"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("%dMB/decode", size>>20), func(b *testing.B) {
+			b.SetBytes(int64(len(doc)))
+			for i := 0; i < b.N; i++ {
+				var decoded map[string]interface{}
+				if err := json.Unmarshal(doc, &decoded); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+// Package prompt reads answers typed into an interactive terminal.
+package prompt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var stdin = bufio.NewReader(os.Stdin)
+
+// Secret prints label to stderr and reads a line from stdin without echoing
+// it, when the terminal allows turning echo off.
+func Secret(label string) (string, error) {
+	fmt.Fprint(os.Stderr, label)
+
+	if restore, err := disableEcho(os.Stdin); err == nil {
+		defer restore()
+	}
+
+	line, err := stdin.ReadString('\n')
+	fmt.Fprintln(os.Stderr)
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// Line prints label to stderr and reads a line from stdin.
+func Line(label string) (string, error) {
+	fmt.Fprint(os.Stderr, label)
+
+	line, err := stdin.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
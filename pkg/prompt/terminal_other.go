@@ -0,0 +1,19 @@
+//go:build !linux && !darwin && !windows
+
+package prompt
+
+import (
+	"errors"
+	"os"
+)
+
+// IsTerminal reports whether file is a character device, the closest
+// approximation available on this platform.
+func IsTerminal(file *os.File) bool {
+	stat, err := file.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+func disableEcho(file *os.File) (func(), error) {
+	return nil, errors.New("turning echo off is not supported on this platform")
+}
@@ -0,0 +1,32 @@
+//go:build windows
+
+package prompt
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// IsTerminal reports whether file is an interactive console.
+func IsTerminal(file *os.File) bool {
+	var mode uint32
+	return windows.GetConsoleMode(windows.Handle(file.Fd()), &mode) == nil
+}
+
+func disableEcho(file *os.File) (func(), error) {
+	handle := windows.Handle(file.Fd())
+
+	var mode uint32
+	if err := windows.GetConsoleMode(handle, &mode); err != nil {
+		return nil, err
+	}
+
+	if err := windows.SetConsoleMode(handle, mode&^windows.ENABLE_ECHO_INPUT); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		_ = windows.SetConsoleMode(handle, mode)
+	}, nil
+}
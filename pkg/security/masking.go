@@ -1,6 +1,16 @@
 package security
 
-import "strings"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// RedactedToken takes the place of API keys removed from stored files.
+const RedactedToken = "REDACTED"
+
+var bearerTokenPattern = regexp.MustCompile(`(Bearer\s+)([A-Za-z0-9._~+/=-]+)`)
 
 // MaskAPIKey masks an API key for display purposes, showing only the first 4 and last 4 characters
 func MaskAPIKey(key string) string {
@@ -8,4 +18,31 @@ func MaskAPIKey(key string) string {
 		return "****"
 	}
 	return key[:4] + strings.Repeat("*", len(key)-8) + key[len(key)-4:]
-}
\ No newline at end of file
+}
+
+// MaskBearerTokens masks every bearer token found in text, such as the
+// Authorization headers inside a configuration file
+func MaskBearerTokens(text string) string {
+	return bearerTokenPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := bearerTokenPattern.FindStringSubmatch(match)
+		return parts[1] + MaskAPIKey(parts[2])
+	})
+}
+
+// RedactKey replaces every occurrence of key in text
+func RedactKey(text, key string) string {
+	if key == "" {
+		return text
+	}
+	return strings.ReplaceAll(text, key, RedactedToken)
+}
+
+// KeyFingerprint identifies an API key without revealing it, so that records
+// can tell whether two changes used the same key
+func KeyFingerprint(key string) string {
+	if key == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(key))
+	return "sha256:" + hex.EncodeToString(sum[:])[:16]
+}
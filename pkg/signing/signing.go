@@ -0,0 +1,127 @@
+// Package signing creates and checks detached ed25519 signatures of files.
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	keyPrefix        = "ed25519:"
+	privateKeyPrefix = "ed25519-private:"
+	algorithm        = "ed25519"
+	signatureVersion = 1
+)
+
+var (
+	ErrInvalidKey       = errors.New("invalid ed25519 key")
+	ErrInvalidSignature = errors.New("invalid signature file")
+)
+
+// Signature is a detached signature along with the public key that made it,
+// so that tampering can be detected even when the key is not trusted.
+type Signature struct {
+	PublicKey ed25519.PublicKey
+	Value     []byte
+}
+
+type signatureFile struct {
+	Version   int    `json:"version"`
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"`
+	Signature string `json:"signature"`
+}
+
+// GenerateKey returns a new key pair.
+func GenerateKey() (ed25519.PublicKey, ed25519.PrivateKey, error) {
+	return ed25519.GenerateKey(rand.Reader)
+}
+
+// EncodePublicKey formats a public key as "ed25519:<base64>".
+func EncodePublicKey(key ed25519.PublicKey) string {
+	return keyPrefix + base64.StdEncoding.EncodeToString(key)
+}
+
+// ParsePublicKey reads a key formatted by EncodePublicKey. The prefix is
+// optional.
+func ParsePublicKey(value string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(strings.TrimSpace(value), keyPrefix))
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidKey, value)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// EncodePrivateKey formats a private key as "ed25519-private:<base64>".
+func EncodePrivateKey(key ed25519.PrivateKey) string {
+	return privateKeyPrefix + base64.StdEncoding.EncodeToString(key.Seed())
+}
+
+func ParsePrivateKey(value string) (ed25519.PrivateKey, error) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, privateKeyPrefix) {
+		return nil, fmt.Errorf("%w: not a private key", ErrInvalidKey)
+	}
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, privateKeyPrefix))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%w: malformed private key", ErrInvalidKey)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// KeyID returns a short identifier of a public key for display.
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Sign returns the encoded detached signature of data.
+func Sign(data []byte, key ed25519.PrivateKey) ([]byte, error) {
+	file := signatureFile{
+		Version:   signatureVersion,
+		Algorithm: algorithm,
+		PublicKey: EncodePublicKey(key.Public().(ed25519.PublicKey)),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)),
+	}
+
+	encoded, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(encoded, '\n'), nil
+}
+
+// ParseSignature reads a signature written by Sign.
+func ParseSignature(data []byte) (*Signature, error) {
+	var file signatureFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if file.Version != signatureVersion || file.Algorithm != algorithm {
+		return nil, fmt.Errorf("%w: unsupported version %d or algorithm %q", ErrInvalidSignature, file.Version, file.Algorithm)
+	}
+
+	key, err := ParsePublicKey(file.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	value, err := base64.StdEncoding.DecodeString(file.Signature)
+	if err != nil || len(value) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidSignature)
+	}
+
+	return &Signature{PublicKey: key, Value: value}, nil
+}
+
+// Verify reports whether the signature was made over data by its key.
+func (s *Signature) Verify(data []byte) bool {
+	return ed25519.Verify(s.PublicKey, data, s.Value)
+}
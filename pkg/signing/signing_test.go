@@ -0,0 +1,39 @@
+package signing
+
+import "testing"
+
+func TestSignAndVerify(t *testing.T) {
+	public, private, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	parsedPrivate, err := ParsePrivateKey(EncodePrivateKey(private))
+	if err != nil {
+		t.Fatalf("ParsePrivateKey() error = %v", err)
+	}
+
+	data := []byte("version: 1\nservers: []\n")
+	encoded, err := Sign(data, parsedPrivate)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	signature, err := ParseSignature(encoded)
+	if err != nil {
+		t.Fatalf("ParseSignature() error = %v", err)
+	}
+	if EncodePublicKey(signature.PublicKey) != EncodePublicKey(public) {
+		t.Errorf("signature key = %s, want %s", EncodePublicKey(signature.PublicKey), EncodePublicKey(public))
+	}
+	if !signature.Verify(data) {
+		t.Error("Verify() = false for the signed data")
+	}
+	if signature.Verify([]byte("version: 1\nservers: [evil]\n")) {
+		t.Error("Verify() = true for tampered data")
+	}
+
+	if _, err := ParsePublicKey("ed25519:not-a-key"); err == nil {
+		t.Error("ParsePublicKey() accepted a malformed key")
+	}
+}
@@ -0,0 +1,188 @@
+// Package textdiff renders line-based unified diffs.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxMiddle bounds the size of the table used to diff the lines between the
+// common prefix and suffix. Larger changes are shown as a block replacement.
+const maxMiddle = 4_000_000
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind opKind
+	line string
+	// a and b are the 0-based line numbers in the old and new text.
+	a, b int
+}
+
+// Unified returns the unified diff turning before into after, with context
+// lines around each change, or "" when they are equal.
+func Unified(before, after, fromName, toName string, context int) string {
+	if before == after {
+		return ""
+	}
+
+	ops := diffLines(splitLines(before), splitLines(after))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+	for _, hunk := range hunks(ops, context) {
+		writeHunk(&b, ops[hunk[0]:hunk[1]])
+	}
+	return b.String()
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func diffLines(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []op
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, op{kind: opEqual, line: a[i], a: i, b: i})
+	}
+	ops = append(ops, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], prefix, prefix)...)
+	for i := 0; i < suffix; i++ {
+		ai, bi := len(a)-suffix+i, len(b)-suffix+i
+		ops = append(ops, op{kind: opEqual, line: a[ai], a: ai, b: bi})
+	}
+	return ops
+}
+
+// diffMiddle diffs a and b with a longest common subsequence table. offsetA
+// and offsetB are the line numbers of their first lines.
+func diffMiddle(a, b []string, offsetA, offsetB int) []op {
+	var ops []op
+	if len(a)*len(b) > maxMiddle {
+		for i, line := range a {
+			ops = append(ops, op{kind: opDelete, line: line, a: offsetA + i, b: offsetB})
+		}
+		for j, line := range b {
+			ops = append(ops, op{kind: opInsert, line: line, a: offsetA + len(a), b: offsetB + j})
+		}
+		return ops
+	}
+
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, op{kind: opEqual, line: a[i], a: offsetA + i, b: offsetB + j})
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] > lcs[i+1][j]):
+			ops = append(ops, op{kind: opInsert, line: b[j], a: offsetA + i, b: offsetB + j})
+			j++
+		default:
+			ops = append(ops, op{kind: opDelete, line: a[i], a: offsetA + i, b: offsetB + j})
+			i++
+		}
+	}
+	return ops
+}
+
+// hunks groups changes that are less than 2*context lines apart, returning
+// the [start, end) range of ops each hunk covers.
+func hunks(ops []op, context int) [][2]int {
+	var result [][2]int
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == opEqual {
+			continue
+		}
+
+		start := max(i-context, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == opEqual {
+				next++
+			}
+			if next == len(ops) || next-end > 2*context {
+				end = min(end+context, len(ops))
+				break
+			}
+			end = next
+		}
+
+		if len(result) > 0 && start <= result[len(result)-1][1] {
+			result[len(result)-1][1] = end
+		} else {
+			result = append(result, [2]int{start, end})
+		}
+		i = end - 1
+	}
+	return result
+}
+
+func writeHunk(b *strings.Builder, ops []op) {
+	countA, countB := 0, 0
+	for _, o := range ops {
+		if o.kind != opInsert {
+			countA++
+		}
+		if o.kind != opDelete {
+			countB++
+		}
+	}
+
+	startA, startB := ops[0].a+1, ops[0].b+1
+	if countA == 0 {
+		startA--
+	}
+	if countB == 0 {
+		startB--
+	}
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", startA, countA, startB, countB)
+
+	for _, o := range ops {
+		b.WriteByte(byte(o.kind))
+		b.WriteString(o.line)
+		if !strings.HasSuffix(o.line, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package textdiff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	before := "{\n  \"a\": 1,\n  \"b\": 2,\n  \"c\": 3,\n}\n"
+	after := "{\n  \"a\": 1,\n  \"b\": 20,\n  \"c\": 3\n}\n"
+
+	want := `--- before
++++ after
+@@ -1,5 +1,5 @@
+ {
+   "a": 1,
+-  "b": 2,
+-  "c": 3,
++  "b": 20,
++  "c": 3
+ }
+`
+	if got := Unified(before, after, "before", "after", 3); got != want {
+		t.Errorf("Unified() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUnified_SeparateHunks(t *testing.T) {
+	before := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	after := "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n"
+
+	want := `--- a
++++ b
+@@ -1,2 +1,2 @@
+-1
++one
+ 2
+@@ -9,2 +9,2 @@
+ 9
+-10
++ten
+`
+	if got := Unified(before, after, "a", "b", 1); got != want {
+		t.Errorf("Unified() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUnified_Equal(t *testing.T) {
+	if got := Unified("same\n", "same\n", "a", "b", 3); got != "" {
+		t.Errorf("Unified() = %q, want empty", got)
+	}
+}
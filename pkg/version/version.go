@@ -0,0 +1,4 @@
+// Package version holds the release version of the installer.
+package version
+
+const Version = "0.1.0"